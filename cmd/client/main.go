@@ -1,39 +1,102 @@
 package main
 
 import (
+	"context"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/alvinlucillo/sqs-processor/internal/client"
-	"github.com/kelseyhightower/envconfig"
+	"github.com/alvinlucillo/sqs-processor/internal/config"
+	"github.com/alvinlucillo/sqs-processor/internal/logging"
 	"github.com/rs/zerolog"
 )
 
 // main is the entrypoint to run the client app
 func main() {
-	// initializes logger to log to standard output
+	// logs to standard output until the configured logger is created
 	logger := zerolog.New(os.Stdout).With().Timestamp().Logger()
 
-	logger.Info().Caller().Msg("Client starting")
-
-	// initializes struct with values from env vars
+	// initializes struct with values from env vars and the config file they name
 	// errors out if required vars are missing
 	var env client.Environment
-	err := envconfig.Process("", &env)
+	err := config.Load("", &env)
 	if err != nil {
 		logger.Error().Err(err).Msg("Error initializing env")
 		return
 	}
 
+	configuredLogger, err := logging.NewLogger(os.Stdout, logging.Config{
+		Level:        env.LogLevel,
+		Format:       env.LogFormat,
+		SampleBurst:  env.LogSampleBurst,
+		SamplePeriod: time.Duration(env.LogSamplePeriod) * time.Second,
+	})
+	if err != nil {
+		logger.Error().Err(err).Msg("Error initializing logger")
+		return
+	}
+	logger = configuredLogger
+
+	// kill -USR1 switches between debug logs and the latest configured LogLevel without restarting the pod
+	stopToggle := logging.ToggleDebugOnSignal(logger, syscall.SIGUSR1)
+	defer stopToggle()
+
+	logger.Info().Caller().Msg("Client starting")
+
 	// logger.Info().Msgf("Env %v", env)
 
-	sqsClient, err := client.NewClient(logger, env)
+	// business logic goes here; returning an error retries the message
+	// and wrapping it with client.Permanent dead-letters it
+	// client.NewRouter dispatches messages to different handlers by their attributes or body
+	var handler client.Handler = client.HandlerFunc(func(ctx context.Context, msg *client.Message) error {
+		logger.Info().Str("messageID", msg.ID).Msg("Processing message")
+		return nil
+	})
+
+	// the routes of the environment or config file pick their handlers by name among these
+	// messages no route matches are handled by the handler above
+	if len(env.Routes) > 0 {
+		handlers := map[string]client.Handler{"log": handler}
+
+		router, err := env.Routes.Router(handlers, handler)
+		if err != nil {
+			logger.Error().Err(err).Msg("Error initializing routes")
+			return
+		}
+		handler = router
+	}
+
+	sqsClient, err := client.NewClient(logger, env, handler)
 	if err != nil {
 		logger.Error().Err(err).Msg("Error initializing client")
 		return
 	}
 
-	if err := sqsClient.Run(); err != nil {
+	// stops receiving messages and drains the in-flight ones once the pod is terminated
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	// applies the polling, limits and log level of the config file once it changes
+	if env.ConfigFile != "" && env.ConfigReloadInterval > 0 {
+		go config.Watch(ctx, env.ConfigFile, time.Duration(env.ConfigReloadInterval)*time.Second, func() {
+			var reloaded client.Environment
+			if err := config.Load("", &reloaded); err != nil {
+				logger.Error().Err(err).Msg("Error reloading config")
+				return
+			}
+
+			if err := sqsClient.Reconfigure(reloaded); err != nil {
+				logger.Error().Err(err).Msg("Error applying config")
+			}
+		})
+	}
+
+	if err := sqsClient.Run(ctx); err != nil {
 		logger.Error().Err(err).Msg("Error running client")
 		return
 	}
+
+	logger.Info().Msg("Client stopped")
 }
@@ -1,31 +1,51 @@
 package main
 
 import (
+	"context"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
+	"github.com/alvinlucillo/sqs-processor/internal/config"
+	"github.com/alvinlucillo/sqs-processor/internal/logging"
 	"github.com/alvinlucillo/sqs-processor/internal/sqsservice"
-	"github.com/kelseyhightower/envconfig"
 
 	"github.com/rs/zerolog"
 )
 
 // main is the entrypoint to run the sqsservice app
 func main() {
+	// logs to standard output until the configured logger is created
 	logger := zerolog.New(os.Stdout).With().Timestamp().Logger()
 
-	logger.Info().Caller().Msg("Server starting")
-
-	// initializes struct with values from env vars
+	// initializes struct with values from env vars and the config file they name
 	// errors out if required vars are missing
 	var env sqsservice.Environment
-	err := envconfig.Process("app", &env)
+	err := config.Load("app", &env)
 	if err != nil {
 		logger.Error().Err(err).Msg("Error initializing env")
 		return
 	}
 
+	configuredLogger, err := logging.NewLogger(os.Stdout, logging.Config{
+		Level:        env.LogLevel,
+		Format:       env.LogFormat,
+		SampleBurst:  env.LogSampleBurst,
+		SamplePeriod: time.Duration(env.LogSamplePeriod) * time.Second,
+	})
+	if err != nil {
+		logger.Error().Err(err).Msg("Error initializing logger")
+		return
+	}
+	logger = configuredLogger
+
+	// kill -USR1 switches between debug logs and the latest configured LogLevel without restarting the pod
+	stopToggle := logging.ToggleDebugOnSignal(logger, syscall.SIGUSR1)
+	defer stopToggle()
+
+	logger.Info().Caller().Msg("Server starting")
+
 	// logger.Debug().Msgf("Environment variables %v", env)
 
 	s, err := sqsservice.NewServer(logger, env)
@@ -45,6 +65,24 @@ func main() {
 		}
 	}()
 
+	// applies the log level and retry policy of the config file once it changes
+	if env.ConfigFile != "" && env.ConfigReloadInterval > 0 {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		go config.Watch(ctx, env.ConfigFile, time.Duration(env.ConfigReloadInterval)*time.Second, func() {
+			var reloaded sqsservice.Environment
+			if err := config.Load("app", &reloaded); err != nil {
+				logger.Error().Err(err).Msg("Error reloading config")
+				return
+			}
+
+			if err := s.Reconfigure(reloaded); err != nil {
+				logger.Error().Err(err).Msg("Error applying config")
+			}
+		})
+	}
+
 	// waits for signal before gracefully stopping the server
 	<-shutdownChan
 	s.GracefulStop()
@@ -0,0 +1,223 @@
+package auth
+
+// package used by sqsservice to authenticate and authorize the callers of its api
+// and by client to send its token along with every call
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/rs/zerolog"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	packageName = "auth"
+
+	// roles of the default policy
+	RoleConsumer = "consumer"
+	RoleProducer = "producer"
+	RoleAdmin    = "admin"
+
+	// allows every method when granted to a role
+	AllMethods = "*"
+)
+
+var (
+	ErrMissingToken = errors.New("missing bearer token")
+	ErrInvalidToken = errors.New("invalid token")
+)
+
+// Identity - caller a token was issued to
+type Identity struct {
+	Subject string
+	Roles   []string
+}
+
+// Authenticator - verifies a bearer token and returns who it was issued to
+type Authenticator interface {
+	Authenticate(token string) (*Identity, error)
+}
+
+// Authenticators - tries every authenticator in order until one accepts the token
+type Authenticators []Authenticator
+
+func (a Authenticators) Authenticate(token string) (*Identity, error) {
+	err := ErrInvalidToken
+
+	for _, authenticator := range a {
+		var identity *Identity
+
+		identity, err = authenticator.Authenticate(token)
+		if err == nil {
+			return identity, nil
+		}
+	}
+
+	return nil, err
+}
+
+// Policy - role -> names of the methods the role can call, e.g. ReceiveMessage
+type Policy map[string][]string
+
+// DefaultPolicy - consumers can receive and settle messages, producers can send them, admins can call every method
+func DefaultPolicy() Policy {
+	return Policy{
+		RoleConsumer: {"ReceiveMessage", "DeleteMessage", "NackMessage", "DeadLetterMessage", "ChangeMessageVisibility", "StreamMessages", "Consume"},
+		RoleProducer: {"SendMessage"},
+		RoleAdmin:    {AllMethods},
+	}
+}
+
+// ParsePolicy - parses a policy formatted as role=Method,Method;role=Method
+// e.g. consumer=ReceiveMessage,DeleteMessage;admin=*
+func ParsePolicy(s string) (Policy, error) {
+	policy := make(Policy)
+
+	for _, rule := range strings.Split(s, ";") {
+		rule = strings.TrimSpace(rule)
+		if rule == "" {
+			continue
+		}
+
+		role, methods, found := strings.Cut(rule, "=")
+		role = strings.TrimSpace(role)
+		if !found || role == "" {
+			return nil, fmt.Errorf("invalid policy rule: %v", rule)
+		}
+
+		for _, method := range strings.Split(methods, ",") {
+			if method = strings.TrimSpace(method); method != "" {
+				policy[role] = append(policy[role], method)
+			}
+		}
+	}
+
+	return policy, nil
+}
+
+// Allowed - checks if any of the identity's roles can call the method
+// fullMethod is the grpc method name, e.g. /sqs.SQSService/ReceiveMessage
+func (p Policy) Allowed(identity *Identity, fullMethod string) bool {
+	method := fullMethod[strings.LastIndex(fullMethod, "/")+1:]
+
+	for _, role := range identity.Roles {
+		for _, allowed := range p[role] {
+			if allowed == AllMethods || allowed == method {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+type identityKey struct{}
+
+// FromContext - returns the identity of the caller authenticated by the interceptor
+func FromContext(ctx context.Context) (*Identity, bool) {
+	identity, ok := ctx.Value(identityKey{}).(*Identity)
+	return identity, ok
+}
+
+// Interceptor - rejects calls without a valid bearer token or whose roles can't call the method
+type Interceptor struct {
+	Authenticator Authenticator
+	Policy        Policy
+	// services callable without a token, e.g. grpc.health.v1.Health for kubelet probes
+	PublicServices []string
+	Logger         zerolog.Logger
+}
+
+// NewInterceptor - creates new Interceptor
+func NewInterceptor(logger zerolog.Logger, authenticator Authenticator, policy Policy) *Interceptor {
+	return &Interceptor{
+		Authenticator: authenticator,
+		Policy:        policy,
+		Logger:        logger.With().Str("package", packageName).Logger(),
+	}
+}
+
+// authorize - returns the context carrying the caller's identity
+// fails with Unauthenticated if the token is missing or invalid, and PermissionDenied if the method isn't allowed
+func (i *Interceptor) authorize(ctx context.Context, fullMethod string) (context.Context, error) {
+	for _, service := range i.PublicServices {
+		if strings.HasPrefix(fullMethod, "/"+service+"/") {
+			return ctx, nil
+		}
+	}
+
+	l := i.Logger.With().Str("function", "authorize").Str("method", fullMethod).Logger()
+
+	token, err := bearerToken(ctx)
+	if err != nil {
+		l.Debug().Err(err).Msg("Rejected unauthenticated call")
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+
+	identity, err := i.Authenticator.Authenticate(token)
+	if err != nil {
+		l.Debug().Err(err).Msg("Rejected unauthenticated call")
+		return nil, status.Error(codes.Unauthenticated, ErrInvalidToken.Error())
+	}
+
+	if !i.Policy.Allowed(identity, fullMethod) {
+		l.Debug().Str("subject", identity.Subject).Strs("roles", identity.Roles).Msg("Rejected unauthorized call")
+		return nil, status.Errorf(codes.PermissionDenied, "%v isn't allowed to call %v", identity.Subject, fullMethod)
+	}
+
+	return context.WithValue(ctx, identityKey{}, identity), nil
+}
+
+// bearerToken - returns the token of the call's authorization metadata
+func bearerToken(ctx context.Context) (string, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	for _, value := range md.Get("authorization") {
+		scheme, token, found := strings.Cut(value, " ")
+		if found && strings.EqualFold(scheme, "bearer") && strings.TrimSpace(token) != "" {
+			return strings.TrimSpace(token), nil
+		}
+	}
+
+	return "", ErrMissingToken
+}
+
+// Unary - interceptor for unary calls
+func (i *Interceptor) Unary() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, err := i.authorize(ctx, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// Stream - interceptor for streaming calls
+func (i *Interceptor) Stream() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := i.authorize(ss.Context(), info.FullMethod)
+		if err != nil {
+			return err
+		}
+
+		return handler(srv, &identityStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// identityStream - server stream whose context carries the caller's identity
+type identityStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *identityStream) Context() context.Context {
+	return s.ctx
+}
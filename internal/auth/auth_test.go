@@ -0,0 +1,185 @@
+package auth
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func writeFile(t *testing.T, path string, content string) string {
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestParsePolicy(t *testing.T) {
+	testCases := map[string]struct {
+		policy  string
+		want    Policy
+		wantErr bool
+	}{
+		"roles": {
+			policy: "consumer=ReceiveMessage, DeleteMessage;admin=*;",
+			want:   Policy{"consumer": {"ReceiveMessage", "DeleteMessage"}, "admin": {"*"}},
+		},
+		"missing methods": {
+			policy:  "consumer",
+			wantErr: true,
+		},
+		"missing role": {
+			policy:  "=ReceiveMessage",
+			wantErr: true,
+		},
+	}
+
+	for name, tc := range testCases {
+		policy, err := ParsePolicy(tc.policy)
+		if tc.wantErr {
+			require.Error(t, err, name)
+			continue
+		}
+
+		require.NoError(t, err, name)
+		require.Equal(t, tc.want, policy, name)
+	}
+}
+
+func TestInterceptor(t *testing.T) {
+	tokenFile := writeFile(t, filepath.Join(t.TempDir(), "tokens"), "# sidecar tokens\nconsumer-token consumer\n\nadmin-token admin\n")
+
+	authenticator, err := NewStaticAuthenticator(zerolog.Nop(), tokenFile)
+	require.NoError(t, err)
+
+	interceptor := NewInterceptor(zerolog.Nop(), authenticator, DefaultPolicy())
+	interceptor.PublicServices = []string{"grpc.health.v1.Health"}
+
+	testCases := map[string]struct {
+		authorization string
+		method        string
+		wantCode      codes.Code
+		wantRoles     []string
+	}{
+		"consumer receives": {
+			authorization: "Bearer consumer-token",
+			method:        "/sqs.SQSService/ReceiveMessage",
+			wantCode:      codes.OK,
+			wantRoles:     []string{"consumer"},
+		},
+		"consumer purges": {
+			authorization: "Bearer consumer-token",
+			method:        "/sqs.SQSService/PurgeQueue",
+			wantCode:      codes.PermissionDenied,
+		},
+		"admin purges": {
+			authorization: "bearer admin-token",
+			method:        "/sqs.SQSService/PurgeQueue",
+			wantCode:      codes.OK,
+			wantRoles:     []string{"admin"},
+		},
+		"missing token": {
+			method:   "/sqs.SQSService/ReceiveMessage",
+			wantCode: codes.Unauthenticated,
+		},
+		"wrong scheme": {
+			authorization: "Basic consumer-token",
+			method:        "/sqs.SQSService/ReceiveMessage",
+			wantCode:      codes.Unauthenticated,
+		},
+		"public service": {
+			method:   "/grpc.health.v1.Health/Check",
+			wantCode: codes.OK,
+		},
+		"invalid token": {
+			authorization: "Bearer guessed-token",
+			method:        "/sqs.SQSService/ReceiveMessage",
+			wantCode:      codes.Unauthenticated,
+		},
+	}
+
+	for name, tc := range testCases {
+		ctx := context.Background()
+		if tc.authorization != "" {
+			ctx = metadata.NewIncomingContext(ctx, metadata.Pairs("authorization", tc.authorization))
+		}
+
+		var identity *Identity
+		handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+			identity, _ = FromContext(ctx)
+			return nil, nil
+		}
+
+		_, err := interceptor.Unary()(ctx, nil, &grpc.UnaryServerInfo{FullMethod: tc.method}, handler)
+		require.Equal(t, tc.wantCode, status.Code(err), name)
+
+		if tc.wantRoles != nil {
+			require.Equal(t, tc.wantRoles, identity.Roles, name)
+		} else {
+			require.Nil(t, identity, name)
+		}
+	}
+}
+
+func TestStaticAuthenticatorReload(t *testing.T) {
+	tokenFile := writeFile(t, filepath.Join(t.TempDir(), "tokens"), "old-token consumer\n")
+
+	authenticator, err := NewStaticAuthenticator(zerolog.Nop(), tokenFile)
+	require.NoError(t, err)
+
+	_, err = authenticator.Authenticate("old-token")
+	require.NoError(t, err)
+
+	// an empty file is a rotation in progress, so the previous tokens are kept
+	writeFile(t, tokenFile, "")
+	future := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(tokenFile, future, future))
+	authenticator.file.lastCheck = time.Time{}
+
+	_, err = authenticator.Authenticate("old-token")
+	require.NoError(t, err)
+
+	writeFile(t, tokenFile, "new-token consumer\n")
+	future = future.Add(time.Minute)
+	require.NoError(t, os.Chtimes(tokenFile, future, future))
+	authenticator.file.lastCheck = time.Time{}
+
+	_, err = authenticator.Authenticate("old-token")
+	require.ErrorIs(t, err, ErrInvalidToken)
+
+	_, err = authenticator.Authenticate("new-token")
+	require.NoError(t, err)
+}
+
+func TestParseStaticTokens(t *testing.T) {
+	testCases := map[string]string{
+		"token without roles": "s3cr3t\n",
+		"extra fields":        "s3cr3t consumer admin\n",
+		"no tokens":           "# rotated\n",
+	}
+
+	for name, content := range testCases {
+		_, err := parseStaticTokens([]byte(content))
+		require.Error(t, err, name)
+	}
+}
+
+func TestTokenCredentials(t *testing.T) {
+	tokenFile := writeFile(t, filepath.Join(t.TempDir(), "token"), "consumer-token\n")
+
+	credentials, err := NewTokenCredentials(tokenFile)
+	require.NoError(t, err)
+
+	md, err := credentials.GetRequestMetadata(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"authorization": "Bearer consumer-token"}, md)
+
+	_, err = NewTokenCredentials(writeFile(t, filepath.Join(t.TempDir(), "empty"), " \n"))
+	require.Error(t, err)
+}
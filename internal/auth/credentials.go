@@ -0,0 +1,43 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"strings"
+)
+
+// TokenCredentials - per-rpc credentials sending the token of a mounted file as a bearer token
+// the file is read again once it changes, so rotated tokens are picked up without a restart
+type TokenCredentials struct {
+	file *watchedFile
+}
+
+// NewTokenCredentials - creates new TokenCredentials; fails if the file is empty
+func NewTokenCredentials(path string) (*TokenCredentials, error) {
+	file, err := newWatchedFile(path, func(content []byte) (interface{}, error) {
+		token := strings.TrimSpace(string(content))
+		if token == "" {
+			return nil, errors.New("no token found")
+		}
+
+		return token, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &TokenCredentials{file: file}, nil
+}
+
+func (c *TokenCredentials) GetRequestMetadata(ctx context.Context, uri ...string) (map[string]string, error) {
+	// a failed reload keeps sending the previous token, which the sqsservice rejects if it's no longer valid
+	token, _ := c.file.current()
+
+	return map[string]string{"authorization": "Bearer " + token.(string)}, nil
+}
+
+// RequireTransportSecurity - tokens are only sent over TLS or unix sockets dialed with local credentials
+// so they can't be read off the network
+func (c *TokenCredentials) RequireTransportSecurity() bool {
+	return true
+}
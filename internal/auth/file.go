@@ -0,0 +1,77 @@
+package auth
+
+import (
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	// minimum time between checks of a file for changes
+	reloadInterval = 5 * time.Second
+)
+
+// watchedFile - contents of a file parsed by parse, parsed again once the file changes
+// keeps the previous contents if parsing the changed file fails, e.g. while a rotation is half written
+type watchedFile struct {
+	path  string
+	parse func(content []byte) (interface{}, error)
+
+	mu        sync.Mutex
+	value     interface{}
+	modTime   time.Time
+	lastCheck time.Time
+}
+
+// newWatchedFile - reads and parses the file; fails if the file can't be parsed
+func newWatchedFile(path string, parse func(content []byte) (interface{}, error)) (*watchedFile, error) {
+	f := &watchedFile{path: path, parse: parse}
+
+	if err := f.load(); err != nil {
+		return nil, err
+	}
+
+	return f, nil
+}
+
+// load - reads and parses the file; caller holds the lock or has exclusive access
+func (f *watchedFile) load() error {
+	info, err := os.Stat(f.path)
+	if err != nil {
+		return err
+	}
+
+	content, err := os.ReadFile(f.path)
+	if err != nil {
+		return err
+	}
+
+	value, err := f.parse(content)
+	if err != nil {
+		return err
+	}
+
+	f.value = value
+	f.modTime = info.ModTime()
+
+	return nil
+}
+
+// current - returns the parsed contents, reloading them first if the file changed
+// the error of a failed reload is returned along with the previous contents
+func (f *watchedFile) current() (interface{}, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if time.Since(f.lastCheck) < reloadInterval {
+		return f.value, nil
+	}
+	f.lastCheck = time.Now()
+
+	info, err := os.Stat(f.path)
+	if err == nil && info.ModTime().Equal(f.modTime) {
+		return f.value, nil
+	}
+
+	return f.value, f.load()
+}
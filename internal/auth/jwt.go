@@ -0,0 +1,222 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/rs/zerolog"
+)
+
+const (
+	// tolerated difference between the issuer's and our clocks when checking exp and nbf
+	clockSkew = 30 * time.Second
+
+	// smallest rsa modulus accepted in the JWKS, in bits
+	minRSAKeySize = 2048
+	// largest rsa public exponent accepted in the JWKS; common keys use 65537
+	maxRSAExponent = 1<<31 - 1
+)
+
+// signing algorithms accepted in a token's header
+var jwtAlgorithms = []string{"RS256", "RS384", "RS512", "ES256", "ES384", "ES512"}
+
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+type jwtClaims struct {
+	jwt.RegisteredClaims
+	// space separated, as issued for oauth2 scopes
+	Scope string   `json:"scope"`
+	Roles []string `json:"roles"`
+}
+
+// JWTAuthenticator - accepts JWTs signed by a key of a local JWKS file
+// the token's roles are its roles claim and the scopes starting with ScopePrefix, without it
+type JWTAuthenticator struct {
+	// checked against the token's iss and aud claims when set
+	Issuer   string
+	Audience string
+	// prefix of the scopes granting roles, e.g. sqs: for sqs:consumer; scopes grant no roles if empty
+	// so an identity provider's unrelated scopes can't be mistaken for roles
+	ScopePrefix string
+	Logger      zerolog.Logger
+
+	file *watchedFile
+}
+
+// NewJWTAuthenticator - creates new JWTAuthenticator; fails if the JWKS file has no usable keys
+func NewJWTAuthenticator(logger zerolog.Logger, jwksPath string, issuer string, audience string, scopePrefix string) (*JWTAuthenticator, error) {
+	file, err := newWatchedFile(jwksPath, parseJWKS)
+	if err != nil {
+		return nil, err
+	}
+
+	return &JWTAuthenticator{
+		Issuer:      issuer,
+		Audience:    audience,
+		ScopePrefix: scopePrefix,
+		Logger:      logger.With().Str("package", packageName).Logger(),
+		file:        file,
+	}, nil
+}
+
+// parseJWKS - returns the key id -> public key of every signing key in the set
+func parseJWKS(content []byte) (interface{}, error) {
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+
+	if err := json.Unmarshal(content, &set); err != nil {
+		return nil, err
+	}
+
+	keys := make(map[string]crypto.PublicKey)
+	for _, key := range set.Keys {
+		if key.Use != "" && key.Use != "sig" {
+			continue
+		}
+
+		publicKey, err := key.publicKey()
+		if err != nil {
+			return nil, fmt.Errorf("key %v: %w", key.Kid, err)
+		}
+
+		keys[key.Kid] = publicKey
+	}
+
+	if len(keys) == 0 {
+		return nil, errors.New("no signing keys found")
+	}
+
+	return keys, nil
+}
+
+func (k jwk) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+
+		if n.BitLen() < minRSAKeySize {
+			return nil, fmt.Errorf("rsa key of %v bits is under %v bits", n.BitLen(), minRSAKeySize)
+		}
+
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+
+		// checked before converting so oversized exponents can't overflow int
+		if e.Cmp(big.NewInt(3)) < 0 || e.Cmp(big.NewInt(maxRSAExponent)) > 0 || e.Bit(0) == 0 {
+			return nil, fmt.Errorf("unsupported rsa exponent: %v", e)
+		}
+
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve: %v", k.Crv)
+		}
+
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+
+		if !curve.IsOnCurve(x, y) {
+			return nil, errors.New("point isn't on the curve")
+		}
+
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	}
+
+	return nil, fmt.Errorf("unsupported key type: %v", k.Kty)
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+
+	return new(big.Int).SetBytes(b), nil
+}
+
+// Authenticate - accepts the token if its signature and claims are valid
+func (a *JWTAuthenticator) Authenticate(token string) (*Identity, error) {
+	l := a.Logger.With().Str("function", "Authenticate").Logger()
+
+	value, err := a.file.current()
+	if err != nil {
+		l.Err(err).Msg("Failed to reload JWKS, keeping the previous keys")
+	}
+	keys := value.(map[string]crypto.PublicKey)
+
+	options := []jwt.ParserOption{jwt.WithValidMethods(jwtAlgorithms), jwt.WithExpirationRequired(), jwt.WithLeeway(clockSkew)}
+	if a.Issuer != "" {
+		options = append(options, jwt.WithIssuer(a.Issuer))
+	}
+	if a.Audience != "" {
+		options = append(options, jwt.WithAudience(a.Audience))
+	}
+
+	// the signing method checks the key's type matches the token's algorithm
+	claims := &jwtClaims{}
+	_, err = jwt.NewParser(options...).ParseWithClaims(token, claims, func(t *jwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+
+		key, ok := keys[kid]
+		if !ok {
+			return nil, fmt.Errorf("unknown key: %v", kid)
+		}
+
+		return key, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	identity := &Identity{Subject: claims.Subject, Roles: claims.Roles}
+	if a.ScopePrefix != "" {
+		for _, scope := range strings.Fields(claims.Scope) {
+			if role := strings.TrimPrefix(scope, a.ScopePrefix); role != scope && role != "" {
+				identity.Roles = append(identity.Roles, role)
+			}
+		}
+	}
+
+	return identity, nil
+}
@@ -0,0 +1,196 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+)
+
+func encodeSegment(t *testing.T, v interface{}) string {
+	b, err := json.Marshal(v)
+	require.NoError(t, err)
+
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// signJWT - returns a token of the claims signed with the key
+func signJWT(t *testing.T, alg string, kid string, key crypto.Signer, claims map[string]interface{}) string {
+	token := jwt.NewWithClaims(jwt.GetSigningMethod(alg), jwt.MapClaims(claims))
+	token.Header["kid"] = kid
+
+	signed, err := token.SignedString(key)
+	require.NoError(t, err)
+
+	return signed
+}
+
+func encodeBigInt(i *big.Int) string {
+	return base64.RawURLEncoding.EncodeToString(i.Bytes())
+}
+
+func TestJWTAuthenticator(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	jwks := map[string]interface{}{
+		"keys": []map[string]string{
+			{"kty": "RSA", "kid": "rsa", "use": "sig", "n": encodeBigInt(rsaKey.N), "e": encodeBigInt(big.NewInt(int64(rsaKey.E)))},
+			{"kty": "EC", "kid": "ec", "crv": "P-256", "x": encodeBigInt(ecKey.X), "y": encodeBigInt(ecKey.Y)},
+		},
+	}
+	b, err := json.Marshal(jwks)
+	require.NoError(t, err)
+
+	jwksFile := writeFile(t, filepath.Join(t.TempDir(), "jwks.json"), string(b))
+
+	authenticator, err := NewJWTAuthenticator(zerolog.Nop(), jwksFile, "https://issuer.example.com", "sqsservice", "sqs:")
+	require.NoError(t, err)
+
+	claims := func(overrides map[string]interface{}) map[string]interface{} {
+		c := map[string]interface{}{
+			"sub":   "orders-consumer",
+			"iss":   "https://issuer.example.com",
+			"aud":   []string{"sqsservice", "other"},
+			"exp":   time.Now().Add(time.Hour).Unix(),
+			"scope": "openid sqs:consumer",
+		}
+		for k, v := range overrides {
+			c[k] = v
+		}
+		return c
+	}
+
+	testCases := map[string]struct {
+		token     string
+		wantErr   bool
+		wantRoles []string
+	}{
+		"rsa": {
+			token:     signJWT(t, "RS256", "rsa", rsaKey, claims(nil)),
+			wantRoles: []string{"consumer"},
+		},
+		"ec with roles claim and single audience": {
+			token:     signJWT(t, "ES256", "ec", ecKey, claims(map[string]interface{}{"roles": []string{"admin"}, "aud": "sqsservice"})),
+			wantRoles: []string{"admin", "consumer"},
+		},
+		"scopes without the prefix": {
+			token:     signJWT(t, "RS256", "rsa", rsaKey, claims(map[string]interface{}{"scope": "admin consumer sqs:", "roles": []string{"producer"}})),
+			wantRoles: []string{"producer"},
+		},
+		"signed by another key": {
+			token:   signJWT(t, "ES256", "ec", otherKey, claims(nil)),
+			wantErr: true,
+		},
+		"unknown key": {
+			token:   signJWT(t, "ES256", "other", otherKey, claims(nil)),
+			wantErr: true,
+		},
+		"algorithm not matching the key": {
+			token:   signJWT(t, "ES256", "rsa", ecKey, claims(nil)),
+			wantErr: true,
+		},
+		"unsupported algorithm": {
+			token:   signJWT(t, "PS256", "rsa", rsaKey, claims(nil)),
+			wantErr: true,
+		},
+		"unsigned": {
+			token:   encodeSegment(t, map[string]string{"alg": "none", "kid": "rsa"}) + "." + encodeSegment(t, claims(nil)) + ".",
+			wantErr: true,
+		},
+		"expired": {
+			token:   signJWT(t, "RS256", "rsa", rsaKey, claims(map[string]interface{}{"exp": time.Now().Add(-time.Hour).Unix()})),
+			wantErr: true,
+		},
+		"without expiry": {
+			token:   signJWT(t, "RS256", "rsa", rsaKey, claims(map[string]interface{}{"exp": nil})),
+			wantErr: true,
+		},
+		"not valid yet": {
+			token:   signJWT(t, "RS256", "rsa", rsaKey, claims(map[string]interface{}{"nbf": time.Now().Add(time.Hour).Unix()})),
+			wantErr: true,
+		},
+		"wrong issuer": {
+			token:   signJWT(t, "RS256", "rsa", rsaKey, claims(map[string]interface{}{"iss": "https://elsewhere.example.com"})),
+			wantErr: true,
+		},
+		"wrong audience": {
+			token:   signJWT(t, "RS256", "rsa", rsaKey, claims(map[string]interface{}{"aud": "other"})),
+			wantErr: true,
+		},
+		"malformed": {
+			token:   "not-a-jwt",
+			wantErr: true,
+		},
+	}
+
+	for name, tc := range testCases {
+		identity, err := authenticator.Authenticate(tc.token)
+		if tc.wantErr {
+			require.Error(t, err, name)
+			continue
+		}
+
+		require.NoError(t, err, name)
+		require.Equal(t, "orders-consumer", identity.Subject, name)
+		require.Equal(t, tc.wantRoles, identity.Roles, name)
+	}
+}
+
+func TestParseJWKS(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	smallKey, err := rsa.GenerateKey(rand.Reader, 1024)
+	require.NoError(t, err)
+
+	testCases := map[string]struct {
+		jwks string
+	}{
+		"invalid json": {
+			jwks: "{",
+		},
+		"no signing keys": {
+			jwks: `{"keys":[{"kty":"RSA","kid":"enc","use":"enc","n":"AQAB","e":"AQAB"}]}`,
+		},
+		"unsupported key type": {
+			jwks: `{"keys":[{"kty":"oct","kid":"hmac"}]}`,
+		},
+		"point off the curve": {
+			jwks: `{"keys":[{"kty":"EC","kid":"ec","crv":"P-256","x":"AQ","y":"AQ"}]}`,
+		},
+		"rsa key under 2048 bits": {
+			jwks: fmt.Sprintf(`{"keys":[{"kty":"RSA","kid":"rsa","n":"%v","e":"AQAB"}]}`, encodeBigInt(smallKey.N)),
+		},
+		"oversized rsa exponent": {
+			jwks: fmt.Sprintf(`{"keys":[{"kty":"RSA","kid":"rsa","n":"%v","e":"%v"}]}`, encodeBigInt(rsaKey.N),
+				encodeBigInt(new(big.Int).Lsh(big.NewInt(1), 64))),
+		},
+		"even rsa exponent": {
+			jwks: fmt.Sprintf(`{"keys":[{"kty":"RSA","kid":"rsa","n":"%v","e":"Ag"}]}`, encodeBigInt(rsaKey.N)),
+		},
+	}
+
+	for name, tc := range testCases {
+		_, err := parseJWKS([]byte(tc.jwks))
+		require.Error(t, err, name)
+	}
+}
@@ -0,0 +1,85 @@
+package auth
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/rs/zerolog"
+)
+
+type staticToken struct {
+	token string
+	roles []string
+}
+
+// StaticAuthenticator - accepts the shared secrets listed in a mounted file
+// every line holds a token followed by its comma separated roles, e.g. s3cr3t consumer
+// the roles are required so a forgotten column doesn't grant more than intended; empty lines and lines starting with # are skipped
+type StaticAuthenticator struct {
+	Logger zerolog.Logger
+
+	file *watchedFile
+}
+
+// NewStaticAuthenticator - creates new StaticAuthenticator; fails if the file has no tokens
+func NewStaticAuthenticator(logger zerolog.Logger, path string) (*StaticAuthenticator, error) {
+	file, err := newWatchedFile(path, parseStaticTokens)
+	if err != nil {
+		return nil, err
+	}
+
+	return &StaticAuthenticator{Logger: logger.With().Str("package", packageName).Logger(), file: file}, nil
+}
+
+func parseStaticTokens(content []byte) (interface{}, error) {
+	tokens := make([]staticToken, 0)
+
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		// the line isn't part of the error since it holds a secret
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("invalid token line: expected a token and its roles, got %v fields", len(fields))
+		}
+
+		tokens = append(tokens, staticToken{token: fields[0], roles: strings.Split(fields[1], ",")})
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(tokens) == 0 {
+		return nil, errors.New("no tokens found")
+	}
+
+	return tokens, nil
+}
+
+// Authenticate - accepts the token if it's listed in the file
+func (a *StaticAuthenticator) Authenticate(token string) (*Identity, error) {
+	l := a.Logger.With().Str("function", "Authenticate").Logger()
+
+	value, err := a.file.current()
+	if err != nil {
+		l.Err(err).Msg("Failed to reload tokens, keeping the previous ones")
+	}
+
+	for i, t := range value.([]staticToken) {
+		if subtle.ConstantTimeCompare([]byte(t.token), []byte(token)) == 1 {
+			// the token itself is a secret, so it's identified by its position in the file
+			return &Identity{Subject: fmt.Sprintf("static token %v", i+1), Roles: t.roles}, nil
+		}
+	}
+
+	return nil, ErrInvalidToken
+}
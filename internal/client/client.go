@@ -2,22 +2,47 @@ package client
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/alvinlucillo/sqs-processor/internal/auth"
+	"github.com/alvinlucillo/sqs-processor/internal/metrics"
+	"github.com/alvinlucillo/sqs-processor/internal/ratelimit"
+	"github.com/alvinlucillo/sqs-processor/internal/tlsconfig"
+	"github.com/alvinlucillo/sqs-processor/internal/tracing"
 	pb "github.com/alvinlucillo/sqs-processor/protogen/sqs"
 
 	"github.com/rs/zerolog"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.20.0"
+	"go.opentelemetry.io/otel/trace"
 	"google.golang.org/grpc"
+	grpccodes "google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/credentials/local"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
 )
 
 const (
 	packageName = "client"
+	tracerName  = "github.com/alvinlucillo/sqs-processor/internal/client"
+
+	// time given to export the buffered spans on shutdown
+	tracingShutdownTimeout = 5 * time.Second
 )
 
 type Client interface {
-	Run() error
+	Run(ctx context.Context) error
+	Reconfigure(env Environment) error
 }
 
 type SQSClient struct {
@@ -27,17 +52,111 @@ type SQSClient struct {
 	PollingInterval   int
 	VisibilityTimeout int
 	WaitTime          int
-	ErrorRateLimit    int
-	MaximumMessages   int
+	ErrorBudget       *ErrorBudget
+	ErrorAction       string
+	// number of seconds receiving stops for when the error budget is exceeded and ErrorAction is pause
+	ErrorPauseDuration int
+	ErrorBackoffBase   int
+	ErrorBackoffMax    int
+	MaximumMessages    int
+	MaximumWaitTime    int
+	UseStream          bool
+	MaximumUnacked     int
+	Handler            Handler
+	Concurrency        int
+	QueueDepth         int
+	DrainTimeout       int
+	PollingPolicy      PollingPolicy
+	// serves /metrics while running when set
+	Metrics *metrics.Server
+	// exports spans when set
+	Tracing *tracing.Provider
+	// skips the messages whose key was already processed when set
+	DedupStore DedupStore
+	// selects the key of a message in its json body; the sqs message id is the key if nil
+	DedupKeyPath *JSONPath
+	// number of receives after which a failing message is routed away instead of retried; 0 disables it
+	MaxAttempts int
+	// where messages over MaxAttempts go when set, instead of the sqsservice's dead-letter queue
+	Quarantine *QuarantineFile
+	// unwrap the envelopes of the messages, in order, before they're handled
+	Envelopes []EnvelopeDecoder
+
+	// guards the settings Reconfigure changes while running
+	mu sync.RWMutex
+	// environment the settings were last applied from
+	env Environment
+	// whether PollingPolicy was set by WithPollingPolicy, so reloads keep it
+	customPolling bool
+}
+
+// Option - customizes the client created by NewClient
+type Option func(*SQSClient)
+
+// WithDedupStore - skips the messages whose key is in store instead of the store of the environment
+// the client closes the store once it stops running
+func WithDedupStore(store DedupStore) Option {
+	return func(s *SQSClient) {
+		s.DedupStore = store
+	}
+}
+
+// WithEnvelopeDecoders - unwraps the envelopes of decoders, tried in order, instead of the ones of the environment
+func WithEnvelopeDecoders(decoders ...EnvelopeDecoder) Option {
+	return func(s *SQSClient) {
+		s.Envelopes = decoders
+	}
+}
+
+// WithPollingPolicy - replaces the polling policy chosen through the environment
+func WithPollingPolicy(policy PollingPolicy) Option {
+	return func(s *SQSClient) {
+		s.PollingPolicy = policy
+	}
 }
 
 type Environment struct {
-	// sqsservice port the client will connect to
+	// sqsservice port the client will connect to on localhost
 	SQSServicePort int `required:"true" default:"50051"`
-	// the limit during which the polling process will terminate
+	// full grpc target of the sqsservice; overrides SQSServicePort
+	// e.g. host:port, dns:///host:port or unix:///path/to/socket
+	SQSServiceTarget string `split_words:"true"`
+	// pem file of the certificate authorities the sqsservice's certificate is verified against
+	// setting it or TLSCertFile enables TLS; the system roots are used if it's unset
+	TLSCAFile string `envconfig:"TLS_CA_FILE"`
+	// pem files of the client's certificate and key presented for mutual TLS
+	TLSCertFile string `envconfig:"TLS_CERT_FILE"`
+	TLSKeyFile  string `envconfig:"TLS_KEY_FILE"`
+	// name verified in the sqsservice's certificate; defaults to the target's host
+	TLSServerName string `envconfig:"TLS_SERVER_NAME"`
+	// file of the bearer token sent with every call, either a static token or a JWT
+	// reread once it changes so rotated tokens are picked up; requires TLS or a unix socket SQSServiceTarget
+	AuthTokenFile string `envconfig:"AUTH_TOKEN_FILE"`
+	// number of errors within ErrorWindow over which the error budget is exceeded
+	// 0 disables the limit
 	ErrorRateLimit int `required:"true" default:"10"`
+	// number of seconds of the sliding window errors are counted in
+	ErrorWindow int `split_words:"true" default:"60"`
+	// ratio of errors to calls within ErrorWindow over which the error budget is exceeded
+	// 0 disables the limit
+	ErrorRatioLimit float64 `split_words:"true" default:"0"`
+	// number of calls within ErrorWindow needed before ErrorRatioLimit is checked
+	ErrorMinSamples int `split_words:"true" default:"20"`
+	// what the client does once the error budget is exceeded: exit, pause or degrade
+	ErrorAction string `split_words:"true" default:"exit"`
+	// number of seconds the client stops receiving for when ErrorAction is pause
+	ErrorPauseDuration int `split_words:"true" default:"60"`
+	// number of seconds to wait after a failed call; doubles on every consecutive failure up to ErrorBackoffMax
+	ErrorBackoffBase int `split_words:"true" default:"1"`
+	ErrorBackoffMax  int `split_words:"true" default:"30"`
 	// number of seconds between each client's poll action
+	// with the adaptive strategy, the longest the client waits after empty receives
 	PollingInterval int `required:"true" default:"5"`
+	// fixed always waits PollingInterval between polls
+	// adaptive polls again right away after a full batch and backs off on empty receives
+	PollingStrategy string `split_words:"true" default:"adaptive"`
+	// number of seconds the adaptive strategy waits after a partial batch or the first empty receive
+	PollingMinInterval int `split_words:"true" default:"1"`
 	// number of seconds the received message becomes invisible to other consumers
 	// passed to the sqsservice
 	VisibilityTimeout int `required:"true" default:"5"`
@@ -48,101 +167,896 @@ type Environment struct {
 	WaitTime int `required:"true" default:"5"`
 	// number of messages the sqs api will retrieve from the queue
 	// passed to the sqsservice
+	// over 10 makes the sqsservice aggregate parallel receives for up to MaximumWaitTime
 	MaximumMessages int `required:"true" default:"5"`
+	// number of seconds the sqsservice keeps aggregating receives for batches over 10
+	// passed to the sqsservice; 0 means WaitTime
+	MaximumWaitTime int `split_words:"true" default:"0"`
+	// subscribes to messages pushed by the sqsservice instead of polling for them
+	UseStream bool `split_words:"true" default:"false"`
+	// number of received messages not yet deleted before the sqsservice stops pushing more
+	// only used when UseStream is set
+	MaximumUnacked int `split_words:"true" default:"10"`
+	// number of messages processed concurrently by the handler
+	Concurrency int `required:"true" default:"1"`
+	// number of received messages waiting for a free worker
+	// the client only polls for more messages once workers and the queue have room
+	// raised to MaximumMessages minus Concurrency so a receive can get MaximumMessages at once
+	QueueDepth int `split_words:"true" default:"0"`
+	// number of seconds to wait for in-flight messages when shutting down
+	// then the handlers' context is cancelled and messages no worker started on are released back to the queue
+	// handlers that haven't returned 5 seconds later are given up on and logged
+	DrainTimeout int `split_words:"true" default:"20"`
+	// address /metrics is served on for prometheus; disabled if empty
+	// differs from the sqsservice's default so both fit in the same pod
+	MetricsAddress string `split_words:"true" default:":9091"`
+	// level of the logs: trace, debug, info, warn, error or disabled
+	// toggled with debug at runtime on SIGUSR1
+	LogLevel string `split_words:"true" default:"info"`
+	// json, or console for humans
+	LogFormat string `split_words:"true" default:"json"`
+	// number of identical debug and info messages logged per LogSamplePeriod; 0 logs every message
+	// messages are compared by their text, not their fields, e.g. the polling logs of an idle queue
+	LogSampleBurst int `split_words:"true" default:"0"`
+	// number of seconds identical messages are counted over
+	LogSamplePeriod int `split_words:"true" default:"1"`
+	// where spans are exported: none, otlp or file
+	// otlp sends them to the collector set by the standard OTEL_EXPORTER_OTLP_* variables
+	// the trace context is propagated through calls and messages even if it's none
+	TracingExporter string `split_words:"true" default:"none"`
+	// file spans are appended to as json when TracingExporter is file
+	TracingFile string `split_words:"true"`
+	// ratio of new traces that are sampled; messages sent within a trace keep its decision
+	TracingSampleRatio float64 `split_words:"true" default:"1"`
+	// number of times a call rejected over the sqsservice's rate limits is retried after the wait it asks for
+	RateLimitRetries int `split_words:"true" default:"3"`
+	// file the keys of processed messages are kept in, so redelivered duplicates are deleted without being handled
+	// deduplication is disabled if unset; mount a volume kept across restarts to skip duplicates after them
+	DedupFile string `split_words:"true"`
+	// number of seconds a processed key is kept
+	DedupTTL int `split_words:"true" default:"86400"`
+	// JSONPath of the key in the message's json body, after unwrapping its envelopes, e.g. $.order.id; defaults to the sqs message id
+	// messages without the key are deduplicated by their sqs message id
+	DedupKeyPath string `split_words:"true"`
+	// number of times a message is received before it stops being retried
+	// messages failing their last attempt, or received more often, e.g. since they crash the client,
+	// are moved to the sqsservice's dead-letter queue with their last error, even if the queue has no redrive policy
+	// 0 retries messages until the queue's redrive policy moves them
+	MaxAttempts int `split_words:"true" default:"0"`
+	// file messages over MaxAttempts are appended to as json lines, then deleted, instead of being dead-lettered
+	QuarantineFile string `split_words:"true"`
+	// comma-separated envelopes unwrapped before messages are handled: sns, eventbridge and s3
+	// e.g. sns,s3 for s3 event notifications published to a topic the queue is subscribed to
+	Envelopes string `split_words:"true"`
+	// whether the queue's sns subscription delivers raw messages, whose metadata are their message attributes
+	SNSRawDelivery bool `envconfig:"SNS_RAW_DELIVERY" default:"false"`
+	// pem file of the sns signing certificate notifications are verified against; unverified if unset
+	// notifications with an invalid signature are dead-lettered
+	SNSCertFile string `envconfig:"SNS_CERT_FILE"`
+	// comma-separated arns of the topics notifications are accepted from; any topic if unset
+	// required with SNSCertFile since sns signs every account's notifications with the same certificate
+	SNSTopicArns string `envconfig:"SNS_TOPIC_ARNS"`
+	// number of seconds after which verified notifications are rejected so they can't be replayed; 0 accepts any age
+	SNSMaxAge int `envconfig:"SNS_MAX_AGE" default:"86400"`
+	// routes dispatching messages to the handlers the program registers by name, as a yaml or json list
+	// e.g. [{name: orders, attribute: type, value: order, concurrency: 2}]; see RouteConfig
+	Routes RouteConfigs `split_words:"true"`
+	// yaml or json file the settings are read from; the environment variables override it
+	ConfigFile string `split_words:"true"`
+	// number of seconds between checks of ConfigFile for changes, which apply the settings Reconfigure supports
+	// 0 disables reloading
+	ConfigReloadInterval int `split_words:"true" default:"10"`
+}
+
+// splitList - returns the trimmed non-empty items of a comma-separated list
+func splitList(list string) []string {
+	items := make([]string, 0)
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+
+	return items
+}
+
+// isUnixTarget - checks if the grpc target is a unix socket, e.g. unix:///path/to/socket
+func isUnixTarget(target string) bool {
+	return strings.HasPrefix(target, "unix:")
 }
 
 // NewClient - initializes a new client app
-func NewClient(logger zerolog.Logger, env Environment) (Client, error) {
+// received messages are passed to handler; nil deletes every message without processing it
+// on error, closes the connection, listener, tracing provider and files it opened
+func NewClient(logger zerolog.Logger, env Environment, handler Handler, opts ...Option) (_ Client, err error) {
 	logger = logger.With().Str("package", packageName).Logger()
 	l := logger.With().Str("function", "NewClient").Logger()
 
+	// closed in reverse order if a later step fails
+	var closers []func() error
+	defer func() {
+		if err == nil {
+			return
+		}
+
+		for i := len(closers) - 1; i >= 0; i-- {
+			if closeErr := closers[i](); closeErr != nil {
+				l.Error().Err(closeErr).Msg("Unable to clean up")
+			}
+		}
+	}()
+
+	if err := env.Validate(); err != nil {
+		l.Error().Err(err).Msg("Invalid environment")
+		return nil, err
+	}
+
+	pollingPolicy := newPollingPolicy(env)
+
+	errorBudget := NewErrorBudget(time.Duration(env.ErrorWindow)*time.Second, env.ErrorRateLimit, env.ErrorRatioLimit, env.ErrorMinSamples)
+
 	sqsClient := &SQSClient{Logger: logger, PollingInterval: env.PollingInterval,
-		VisibilityTimeout: env.VisibilityTimeout, WaitTime: env.WaitTime, ErrorRateLimit: env.ErrorRateLimit, MaximumMessages: env.MaximumMessages}
+		VisibilityTimeout: env.VisibilityTimeout, WaitTime: env.WaitTime, ErrorBudget: errorBudget, ErrorAction: env.ErrorAction,
+		ErrorPauseDuration: env.ErrorPauseDuration, ErrorBackoffBase: env.ErrorBackoffBase, ErrorBackoffMax: env.ErrorBackoffMax,
+		MaximumMessages: env.MaximumMessages,
+		MaximumWaitTime: env.MaximumWaitTime, UseStream: env.UseStream, MaximumUnacked: env.MaximumUnacked, Handler: handler,
+		Concurrency: env.Concurrency, QueueDepth: env.QueueDepth, DrainTimeout: env.DrainTimeout, PollingPolicy: pollingPolicy,
+		MaxAttempts: env.MaxAttempts, env: env}
+
+	if sqsClient.Handler == nil {
+		sqsClient.Handler = noopHandler
+	}
+
+	for _, opt := range opts {
+		opt(sqsClient)
+	}
+	sqsClient.customPolling = sqsClient.PollingPolicy != pollingPolicy
+
+	if sqsClient.Envelopes == nil {
+		decoders, err := ParseEnvelopes(env.Envelopes, SNSConfig{Raw: env.SNSRawDelivery, CertFile: env.SNSCertFile,
+			TopicArns: splitList(env.SNSTopicArns), MaxAge: time.Duration(env.SNSMaxAge) * time.Second})
+		if err != nil {
+			l.Error().Err(err).Msg("Failed to create envelope decoders")
+			return nil, err
+		}
+		sqsClient.Envelopes = decoders
+	}
 
 	// establishing connection to sqsservice
-	target := fmt.Sprintf("localhost:%v", env.SQSServicePort)
-	conn, err := grpc.Dial(target, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	target := env.SQSServiceTarget
+	if target == "" {
+		target = fmt.Sprintf("localhost:%v", env.SQSServicePort)
+	}
+
+	l.Info().Msgf("Connecting to sqsservice at %v", target)
+	transportCredentials := insecure.NewCredentials()
+
+	if env.TLSCAFile != "" || env.TLSCertFile != "" {
+		reloader, err := tlsconfig.NewReloader(logger, tlsconfig.Files{CertFile: env.TLSCertFile, KeyFile: env.TLSKeyFile, CAFile: env.TLSCAFile})
+		if err != nil {
+			l.Error().Err(err).Msg("Failed to load TLS certificates")
+			return nil, err
+		}
+
+		transportCredentials = credentials.NewTLS(reloader.ClientConfig(env.TLSServerName))
+	} else if isUnixTarget(target) {
+		// unix sockets can't be reached from the network, so tokens are sent over them without TLS
+		transportCredentials = local.NewCredentials()
+	}
+
+	dialOpts := []grpc.DialOption{
+		grpc.WithTransportCredentials(transportCredentials),
+		// retries go first so every attempt is measured and traced
+		grpc.WithChainUnaryInterceptor(ratelimit.UnaryClientInterceptor(env.RateLimitRetries), rpcMetrics.UnaryClientInterceptor(),
+			otelgrpc.UnaryClientInterceptor(otelgrpc.WithPropagators(tracing.Propagator))),
+		grpc.WithChainStreamInterceptor(rpcMetrics.StreamClientInterceptor(), otelgrpc.StreamClientInterceptor(otelgrpc.WithPropagators(tracing.Propagator))),
+	}
+
+	if env.AuthTokenFile != "" {
+		tokenCredentials, err := auth.NewTokenCredentials(env.AuthTokenFile)
+		if err != nil {
+			l.Error().Err(err).Msg("Failed to load auth token")
+			return nil, err
+		}
+
+		dialOpts = append(dialOpts, grpc.WithPerRPCCredentials(tokenCredentials))
+	}
+
+	conn, err := grpc.Dial(target, dialOpts...)
 	if err != nil {
 		l.Error().Err(err).Msg("Failed to connect")
 		return nil, err
 	}
+	closers = append(closers, conn.Close)
 
 	client := pb.NewSQSServiceClient(conn)
 
 	sqsClient.Client = client
 	sqsClient.Conn = conn
 
+	if env.MetricsAddress != "" {
+		listener, err := net.Listen("tcp", env.MetricsAddress)
+		if err != nil {
+			l.Error().Err(err).Msg("Failed to create metrics listener")
+			return nil, err
+		}
+		// not served yet, so shutting down the server wouldn't close it
+		closers = append(closers, listener.Close)
+
+		sqsClient.Metrics = metrics.NewServer(listener)
+	}
+
+	provider, err := tracing.NewProvider(context.Background(), tracing.Config{
+		Exporter:    env.TracingExporter,
+		File:        env.TracingFile,
+		SampleRatio: env.TracingSampleRatio,
+		ServiceName: "sqsclient",
+	})
+	if err != nil {
+		l.Error().Err(err).Msg("Failed to set up tracing")
+		return nil, err
+	}
+	if provider != nil {
+		closers = append(closers, func() error {
+			ctx, cancel := context.WithTimeout(context.Background(), tracingShutdownTimeout)
+			defer cancel()
+
+			return provider.Shutdown(ctx)
+		})
+	}
+	sqsClient.Tracing = provider
+
+	if env.DedupKeyPath != "" {
+		// already validated
+		sqsClient.DedupKeyPath, _ = ParseJSONPath(env.DedupKeyPath)
+	}
+
+	if sqsClient.DedupStore == nil && env.DedupFile != "" {
+		store, err := NewBoltDedupStore(env.DedupFile, time.Duration(env.DedupTTL)*time.Second)
+		if err != nil {
+			l.Error().Err(err).Msg("Failed to open dedup store")
+			return nil, err
+		}
+		// stores passed as options are left to their owner
+		closers = append(closers, store.Close)
+		sqsClient.DedupStore = store
+	}
+
+	if env.QuarantineFile != "" {
+		quarantine, err := NewQuarantineFile(env.QuarantineFile)
+		if err != nil {
+			l.Error().Err(err).Msg("Failed to open quarantine file")
+			return nil, err
+		}
+		sqsClient.Quarantine = quarantine
+	}
+
 	return sqsClient, nil
 }
 
 // Run - drives the polling process
-// only stops when error or system terminates it
-func (s *SQSClient) Run() error {
+// only stops when error or ctx is done
+// on ctx done, stops receiving and waits up to DrainTimeout for in-flight messages, then up to 5 seconds for the cancelled handlers, before closing the connection
+func (s *SQSClient) Run(ctx context.Context) error {
 	l := s.Logger.With().Str("function", "Run").Logger()
 
-	ctx := context.Background()
+	if s.Metrics != nil {
+		go func() {
+			if err := s.Metrics.Serve(); err != nil {
+				l.Error().Err(err).Msg("Failed to serve metrics")
+			}
+		}()
+	}
 
-	// establishes parameters for the sqsservice
-	req := &pb.SQSReceiveMessageRequest{VisibilityTimeout: int64(s.VisibilityTimeout), WaitTime: int64(s.WaitTime), MaximumNumberOfMessages: int64(s.MaximumMessages)}
-	pollCounter := 0
-	errCounter := 0
-	processed := 0
+	// counters are shared with the workers
+	var errCounter, processed int64
+
+	// stops receiving once a worker runs into an error retrying can't fix
+	receiveCtx, stopReceiving := context.WithCancel(ctx)
+	defer stopReceiving()
+
+	var fatalMu sync.Mutex
+	var fatalErr error
+
+	// the pool has room for a whole receive of MaximumMessages even if there are fewer workers
+	s.mu.RLock()
+	queueDepth := s.QueueDepth
+	if depth := s.MaximumMessages - s.Concurrency; depth > queueDepth {
+		queueDepth = depth
+	}
+	s.mu.RUnlock()
+
+	pool := newWorkerPool(s.Concurrency, queueDepth, func(workCtx context.Context, msg *pb.SQSResponseMessage) {
+		if err := s.process(workCtx, msg); err != nil {
+			s.ErrorBudget.RecordError()
+			atomic.AddInt64(&errCounter, 1)
+
+			// every message over its maximum attempts would be handled again forever
+			if errors.Is(err, ErrNoDeadLetterQueue) {
+				fatalMu.Lock()
+				if fatalErr == nil {
+					fatalErr = err
+				}
+				fatalMu.Unlock()
+
+				stopReceiving()
+			}
+			return
+		}
+
+		s.ErrorBudget.RecordSuccess()
+		atomic.AddInt64(&processed, 1)
+	})
 
-	// logs summary and closes connection before leaving the function
+	// drains the workers, logs summary and closes connection before leaving the function
 	defer func() {
-		l.Debug().Msgf("Number of processed messages %v", processed)
-		l.Debug().Msgf("Number of polls made %v", pollCounter)
-		l.Debug().Msgf("Number of errors encountered %v", errCounter)
+		l.Info().Msg("Draining in-flight messages")
+
+		s.mu.RLock()
+		drainTimeout := time.Duration(s.DrainTimeout) * time.Second
+		s.mu.RUnlock()
+
+		// returns once every worker is done, so nothing is settled after the connection and files are closed
+		// except by handlers that ignore their cancellation, which are given up on
+		unstarted, abandoned := pool.stop(drainTimeout)
+		for _, msg := range unstarted {
+			s.release(msg)
+		}
+
+		for _, msg := range abandoned {
+			l.Error().Str("messageID", msg.MessageID).Msg("Handler didn't return after being cancelled, leaving the message to its visibility timeout")
+		}
+
+		l.Debug().Msgf("Number of processed messages %v", atomic.LoadInt64(&processed))
+		l.Debug().Msgf("Number of errors encountered %v", atomic.LoadInt64(&errCounter))
 
 		if err := s.Conn.Close(); err != nil {
 			l.Error().Err(err).Msg("Unable to close connection")
 		}
+
+		if s.Metrics != nil {
+			if err := s.Metrics.Shutdown(context.Background()); err != nil {
+				l.Error().Err(err).Msg("Unable to shut down metrics")
+			}
+		}
+
+		if s.DedupStore != nil {
+			if err := s.DedupStore.Close(); err != nil {
+				l.Error().Err(err).Msg("Unable to close dedup store")
+			}
+		}
+
+		if s.Quarantine != nil {
+			if err := s.Quarantine.Close(); err != nil {
+				l.Error().Err(err).Msg("Unable to close quarantine file")
+			}
+		}
+
+		if s.Tracing != nil {
+			ctx, cancel := context.WithTimeout(context.Background(), tracingShutdownTimeout)
+			defer cancel()
+
+			if err := s.Tracing.Shutdown(ctx); err != nil {
+				l.Error().Err(err).Msg("Unable to shut down tracing")
+			}
+		}
+	}()
+
+	var err error
+	if s.UseStream {
+		err = s.stream(receiveCtx, pool, &errCounter)
+	} else {
+		err = s.poll(receiveCtx, pool, &errCounter)
+	}
+
+	fatalMu.Lock()
+	fatal := fatalErr
+	fatalMu.Unlock()
+
+	if fatal != nil {
+		l.Error().Err(fatal).Msg("Stopped receiving messages")
+		return fatal
+	}
+
+	// stopping through ctx is a clean shutdown
+	if ctx.Err() != nil {
+		l.Info().Msg("Stopped receiving messages")
+		return nil
+	}
+
+	return err
+}
+
+// checkBudget - applies ErrorAction once the error budget is exceeded
+// returns whether the client should keep receiving in degraded mode
+func (s *SQSClient) checkBudget(ctx context.Context) (bool, error) {
+	l := s.Logger.With().Str("function", "checkBudget").Logger()
+
+	if !s.ErrorBudget.Exceeded() {
+		return false, nil
+	}
+
+	s.mu.RLock()
+	action, pauseDuration := s.ErrorAction, s.ErrorPauseDuration
+	s.mu.RUnlock()
+
+	switch action {
+	case ErrorActionPause:
+		l.Warn().Msgf("Error budget exceeded with %v, pausing for %v second(s)", s.ErrorBudget, pauseDuration)
+
+		select {
+		case <-ctx.Done():
+			return false, ctx.Err()
+		case <-time.After(time.Duration(pauseDuration) * time.Second):
+		}
+
+		s.ErrorBudget.Reset()
+
+		return false, nil
+	case ErrorActionDegrade:
+		return true, nil
+	default:
+		return false, fmt.Errorf("error budget exceeded with %v", s.ErrorBudget)
+	}
+}
+
+// pollingInterval - returns the wait before the next poll; falls back to PollingInterval without a policy
+func (s *SQSClient) pollingInterval(requested int, received int) time.Duration {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.PollingPolicy == nil {
+		return time.Duration(s.PollingInterval) * time.Second
+	}
+
+	return s.PollingPolicy.Next(requested, received)
+}
+
+// wait - blocks for the duration or until ctx is done
+func wait(ctx context.Context, d time.Duration) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(d):
+		return nil
+	}
+}
+
+// poll - polls for sqs messages indefinitely until the error budget is exceeded
+// only polls for as many messages as the worker pool can take
+func (s *SQSClient) poll(ctx context.Context, pool *workerPool, errCounter *int64) error {
+	l := s.Logger.With().Str("function", "poll").Logger()
+
+	pollCounter := 0
+	degraded := false
+	retry := &backoff{Base: time.Duration(s.ErrorBackoffBase) * time.Second, Max: time.Duration(s.ErrorBackoffMax) * time.Second}
+
+	defer func() {
+		l.Debug().Msgf("Number of polls made %v", pollCounter)
 	}()
 
-	// pools for sqs messages indefinitely until error rate exceeds limit
-	// once received, each message is deleted from the queue
 	for {
+		exceeded, err := s.checkBudget(ctx)
+		if err != nil {
+			return err
+		}
+
+		if exceeded != degraded {
+			degraded = exceeded
+			l.Warn().Bool("degraded", degraded).Msgf("Error budget at %v", s.ErrorBudget)
+		}
+
+		s.mu.RLock()
+		maximumMessages := s.MaximumMessages
+		// establishes parameters for the sqsservice
+		req := &pb.SQSReceiveMessageRequest{VisibilityTimeout: int64(s.VisibilityTimeout), WaitTime: int64(s.WaitTime),
+			MaximumWaitTime: int64(s.MaximumWaitTime)}
+		s.mu.RUnlock()
+
+		// a degraded client receives one message at a time
+		if degraded {
+			maximumMessages = 1
+		}
+
+		// waits for workers to have capacity before polling
+		capacity, err := pool.reserve(ctx, maximumMessages)
+		if err != nil {
+			return err
+		}
+
 		pollCounter++
 
-		l.Info().Msgf("Polling count: %v", pollCounter)
+		l.Info().Int("pollCount", pollCounter).Msg("Polling")
+
+		req.MaximumNumberOfMessages = int64(capacity)
+
+		// an in-flight receive isn't cancelled since the messages it gets would stay invisible until they time out
+		// but a throttled one isn't retried once ctx is done so it doesn't hold up the shutdown
+		resp, err := s.Client.ReceiveMessage(ratelimit.WithRetryContext(context.Background(), ctx), req)
+		if retryAfter, throttled := ratelimit.RetryAfter(err); throttled {
+			pool.release(capacity)
+
+			// the sqsservice is only protecting its quotas, so it's not counted as an error
+			l.Warn().Err(err).Msg("Receive throttled by sqsservice")
+			if err := wait(ctx, retryAfter); err != nil {
+				return err
+			}
 
-		resp, err := s.Client.ReceiveMessage(ctx, req)
+			continue
+		}
 		if err != nil {
+			pool.release(capacity)
+
 			l.Error().Err(err).Msg("Unable to receive message from sqs")
-			errCounter++
+			s.ErrorBudget.RecordError()
+			atomic.AddInt64(errCounter, 1)
 
-			if errCounter > s.ErrorRateLimit {
-				return fmt.Errorf("number of errors exceeded limit: %v", errCounter)
+			// backs off before polling again
+			if err := wait(ctx, retry.next()); err != nil {
+				return err
 			}
 
 			continue
 		}
 
+		s.ErrorBudget.RecordSuccess()
+		retry.reset()
+
+		received := 0
 		if resp != nil {
-			l.Info().Msgf("Received %v message(s)", len(resp.Messages))
+			received = len(resp.Messages)
+		}
+
+		receives.Inc()
+		if received == 0 {
+			emptyReceives.Inc()
+		}
+		messagesReceived.Add(float64(received))
+
+		l.Info().Msgf("Received %v message(s)", received)
+
+		for i := 0; i < received; i++ {
+			pool.submit(resp.Messages[i])
+		}
+		pool.release(capacity - received)
 
-			for _, msg := range resp.Messages {
-				l.Info().Msgf("Deleting message %v", msg)
+		// waits as long as the polling policy decides before going through another polling
+		// a degraded client waits at least as long as the maximum backoff
+		pollingInterval := s.pollingInterval(capacity, received)
+		if degraded && retry.Max > pollingInterval {
+			pollingInterval = retry.Max
+		}
 
-				deleteReq := &pb.SQSDeleteMessageRequest{MessageID: msg.MessageID}
-				_, err := s.Client.DeleteMessage(ctx, deleteReq)
+		if err := wait(ctx, pollingInterval); err != nil {
+			return err
+		}
+	}
+}
 
+// stream - receives the messages pushed by the sqsservice and passes them to the worker pool
+// reconnects when the stream breaks until the error budget is exceeded
+func (s *SQSClient) stream(ctx context.Context, pool *workerPool, errCounter *int64) error {
+	l := s.Logger.With().Str("function", "stream").Logger()
+
+	retry := &backoff{Base: time.Duration(s.ErrorBackoffBase) * time.Second, Max: time.Duration(s.ErrorBackoffMax) * time.Second}
+
+	for {
+		degraded, err := s.checkBudget(ctx)
+		if err != nil {
+			return err
+		}
+
+		s.mu.RLock()
+		req := &pb.SQSStreamMessagesRequest{VisibilityTimeout: int64(s.VisibilityTimeout), WaitTime: int64(s.WaitTime),
+			MaximumNumberOfMessages: int64(s.MaximumMessages), MaximumUnackedMessages: int64(s.MaximumUnacked)}
+		interval := time.Duration(s.PollingInterval) * time.Second
+		s.mu.RUnlock()
+
+		// a degraded client only holds one message at a time
+		if degraded {
+			req.MaximumNumberOfMessages = 1
+			req.MaximumUnackedMessages = 1
+		}
+
+		streamCtx, cancel := context.WithCancel(ctx)
+
+		stream, err := s.Client.StreamMessages(streamCtx, req)
+		if err == nil {
+			l.Info().Bool("degraded", degraded).Msg("Subscribed to messages")
+
+			for {
+				var msg *pb.SQSResponseMessage
+				msg, err = stream.Recv()
 				if err != nil {
-					l.Error().Err(err).Msgf("Unable to delete message: %v", msg.MessageID)
-					errCounter++
+					break
+				}
+
+				s.ErrorBudget.RecordSuccess()
+				retry.reset()
+				messagesReceived.Inc()
 
-					if errCounter > s.ErrorRateLimit {
-						return fmt.Errorf("number of errors exceeded limit: %v", errCounter)
-					}
+				// blocks while every worker is busy
+				if _, err = pool.reserve(ctx, 1); err != nil {
+					cancel()
+					return err
 				}
+				pool.submit(msg)
 
-				l.Info().Msgf("Message deleted successfully: %v", msg.MessageID)
+				// subscribes again whenever the budget's state changes
+				exceeded, err := s.checkBudget(ctx)
+				if err != nil {
+					cancel()
+					return err
+				}
+
+				if exceeded != degraded {
+					break
+				}
 			}
-		} else {
-			l.Info().Msg("No messages received")
 		}
 
-		// waits for the set time before going through another polling
-		time.Sleep(time.Duration(s.PollingInterval) * time.Second)
+		cancel()
+
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		retryAfter, throttled := ratelimit.RetryAfter(err)
+
+		switch {
+		case err == nil:
+			l.Info().Msgf("Error budget at %v, subscribing again", s.ErrorBudget)
+		case err == io.EOF:
+			l.Info().Msg("Stream ended by sqsservice")
+		case throttled:
+			// the sqsservice is only protecting its quotas, so it's not counted as an error
+			l.Warn().Err(err).Msg("Subscription throttled by sqsservice")
+			interval = retryAfter
+		default:
+			l.Error().Err(err).Msg("Unable to receive message from stream")
+			s.ErrorBudget.RecordError()
+			atomic.AddInt64(errCounter, 1)
+
+			interval = retry.next()
+		}
+
+		// waits before subscribing again
+		if err := wait(ctx, interval); err != nil {
+			return err
+		}
+	}
+}
+
+// process - passes the message to the handler and settles it based on the outcome
+// deletes it on success, dead-letters it on a permanent error, otherwise releases it for retry
+// the handler and the settling calls are traced as part of the trace the message was sent in
+// only returns errors of the calls to the sqsservice
+func (s *SQSClient) process(ctx context.Context, msg *pb.SQSResponseMessage) (err error) {
+	l := s.Logger.With().Str("function", "process").Str("messageID", msg.MessageID).Logger()
+
+	ctx, span := tracing.Tracer(tracerName).Start(tracing.Extract(ctx, msg.TraceContext), "process",
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(
+			semconv.MessagingSystem("aws_sqs"),
+			semconv.MessagingOperationProcess,
+		))
+	defer func() {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, "settling failed")
+		}
+		span.End()
+	}()
+
+	message := &Message{ID: msg.MessageID, MessageID: msg.SqsMessageID, Body: msg.MessageBody, RawBody: msg.MessageBody,
+		ReceiveCount: msg.ReceiveCount, Attributes: msg.Attributes}
+
+	// malformed envelopes are dead-lettered like messages the handler can't process
+	unwrapErr := unwrap(message, s.Envelopes)
+
+	key := ""
+	if unwrapErr == nil {
+		key = s.dedupKey(message)
+	}
+	if key != "" {
+		seen, err := s.DedupStore.Seen(key)
+		if err != nil {
+			// handled anyway since a duplicate is better than a lost message
+			l.Error().Err(err).Msg("Unable to check for duplicate")
+		} else if seen {
+			return s.deleteDuplicate(tracing.Detach(ctx), msg, key)
+		}
 	}
+
+	s.mu.RLock()
+	maxAttempts := int64(s.MaxAttempts)
+	s.mu.RUnlock()
+
+	// not handled again, e.g. since it crashed the client on every previous attempt
+	if maxAttempts > 0 && msg.ReceiveCount > maxAttempts {
+		messagesFailed.WithLabelValues("max_attempts").Inc()
+		return s.routeExhausted(tracing.Detach(ctx), message, fmt.Errorf("received %v times, over the maximum of %v attempts", msg.ReceiveCount, maxAttempts))
+	}
+
+	start := time.Now()
+	handlerErr := unwrapErr
+	if handlerErr == nil {
+		handlerErr = s.Handler.Handle(ctx, message)
+	}
+	if handlerErr != nil {
+		span.RecordError(handlerErr)
+		span.SetStatus(codes.Error, "handler failed")
+	}
+
+	// given up on before the handler got to it, e.g. while waiting for its route, or cancelled by the client stopping
+	// so it's no failed attempt
+	cancelled := ctx.Err() != nil && errors.Is(handlerErr, ctx.Err())
+	notHandled := errors.Is(handlerErr, errNotHandled) || cancelled
+
+	// failed its last attempt
+	exhausted := handlerErr != nil && !IsPermanent(handlerErr) && !notHandled && maxAttempts > 0 && msg.ReceiveCount >= maxAttempts
+
+	result := "success"
+	switch {
+	case notHandled:
+		result = "not_handled"
+	case IsPermanent(handlerErr):
+		result = "permanent_error"
+		messagesFailed.WithLabelValues("dead_letter").Inc()
+	case exhausted:
+		result = "error"
+		messagesFailed.WithLabelValues("max_attempts").Inc()
+	case handlerErr != nil:
+		result = "error"
+		messagesFailed.WithLabelValues("retry").Inc()
+	}
+	handlerDuration.WithLabelValues(result).Observe(time.Since(start).Seconds())
+
+	// the outcome is settled even if ctx was cancelled while handling the message
+	ctx = tracing.Detach(ctx)
+
+	// recorded before deleting so the message is skipped if the delete fails and it's redelivered
+	if handlerErr == nil && key != "" {
+		if err := s.DedupStore.Record(key); err != nil {
+			l.Error().Err(err).Msg("Unable to record processed message")
+		}
+	}
+
+	switch {
+	case handlerErr == nil:
+		if _, err := s.Client.DeleteMessage(ctx, &pb.SQSDeleteMessageRequest{MessageID: msg.MessageID}); err != nil {
+			l.Error().Err(err).Msg("Unable to delete message")
+			return err
+		}
+
+		messagesDeleted.Inc()
+
+		l.Info().Msg("Message deleted successfully")
+
+	case IsPermanent(handlerErr):
+		l.Warn().Err(handlerErr).Msg("Message can't be processed, dead-lettering it")
+
+		req := &pb.SQSDeadLetterMessageRequest{MessageID: msg.MessageID, MessageBody: msg.MessageBody, Reason: handlerErr.Error()}
+		if _, err := s.Client.DeadLetterMessage(ctx, req); err != nil {
+			l.Error().Err(err).Msg("Unable to dead-letter message")
+			return err
+		}
+
+		l.Info().Msg("Message dead-lettered successfully")
+
+	case exhausted:
+		return s.routeExhausted(ctx, message, handlerErr)
+
+	case notHandled:
+		l.Info().Err(handlerErr).Msg("Message wasn't handled, releasing it")
+
+		s.release(msg)
+
+	default:
+		l.Warn().Err(handlerErr).Msg("Failed to process message, releasing it for retry")
+
+		req := &pb.SQSNackMessageRequest{MessageID: msg.MessageID, ReceiveCount: msg.ReceiveCount}
+		if _, err := s.Client.NackMessage(ctx, req); err != nil {
+			l.Error().Err(err).Msg("Unable to release message")
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ErrNoDeadLetterQueue - returned by Run once a message over its maximum attempts can't be dead-lettered
+// since the sqsservice has no dead-letter queue; QuarantineFile keeps such messages instead
+var ErrNoDeadLetterQueue = errors.New("the sqsservice has no dead-letter queue for messages over their maximum attempts, set QUARANTINE_FILE")
+
+// routeExhausted - moves a message over the maximum attempts out of the queue along with its last error
+// appends it to the quarantine file and deletes it if there's one, otherwise dead-letters it
+func (s *SQSClient) routeExhausted(ctx context.Context, msg *Message, reason error) error {
+	l := s.Logger.With().Str("function", "routeExhausted").Str("messageID", msg.ID).Int64("receiveCount", msg.ReceiveCount).Logger()
+
+	if s.Quarantine != nil {
+		if err := s.Quarantine.Add(msg, reason); err != nil {
+			l.Error().Err(err).Msg("Unable to quarantine message")
+			return err
+		}
+
+		if _, err := s.Client.DeleteMessage(ctx, &pb.SQSDeleteMessageRequest{MessageID: msg.ID}); err != nil {
+			l.Error().Err(err).Msg("Unable to delete quarantined message")
+			return err
+		}
+
+		l.Warn().Err(reason).Msg("Message over its maximum attempts quarantined")
+
+		return nil
+	}
+
+	req := &pb.SQSDeadLetterMessageRequest{MessageID: msg.ID, MessageBody: msg.RawBody, Reason: reason.Error()}
+	if _, err := s.Client.DeadLetterMessage(ctx, req); err != nil {
+		l.Error().Err(err).Msg("Unable to dead-letter message over its maximum attempts")
+
+		if status.Code(err) == grpccodes.FailedPrecondition {
+			return fmt.Errorf("%w: %v", ErrNoDeadLetterQueue, err)
+		}
+
+		return err
+	}
+
+	l.Warn().Err(reason).Msg("Message over its maximum attempts dead-lettered")
+
+	return nil
+}
+
+// dedupKey - returns the key the message is deduplicated by; empty if deduplication is disabled
+// the key path selects in the unwrapped payload; the id of the outermost envelope comes before the sqs message id
+// since e.g. sns keeps it across duplicate deliveries of a notification
+func (s *SQSClient) dedupKey(msg *Message) string {
+	if s.DedupStore == nil {
+		return ""
+	}
+
+	if s.DedupKeyPath != nil {
+		if key, ok := s.DedupKeyPath.Lookup(msg.Body); ok {
+			return "key:" + key
+		}
+	}
+
+	if len(msg.Envelopes) > 0 && msg.Envelopes[0].ID != "" {
+		return msg.Envelopes[0].Type + ":" + msg.Envelopes[0].ID
+	}
+
+	if msg.MessageID == "" {
+		return ""
+	}
+
+	return "id:" + msg.MessageID
+}
+
+// deleteDuplicate - deletes a message whose key was already processed without handling it
+func (s *SQSClient) deleteDuplicate(ctx context.Context, msg *pb.SQSResponseMessage, key string) error {
+	l := s.Logger.With().Str("function", "deleteDuplicate").Str("messageID", msg.MessageID).Logger()
+
+	if _, err := s.Client.DeleteMessage(ctx, &pb.SQSDeleteMessageRequest{MessageID: msg.MessageID}); err != nil {
+		l.Error().Err(err).Msg("Unable to delete duplicate message")
+		return err
+	}
+
+	messagesDuplicate.Inc()
+
+	l.Info().Str("key", key).Msg("Duplicate message deleted without handling it")
+
+	return nil
+}
+
+// release - makes an unfinished message visible again so it's redelivered right away
+// its delay is set to 0 so the sqsservice's retry policy doesn't back it off like a failed one
+func (s *SQSClient) release(msg *pb.SQSResponseMessage) {
+	l := s.Logger.With().Str("function", "release").Str("messageID", msg.MessageID).Logger()
+
+	req := &pb.SQSNackMessageRequest{MessageID: msg.MessageID, DelaySeconds: proto.Int64(0), ReceiveCount: msg.ReceiveCount}
+	if _, err := s.Client.NackMessage(context.Background(), req); err != nil {
+		l.Error().Err(err).Msg("Unable to release unfinished message")
+		return
+	}
+
+	l.Info().Msg("Released unfinished message")
 }
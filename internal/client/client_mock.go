@@ -0,0 +1,105 @@
+package client
+
+// package is used for unit test
+// mocks the sqsservice's grpc client for testing purposes
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	pb "github.com/alvinlucillo/sqs-processor/protogen/sqs"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// default values
+const (
+	MockMessageID    = "message-1"
+	MockMessageBody  = "message-body"
+	MockErrMessageID = "error-id"
+	// id of the messages the mocked sqsservice has no dead-letter queue for
+	MockNoDeadLetterMessageID = "no-dead-letter-id"
+
+	ErrMessageFailedDelete     = "failed deleting message"
+	ErrMessageFailedNack       = "failed releasing message"
+	ErrMessageFailedDeadLetter = "failed dead-lettering message"
+)
+
+type SQSServiceClientMock struct {
+	pb.SQSServiceClient
+
+	mu sync.Mutex
+	// messages returned by every ReceiveMessage call
+	Messages []*pb.SQSResponseMessage
+	// number of messages asked for by each ReceiveMessage call
+	Requested []int64
+	// ids of the messages settled through each call
+	Deleted      []string
+	Nacked       []string
+	DeadLettered []string
+	// delays the nacked messages asked for, in the same order; nil leaves it to the retry policy
+	NackDelays []*int64
+	// reasons of the dead-lettered messages, in the same order
+	DeadLetterReasons []string
+}
+
+// ReceiveMessage -- mocks sqsservice ReceiveMessage
+func (c *SQSServiceClientMock) ReceiveMessage(ctx context.Context, in *pb.SQSReceiveMessageRequest, opts ...grpc.CallOption) (*pb.SQSReceiveMessageResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.Requested = append(c.Requested, in.MaximumNumberOfMessages)
+
+	return &pb.SQSReceiveMessageResponse{Messages: c.Messages}, nil
+}
+
+// DeleteMessage -- mocks sqsservice DeleteMessage
+func (c *SQSServiceClientMock) DeleteMessage(ctx context.Context, in *pb.SQSDeleteMessageRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	if in.MessageID == MockErrMessageID {
+		return nil, errors.New(ErrMessageFailedDelete)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.Deleted = append(c.Deleted, in.MessageID)
+
+	return &emptypb.Empty{}, nil
+}
+
+// NackMessage -- mocks sqsservice NackMessage
+func (c *SQSServiceClientMock) NackMessage(ctx context.Context, in *pb.SQSNackMessageRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	if in.MessageID == MockErrMessageID {
+		return nil, errors.New(ErrMessageFailedNack)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.Nacked = append(c.Nacked, in.MessageID)
+	c.NackDelays = append(c.NackDelays, in.DelaySeconds)
+
+	return &emptypb.Empty{}, nil
+}
+
+// DeadLetterMessage -- mocks sqsservice DeadLetterMessage
+func (c *SQSServiceClientMock) DeadLetterMessage(ctx context.Context, in *pb.SQSDeadLetterMessageRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	if in.MessageID == MockErrMessageID {
+		return nil, errors.New(ErrMessageFailedDeadLetter)
+	}
+
+	if in.MessageID == MockNoDeadLetterMessageID {
+		return nil, status.Error(codes.FailedPrecondition, "no dead-letter queue configured")
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.DeadLettered = append(c.DeadLettered, in.MessageID)
+	c.DeadLetterReasons = append(c.DeadLetterReasons, in.Reason)
+
+	return &emptypb.Empty{}, nil
+}
@@ -0,0 +1,440 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	pb "github.com/alvinlucillo/sqs-processor/protogen/sqs"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestProcess(t *testing.T) {
+	testCases := map[string]struct {
+		messageId    string
+		handlerErr   error
+		err          error
+		deleted      int
+		nacked       int
+		deadLettered int
+	}{
+		"handled message is deleted": {
+			messageId: MockMessageID,
+			deleted:   1,
+		},
+		"failed message is released": {
+			messageId:  MockMessageID,
+			handlerErr: errors.New("transient"),
+			nacked:     1,
+		},
+		"permanently failed message is dead-lettered": {
+			messageId:    MockMessageID,
+			handlerErr:   Permanent(errors.New("malformed")),
+			deadLettered: 1,
+		},
+		"failed delete": {
+			messageId: MockErrMessageID,
+			err:       errors.New(ErrMessageFailedDelete),
+		},
+		"failed release": {
+			messageId:  MockErrMessageID,
+			handlerErr: errors.New("transient"),
+			err:        errors.New(ErrMessageFailedNack),
+		},
+	}
+
+	for _, tc := range testCases {
+		mock := &SQSServiceClientMock{}
+		handlerErr := tc.handlerErr
+		sqsClient := &SQSClient{Client: mock, Handler: HandlerFunc(func(ctx context.Context, msg *Message) error {
+			return handlerErr
+		})}
+
+		err := sqsClient.process(context.Background(), &pb.SQSResponseMessage{MessageID: tc.messageId, MessageBody: MockMessageBody})
+
+		if tc.err == nil {
+			require.NoError(t, err)
+		} else {
+			require.Equal(t, tc.err, err)
+		}
+
+		require.Len(t, mock.Deleted, tc.deleted)
+		require.Len(t, mock.Nacked, tc.nacked)
+		require.Len(t, mock.DeadLettered, tc.deadLettered)
+	}
+}
+
+func TestNewClientCleanup(t *testing.T) {
+	// a free port for the metrics listener
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	require.NoError(t, listener.Close())
+
+	dir := t.TempDir()
+	env := defaultEnvironment(t)
+	env.MetricsAddress = listener.Addr().String()
+	env.DedupFile = filepath.Join(dir, "dedup.db")
+	env.TracingExporter = "file"
+	env.TracingFile = filepath.Join(dir, "spans.jsonl")
+	// fails last, once everything else is open
+	env.QuarantineFile = filepath.Join(dir, "missing", "quarantine.jsonl")
+
+	defer otel.SetTracerProvider(trace.NewNoopTracerProvider())
+
+	_, err = NewClient(zerolog.Nop(), env, nil)
+	require.Error(t, err)
+
+	// the metrics address and the dedup file's lock are free again
+	listener, err = net.Listen("tcp", env.MetricsAddress)
+	require.NoError(t, err)
+	require.NoError(t, listener.Close())
+
+	store, err := NewBoltDedupStore(env.DedupFile, time.Minute)
+	require.NoError(t, err)
+	require.NoError(t, store.Close())
+}
+
+func TestRunStopsOnContextDone(t *testing.T) {
+	mock := &SQSServiceClientMock{Messages: []*pb.SQSResponseMessage{{MessageID: MockMessageID, MessageBody: MockMessageBody}}}
+
+	conn, err := grpc.Dial("localhost:0", grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+
+	sqsClient := &SQSClient{Client: mock, Conn: conn, Handler: noopHandler, MaximumMessages: 1, Concurrency: 1, DrainTimeout: 1,
+		ErrorBudget: NewErrorBudget(time.Minute, 10, 0, 0)}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	require.NoError(t, sqsClient.Run(ctx))
+	require.NotEmpty(t, mock.Deleted)
+}
+
+func TestRunReceivesMaximumMessages(t *testing.T) {
+	mock := &SQSServiceClientMock{}
+
+	conn, err := grpc.Dial("localhost:0", grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+
+	// the defaults: a single worker and no queue depth
+	sqsClient := &SQSClient{Client: mock, Conn: conn, Handler: noopHandler, MaximumMessages: 5, Concurrency: 1, DrainTimeout: 1,
+		ErrorBudget: NewErrorBudget(time.Minute, 10, 0, 0)}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	require.NoError(t, sqsClient.Run(ctx))
+
+	mock.mu.Lock()
+	defer mock.mu.Unlock()
+
+	require.NotEmpty(t, mock.Requested)
+	require.Equal(t, int64(5), mock.Requested[0])
+}
+
+func TestRunReleasesCancelledMessages(t *testing.T) {
+	for _, quarantined := range []bool{false, true} {
+		mock := &SQSServiceClientMock{Messages: []*pb.SQSResponseMessage{{MessageID: MockMessageID, MessageBody: MockMessageBody, ReceiveCount: 3}}}
+
+		conn, err := grpc.Dial("localhost:0", grpc.WithTransportCredentials(insecure.NewCredentials()))
+		require.NoError(t, err)
+
+		handling := make(chan struct{})
+		var once sync.Once
+		// handles the message on its last attempt until the client stops
+		handler := HandlerFunc(func(ctx context.Context, msg *Message) error {
+			once.Do(func() { close(handling) })
+			<-ctx.Done()
+			return ctx.Err()
+		})
+
+		sqsClient := &SQSClient{Client: mock, Conn: conn, Handler: handler, MaximumMessages: 1, Concurrency: 1, DrainTimeout: 1,
+			MaxAttempts: 3, ErrorBudget: NewErrorBudget(time.Minute, 10, 0, 0)}
+
+		path := filepath.Join(t.TempDir(), "quarantine.jsonl")
+		if quarantined {
+			quarantine, err := NewQuarantineFile(path)
+			require.NoError(t, err)
+			sqsClient.Quarantine = quarantine
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error)
+		go func() {
+			done <- sqsClient.Run(ctx)
+		}()
+
+		<-handling
+		cancel()
+		require.NoError(t, <-done)
+
+		// released right away instead of moved out of the queue or backed off
+		require.Empty(t, mock.DeadLettered)
+		require.Equal(t, []*int64{proto.Int64(0)}, mock.NackDelays)
+
+		if quarantined {
+			content, err := os.ReadFile(path)
+			require.NoError(t, err)
+			require.Empty(t, content)
+		}
+	}
+}
+
+func TestRunStopsWithoutDeadLetterQueue(t *testing.T) {
+	mock := &SQSServiceClientMock{Messages: []*pb.SQSResponseMessage{{MessageID: MockNoDeadLetterMessageID, MessageBody: MockMessageBody, ReceiveCount: 4}}}
+
+	conn, err := grpc.Dial("localhost:0", grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+
+	sqsClient := &SQSClient{Client: mock, Conn: conn, Handler: noopHandler, MaximumMessages: 1, Concurrency: 1, DrainTimeout: 1,
+		MaxAttempts: 3, ErrorBudget: NewErrorBudget(time.Minute, 10, 0, 0)}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	// stops on its own instead of receiving the message again until ctx is done
+	require.ErrorIs(t, sqsClient.Run(ctx), ErrNoDeadLetterQueue)
+	require.NoError(t, ctx.Err())
+	require.Empty(t, mock.Deleted)
+}
+
+func TestProcessMetrics(t *testing.T) {
+	testCases := map[string]struct {
+		handlerErr   error
+		deleted      float64
+		retried      float64
+		deadLettered float64
+	}{
+		"handled message": {
+			deleted: 1,
+		},
+		"failed message": {
+			handlerErr: errors.New("transient"),
+			retried:    1,
+		},
+		"permanently failed message": {
+			handlerErr:   Permanent(errors.New("malformed")),
+			deadLettered: 1,
+		},
+	}
+
+	for name, tc := range testCases {
+		deleted := testutil.ToFloat64(messagesDeleted)
+		retried := testutil.ToFloat64(messagesFailed.WithLabelValues("retry"))
+		deadLettered := testutil.ToFloat64(messagesFailed.WithLabelValues("dead_letter"))
+
+		handlerErr := tc.handlerErr
+		sqsClient := &SQSClient{Client: &SQSServiceClientMock{}, Handler: HandlerFunc(func(ctx context.Context, msg *Message) error {
+			return handlerErr
+		})}
+
+		require.NoError(t, sqsClient.process(context.Background(), &pb.SQSResponseMessage{MessageID: MockMessageID, MessageBody: MockMessageBody}), name)
+
+		require.Equal(t, tc.deleted, testutil.ToFloat64(messagesDeleted)-deleted, name)
+		require.Equal(t, tc.retried, testutil.ToFloat64(messagesFailed.WithLabelValues("retry"))-retried, name)
+		require.Equal(t, tc.deadLettered, testutil.ToFloat64(messagesFailed.WithLabelValues("dead_letter"))-deadLettered, name)
+	}
+}
+
+func TestProcessTracing(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	defer otel.SetTracerProvider(trace.NewNoopTracerProvider())
+
+	traceParent := "00-5759e988bd862e3fe1be46a994272793-53995c3f42cd8ad8-01"
+
+	var handlerSpan trace.SpanContext
+	sqsClient := &SQSClient{Client: &SQSServiceClientMock{}, Handler: HandlerFunc(func(ctx context.Context, msg *Message) error {
+		handlerSpan = trace.SpanContextFromContext(ctx)
+		return errors.New("transient")
+	})}
+
+	msg := &pb.SQSResponseMessage{MessageID: MockMessageID, MessageBody: MockMessageBody, TraceContext: map[string]string{"traceparent": traceParent}}
+	require.NoError(t, sqsClient.process(context.Background(), msg))
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+
+	// the handler continues the trace the message was sent in
+	require.Equal(t, "process", spans[0].Name())
+	require.Equal(t, trace.SpanKindConsumer, spans[0].SpanKind())
+	require.Equal(t, "5759e988bd862e3fe1be46a994272793", spans[0].SpanContext().TraceID().String())
+	require.Equal(t, "53995c3f42cd8ad8", spans[0].Parent().SpanID().String())
+	require.Equal(t, spans[0].SpanContext(), handlerSpan)
+	require.Equal(t, codes.Error, spans[0].Status().Code)
+}
+
+func TestProcessDedup(t *testing.T) {
+	store, err := NewBoltDedupStore(filepath.Join(t.TempDir(), "dedup.db"), time.Hour)
+	require.NoError(t, err)
+	defer store.Close()
+
+	keyPath, err := ParseJSONPath("$.orderId")
+	require.NoError(t, err)
+
+	handled := 0
+	handlerErr := errors.New("transient")
+	mock := &SQSServiceClientMock{}
+	sqsClient := &SQSClient{Client: mock, DedupStore: store, DedupKeyPath: keyPath, Handler: HandlerFunc(func(ctx context.Context, msg *Message) error {
+		handled++
+		return handlerErr
+	})}
+
+	process := func(receiptHandle string, sqsMessageID string, body string) {
+		msg := &pb.SQSResponseMessage{MessageID: receiptHandle, SqsMessageID: sqsMessageID, MessageBody: body}
+		require.NoError(t, sqsClient.process(context.Background(), msg))
+	}
+
+	// failed messages aren't recorded so they're handled again
+	process("handle-1", "message-1", `{"orderId": "order-1"}`)
+	handlerErr = nil
+	process("handle-2", "message-1", `{"orderId": "order-1"}`)
+	require.Equal(t, 2, handled)
+
+	duplicates := testutil.ToFloat64(messagesDuplicate)
+
+	// the same key in another message is a duplicate
+	process("handle-3", "message-2", `{"orderId": "order-1"}`)
+	require.Equal(t, 2, handled)
+	require.Equal(t, []string{"handle-2", "handle-3"}, mock.Deleted)
+	require.Equal(t, 1.0, testutil.ToFloat64(messagesDuplicate)-duplicates)
+
+	// messages without the key are deduplicated by their sqs message id
+	process("handle-4", "message-3", `not json`)
+	process("handle-5", "message-3", `not json`)
+	require.Equal(t, 3, handled)
+	require.Equal(t, []string{"handle-2", "handle-3", "handle-4", "handle-5"}, mock.Deleted)
+}
+
+func TestProcessEnvelopes(t *testing.T) {
+	decoders, err := ParseEnvelopes("sns,s3", SNSConfig{})
+	require.NoError(t, err)
+
+	var handled *Message
+	mock := &SQSServiceClientMock{}
+	sqsClient := &SQSClient{Client: mock, Envelopes: decoders, Handler: HandlerFunc(func(ctx context.Context, msg *Message) error {
+		handled = msg
+		return nil
+	})}
+
+	body := snsNotificationBody(t, testS3Notification, nil, "1")
+	require.NoError(t, sqsClient.process(context.Background(), &pb.SQSResponseMessage{MessageID: "handle-1", MessageBody: body}))
+	require.Equal(t, body, handled.RawBody)
+	require.Len(t, handled.Envelopes, 2)
+	require.Equal(t, "uploads", handled.Envelopes[1].S3Records[0].Bucket)
+	require.Equal(t, []string{"handle-1"}, mock.Deleted)
+
+	// malformed envelopes are dead-lettered without calling the handler
+	handled = nil
+	malformed := `{"Records":[{"eventSource":"aws:s3","eventTime":"yesterday"}]}`
+	require.NoError(t, sqsClient.process(context.Background(), &pb.SQSResponseMessage{MessageID: "handle-2", MessageBody: malformed}))
+	require.Nil(t, handled)
+	require.Equal(t, []string{"handle-2"}, mock.DeadLettered)
+	require.Contains(t, mock.DeadLetterReasons[0], "unwrapping envelope")
+}
+
+func TestProcessMaxAttempts(t *testing.T) {
+	testCases := map[string]struct {
+		receiveCount int64
+		handlerErr   error
+		wantHandled  bool
+		// delays of the nacks; nil ones leave it to the retry policy
+		wantNackDelays []*int64
+		wantReason     string
+	}{
+		"attempts left": {
+			receiveCount: 2,
+			handlerErr:   errors.New("timeout"),
+			wantHandled:  true,
+			// failed messages are backed off
+			wantNackDelays: []*int64{nil},
+		},
+		"last attempt failed": {
+			receiveCount: 3,
+			handlerErr:   errors.New("timeout"),
+			wantHandled:  true,
+			wantReason:   "timeout",
+		},
+		"last attempt succeeded": {
+			receiveCount: 3,
+			wantHandled:  true,
+		},
+		"last attempt given up on before handling": {
+			receiveCount: 3,
+			handlerErr:   fmt.Errorf("%w: %w", errNotHandled, context.Canceled),
+			wantHandled:  true,
+			// unhandled messages are released right away
+			wantNackDelays: []*int64{proto.Int64(0)},
+		},
+		"over maximum attempts": {
+			receiveCount: 4,
+			wantReason:   "received 4 times, over the maximum of 3 attempts",
+		},
+	}
+
+	for name, tc := range testCases {
+		for _, quarantined := range []bool{false, true} {
+			mock := &SQSServiceClientMock{}
+			handled := false
+			handlerErr := tc.handlerErr
+			sqsClient := &SQSClient{Client: mock, MaxAttempts: 3, Handler: HandlerFunc(func(ctx context.Context, msg *Message) error {
+				handled = true
+				return handlerErr
+			})}
+
+			path := filepath.Join(t.TempDir(), "quarantine.jsonl")
+			if quarantined {
+				quarantine, err := NewQuarantineFile(path)
+				require.NoError(t, err)
+				sqsClient.Quarantine = quarantine
+			}
+
+			msg := &pb.SQSResponseMessage{MessageID: MockMessageID, SqsMessageID: "sqs-message-1", MessageBody: MockMessageBody, ReceiveCount: tc.receiveCount}
+			require.NoError(t, sqsClient.process(context.Background(), msg), name)
+
+			require.Equal(t, tc.wantHandled, handled, name)
+			require.Equal(t, tc.wantNackDelays, mock.NackDelays, name)
+
+			if tc.wantReason == "" {
+				continue
+			}
+
+			if !quarantined {
+				require.Equal(t, []string{tc.wantReason}, mock.DeadLetterReasons, name)
+				continue
+			}
+
+			// quarantined messages are deleted from the queue instead of dead-lettered
+			require.NoError(t, sqsClient.Quarantine.Close())
+			require.Empty(t, mock.DeadLettered, name)
+			require.Equal(t, []string{MockMessageID}, mock.Deleted, name)
+
+			content, err := os.ReadFile(path)
+			require.NoError(t, err)
+
+			var line QuarantinedMessage
+			require.NoError(t, json.Unmarshal(content, &line), name)
+			require.Equal(t, "sqs-message-1", line.MessageID, name)
+			require.Equal(t, MockMessageBody, line.Body, name)
+			require.Equal(t, tc.receiveCount, line.ReceiveCount, name)
+			require.Equal(t, tc.wantReason, line.Error, name)
+		}
+	}
+}
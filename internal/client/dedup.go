@@ -0,0 +1,142 @@
+package client
+
+import (
+	"encoding/binary"
+	"sync"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+const (
+	// bucket of the bbolt file holding the keys and when they expire
+	dedupBucket = "processed"
+	// how often expired keys are removed from the file
+	dedupPruneInterval = time.Minute
+	// how long opening a file locked by another process is waited for
+	dedupOpenTimeout = 5 * time.Second
+)
+
+// DedupStore - remembers the keys of processed messages so their duplicates can be skipped
+type DedupStore interface {
+	// Seen - checks if the key was recorded and hasn't expired yet
+	Seen(key string) (bool, error)
+	// Record - remembers the key for the store's ttl
+	Record(key string) error
+	Close() error
+}
+
+// BoltDedupStore - DedupStore kept in a bbolt file so it survives restarts
+// expired keys are removed every minute
+type BoltDedupStore struct {
+	DB  *bolt.DB
+	TTL time.Duration
+
+	done chan struct{}
+	wg   sync.WaitGroup
+}
+
+// NewBoltDedupStore - opens or creates the store's file at path, e.g. on a volume kept across restarts
+// only one process can open the file at a time
+func NewBoltDedupStore(path string, ttl time.Duration) (*BoltDedupStore, error) {
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: dedupOpenTimeout})
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists([]byte(dedupBucket))
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	store := &BoltDedupStore{DB: db, TTL: ttl, done: make(chan struct{})}
+
+	if err := store.Prune(); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	store.wg.Add(1)
+	go store.pruneEvery(dedupPruneInterval)
+
+	return store, nil
+}
+
+func (s *BoltDedupStore) Seen(key string) (bool, error) {
+	seen := false
+
+	err := s.DB.View(func(tx *bolt.Tx) error {
+		value := tx.Bucket([]byte(dedupBucket)).Get([]byte(key))
+		seen = len(value) == 8 && time.Now().UnixNano() < int64(binary.BigEndian.Uint64(value))
+		return nil
+	})
+
+	return seen, err
+}
+
+func (s *BoltDedupStore) Record(key string) error {
+	expiry := make([]byte, 8)
+	binary.BigEndian.PutUint64(expiry, uint64(time.Now().Add(s.TTL).UnixNano()))
+
+	return s.DB.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket([]byte(dedupBucket)).Put([]byte(key), expiry)
+	})
+}
+
+// Prune - removes the expired keys
+func (s *BoltDedupStore) Prune() error {
+	now := time.Now().UnixNano()
+
+	return s.DB.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(dedupBucket))
+
+		expired := make([][]byte, 0)
+		err := bucket.ForEach(func(key, value []byte) error {
+			if len(value) != 8 || int64(binary.BigEndian.Uint64(value)) <= now {
+				expired = append(expired, key)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		// deleted after iterating since the cursor can't be modified while walking the bucket
+		for _, key := range expired {
+			if err := bucket.Delete(key); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+func (s *BoltDedupStore) pruneEvery(interval time.Duration) {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-ticker.C:
+			// failures are retried on the next tick; Seen ignores expired keys meanwhile
+			_ = s.Prune()
+		}
+	}
+}
+
+// Close - stops removing expired keys and closes the file
+func (s *BoltDedupStore) Close() error {
+	close(s.done)
+	s.wg.Wait()
+
+	return s.DB.Close()
+}
@@ -0,0 +1,52 @@
+package client
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	bolt "go.etcd.io/bbolt"
+)
+
+func TestBoltDedupStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dedup.db")
+
+	store, err := NewBoltDedupStore(path, time.Hour)
+	require.NoError(t, err)
+
+	seen, err := store.Seen("id:1")
+	require.NoError(t, err)
+	require.False(t, seen)
+
+	require.NoError(t, store.Record("id:1"))
+
+	seen, err = store.Seen("id:1")
+	require.NoError(t, err)
+	require.True(t, seen)
+
+	require.NoError(t, store.Close())
+
+	// keys survive restarts
+	store, err = NewBoltDedupStore(path, 50*time.Millisecond)
+	require.NoError(t, err)
+	defer store.Close()
+
+	seen, err = store.Seen("id:1")
+	require.NoError(t, err)
+	require.True(t, seen)
+
+	// expired keys are no longer seen and are pruned
+	require.NoError(t, store.Record("id:2"))
+	time.Sleep(60 * time.Millisecond)
+
+	seen, err = store.Seen("id:2")
+	require.NoError(t, err)
+	require.False(t, seen)
+
+	require.NoError(t, store.Prune())
+	require.NoError(t, store.DB.View(func(tx *bolt.Tx) error {
+		require.Equal(t, 1, tx.Bucket([]byte(dedupBucket)).Stats().KeyN)
+		return nil
+	}))
+}
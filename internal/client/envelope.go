@@ -0,0 +1,457 @@
+package client
+
+import (
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"hash"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	// types of the envelopes unwrapped by the decoders of this package
+	EnvelopeSNS         = "sns"
+	EnvelopeEventBridge = "eventbridge"
+	EnvelopeS3          = "s3"
+
+	// type of the sns envelopes holding a message published to the topic
+	snsNotification = "Notification"
+	// event source of the records of s3 event notifications
+	s3EventSource = "aws:s3"
+)
+
+// Envelope - notification a message's payload was wrapped in, e.g. by sns, eventbridge or s3
+type Envelope struct {
+	// sns, eventbridge or s3
+	Type string
+	// id of the notification, e.g. the sns MessageId or the eventbridge event id; empty for raw sns deliveries
+	ID string
+	// what sent the notification: the sns topic arn, the eventbridge source or aws:s3
+	Source string
+	// sns subject, eventbridge detail-type or the event name of the first s3 record
+	Subject string
+	// when the notification was sent; zero for raw sns deliveries
+	Time time.Time
+	// other metadata, e.g. the sns message attributes or the eventbridge account and region
+	Attributes map[string]string
+	// objects the s3 event notification is about
+	S3Records []S3Record
+	// body wrapped in the envelope, as received
+	Body string
+}
+
+// S3Record - object event of an s3 event notification
+type S3Record struct {
+	// e.g. ObjectCreated:Put
+	EventName string
+	EventTime time.Time
+	Region    string
+	Bucket    string
+	// decoded object key, e.g. with spaces instead of +
+	Key       string
+	Size      int64
+	ETag      string
+	VersionID string
+}
+
+// EnvelopeDecoder - unwraps one kind of envelope
+type EnvelopeDecoder interface {
+	// Decode - returns the envelope of the message's body and the payload it wraps
+	// returns a nil envelope if the body isn't wrapped in this kind of envelope
+	Decode(msg *Message) (*Envelope, string, error)
+}
+
+// SNSConfig - settings of the sns decoder
+type SNSConfig struct {
+	// the queue's subscription delivers raw messages
+	Raw bool
+	// pem file of the sns signing certificate notifications are verified against; unverified if empty
+	CertFile string
+	// arns of the topics notifications are accepted from; any topic if empty
+	// required with CertFile since sns signs the notifications of every account's topics with the same certificate
+	TopicArns []string
+	// age over which verified notifications are rejected, so old ones can't be replayed; 0 accepts any age
+	MaxAge time.Duration
+}
+
+// ParseEnvelopes - returns the decoders of the comma-separated envelope types, e.g. sns,s3
+// they're returned in the order they're unwrapped, outermost first: sns, eventbridge then s3
+func ParseEnvelopes(types string, sns SNSConfig) ([]EnvelopeDecoder, error) {
+	enabled, err := envelopeTypes(types)
+	if err != nil {
+		return nil, err
+	}
+
+	decoders := make([]EnvelopeDecoder, 0, len(enabled))
+
+	if enabled[EnvelopeSNS] {
+		decoder, err := NewSNSDecoder(sns)
+		if err != nil {
+			return nil, err
+		}
+		decoders = append(decoders, decoder)
+	}
+
+	if enabled[EnvelopeEventBridge] {
+		decoders = append(decoders, EventBridgeDecoder{})
+	}
+
+	if enabled[EnvelopeS3] {
+		decoders = append(decoders, S3Decoder{})
+	}
+
+	return decoders, nil
+}
+
+// envelopeTypes - returns the set of comma-separated envelope types; internally used
+func envelopeTypes(types string) (map[string]bool, error) {
+	enabled := make(map[string]bool)
+
+	for _, t := range strings.Split(types, ",") {
+		t = strings.ToLower(strings.TrimSpace(t))
+		switch t {
+		case "":
+			continue
+		case EnvelopeSNS, EnvelopeEventBridge, EnvelopeS3:
+			enabled[t] = true
+		default:
+			return nil, fmt.Errorf("unknown envelope: %v", t)
+		}
+	}
+
+	return enabled, nil
+}
+
+// unwrap - replaces the body of msg with the payload of its envelopes, which are added to it outermost first
+// decoders are tried in order and each unwraps at most one envelope, e.g. an s3 notification delivered through sns
+// returns a permanent error if an envelope is malformed or its signature invalid
+func unwrap(msg *Message, decoders []EnvelopeDecoder) error {
+	remaining := decoders
+
+	for len(remaining) > 0 {
+		decoded := false
+
+		for i, decoder := range remaining {
+			envelope, payload, err := decoder.Decode(msg)
+			if err != nil {
+				return Permanent(fmt.Errorf("unwrapping envelope: %w", err))
+			}
+			if envelope == nil {
+				continue
+			}
+
+			envelopesUnwrapped.WithLabelValues(envelope.Type).Inc()
+
+			msg.Envelopes = append(msg.Envelopes, envelope)
+			msg.Body = payload
+			remaining = append(remaining[:i:i], remaining[i+1:]...)
+			decoded = true
+			break
+		}
+
+		if !decoded {
+			return nil
+		}
+	}
+
+	return nil
+}
+
+// snsEnvelope - json body of a message delivered by an sns subscription without raw delivery; internally used
+type snsEnvelope struct {
+	Type             string `json:"Type"`
+	MessageID        string `json:"MessageId"`
+	TopicArn         string `json:"TopicArn"`
+	Subject          string `json:"Subject"`
+	Message          string `json:"Message"`
+	Timestamp        string `json:"Timestamp"`
+	SignatureVersion string `json:"SignatureVersion"`
+	Signature        string `json:"Signature"`
+	SigningCertURL   string `json:"SigningCertURL"`
+	UnsubscribeURL   string `json:"UnsubscribeURL"`
+
+	MessageAttributes map[string]struct {
+		Type  string `json:"Type"`
+		Value string `json:"Value"`
+	} `json:"MessageAttributes"`
+}
+
+// stringToSign - returns what sns signed for the notification
+// https://docs.aws.amazon.com/sns/latest/dg/sns-verify-signature-of-message.html
+func (e *snsEnvelope) stringToSign() string {
+	var b strings.Builder
+
+	field := func(name string, value string) {
+		b.WriteString(name + "\n" + value + "\n")
+	}
+
+	field("Message", e.Message)
+	field("MessageId", e.MessageID)
+	if e.Subject != "" {
+		field("Subject", e.Subject)
+	}
+	field("Timestamp", e.Timestamp)
+	field("TopicArn", e.TopicArn)
+	field("Type", e.Type)
+
+	return b.String()
+}
+
+// SNSDecoder - unwraps the notifications of sns topics the queue is subscribed to
+type SNSDecoder struct {
+	// treats bodies that aren't sns notifications as raw deliveries
+	// whose payload is the body and whose metadata are the message attributes
+	Raw bool
+	// verifies the signature of notifications against its public key when set
+	// messages that aren't notifications are rejected then, so Raw doesn't apply
+	Certificate *x509.Certificate
+	// topic arn -> whether its notifications are accepted; any topic if empty
+	TopicArns map[string]bool
+	// age over which verified notifications are rejected; 0 accepts any age
+	MaxAge time.Duration
+}
+
+// NewSNSDecoder - creates the decoder; notifications are verified against the pem certificate of config.CertFile if set
+// e.g. the sns signing certificate of the region, downloaded beforehand since it isn't fetched while running
+func NewSNSDecoder(config SNSConfig) (*SNSDecoder, error) {
+	decoder := &SNSDecoder{Raw: config.Raw, MaxAge: config.MaxAge}
+
+	if len(config.TopicArns) > 0 {
+		decoder.TopicArns = make(map[string]bool, len(config.TopicArns))
+		for _, arn := range config.TopicArns {
+			decoder.TopicArns[arn] = true
+		}
+	}
+
+	certFile := config.CertFile
+	if certFile == "" {
+		return decoder, nil
+	}
+
+	if decoder.TopicArns == nil {
+		return nil, errors.New("sns signature verification requires the arns of the accepted topics")
+	}
+
+	data, err := os.ReadFile(certFile)
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("no pem certificate in %v", certFile)
+	}
+
+	certificate, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, ok := certificate.PublicKey.(*rsa.PublicKey); !ok {
+		return nil, fmt.Errorf("sns certificate %v doesn't have an rsa key", certFile)
+	}
+
+	decoder.Certificate = certificate
+
+	return decoder, nil
+}
+
+func (d *SNSDecoder) Decode(msg *Message) (*Envelope, string, error) {
+	var sns snsEnvelope
+
+	if json.Unmarshal([]byte(msg.Body), &sns) != nil || sns.Type != snsNotification || sns.TopicArn == "" || sns.MessageID == "" {
+		// otherwise messages sent straight to the queue would bypass the verification
+		if d.Certificate != nil {
+			return nil, "", errors.New("message isn't a signed sns notification")
+		}
+
+		if !d.Raw {
+			return nil, "", nil
+		}
+
+		attributes := make(map[string]string, len(msg.Attributes))
+		for name, value := range msg.Attributes {
+			attributes[name] = value
+		}
+
+		return &Envelope{Type: EnvelopeSNS, Attributes: attributes, Body: msg.Body}, msg.Body, nil
+	}
+
+	// any account's topic can have a notification signed, so the topic is checked along with the signature
+	if d.TopicArns != nil && !d.TopicArns[sns.TopicArn] {
+		return nil, "", fmt.Errorf("sns notification %v from unexpected topic %v", sns.MessageID, sns.TopicArn)
+	}
+
+	if d.Certificate != nil {
+		if err := d.verify(&sns); err != nil {
+			return nil, "", fmt.Errorf("invalid sns signature of notification %v: %w", sns.MessageID, err)
+		}
+	}
+
+	sent, err := time.Parse(time.RFC3339, sns.Timestamp)
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid sns timestamp of notification %v: %w", sns.MessageID, err)
+	}
+
+	// signed notifications stay valid, so old ones could otherwise be replayed
+	if d.Certificate != nil && d.MaxAge > 0 && time.Since(sent) > d.MaxAge {
+		return nil, "", fmt.Errorf("sns notification %v sent at %v is older than %v", sns.MessageID, sns.Timestamp, d.MaxAge)
+	}
+
+	// binary attributes are left out like the sqs ones
+	attributes := make(map[string]string, len(sns.MessageAttributes))
+	for name, attribute := range sns.MessageAttributes {
+		if attribute.Type != "Binary" {
+			attributes[name] = attribute.Value
+		}
+	}
+
+	return &Envelope{Type: EnvelopeSNS, ID: sns.MessageID, Source: sns.TopicArn, Subject: sns.Subject, Time: sent,
+		Attributes: attributes, Body: msg.Body}, sns.Message, nil
+}
+
+// verify - checks the notification was signed with the key of the decoder's certificate
+func (d *SNSDecoder) verify(sns *snsEnvelope) error {
+	var (
+		hashFunc crypto.Hash
+		h        hash.Hash
+	)
+
+	switch sns.SignatureVersion {
+	case "1":
+		hashFunc, h = crypto.SHA1, sha1.New()
+	case "2":
+		hashFunc, h = crypto.SHA256, sha256.New()
+	default:
+		return fmt.Errorf("unsupported signature version %q", sns.SignatureVersion)
+	}
+
+	signature, err := base64.StdEncoding.DecodeString(sns.Signature)
+	if err != nil {
+		return err
+	}
+
+	if now := time.Now(); now.Before(d.Certificate.NotBefore) || now.After(d.Certificate.NotAfter) {
+		return errors.New("certificate isn't valid at this time")
+	}
+
+	h.Write([]byte(sns.stringToSign()))
+
+	return rsa.VerifyPKCS1v15(d.Certificate.PublicKey.(*rsa.PublicKey), hashFunc, h.Sum(nil), signature)
+}
+
+// eventBridgeEnvelope - json body of an event delivered by an eventbridge rule; internally used
+type eventBridgeEnvelope struct {
+	Version    string          `json:"version"`
+	ID         string          `json:"id"`
+	DetailType string          `json:"detail-type"`
+	Source     string          `json:"source"`
+	Account    string          `json:"account"`
+	Time       string          `json:"time"`
+	Region     string          `json:"region"`
+	Resources  []string        `json:"resources"`
+	Detail     json.RawMessage `json:"detail"`
+}
+
+// EventBridgeDecoder - unwraps the events of eventbridge rules targeting the queue
+// the payload is the event's detail as json
+type EventBridgeDecoder struct{}
+
+func (EventBridgeDecoder) Decode(msg *Message) (*Envelope, string, error) {
+	var event eventBridgeEnvelope
+
+	if json.Unmarshal([]byte(msg.Body), &event) != nil || event.ID == "" || event.DetailType == "" || event.Source == "" || len(event.Detail) == 0 {
+		return nil, "", nil
+	}
+
+	sent, err := time.Parse(time.RFC3339, event.Time)
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid eventbridge time of event %v: %w", event.ID, err)
+	}
+
+	attributes := map[string]string{"version": event.Version, "account": event.Account, "region": event.Region}
+	if len(event.Resources) > 0 {
+		attributes["resources"] = strings.Join(event.Resources, ",")
+	}
+
+	return &Envelope{Type: EnvelopeEventBridge, ID: event.ID, Source: event.Source, Subject: event.DetailType, Time: sent,
+		Attributes: attributes, Body: msg.Body}, string(event.Detail), nil
+}
+
+// s3EventRecord - record of an s3 event notification; internally used
+type s3EventRecord struct {
+	EventSource string `json:"eventSource"`
+	EventName   string `json:"eventName"`
+	EventTime   string `json:"eventTime"`
+	AWSRegion   string `json:"awsRegion"`
+	S3          struct {
+		Bucket struct {
+			Name string `json:"name"`
+		} `json:"bucket"`
+		Object struct {
+			Key       string `json:"key"`
+			Size      int64  `json:"size"`
+			ETag      string `json:"eTag"`
+			VersionID string `json:"versionId"`
+		} `json:"object"`
+	} `json:"s3"`
+}
+
+// S3Decoder - unwraps the event notifications of s3 buckets
+// the payload is the json array of the notification's records, which are also parsed into the envelope's S3Records
+type S3Decoder struct{}
+
+func (S3Decoder) Decode(msg *Message) (*Envelope, string, error) {
+	var notification struct {
+		Records json.RawMessage `json:"Records"`
+	}
+
+	if json.Unmarshal([]byte(msg.Body), &notification) != nil || len(notification.Records) == 0 {
+		return nil, "", nil
+	}
+
+	var records []s3EventRecord
+	if json.Unmarshal(notification.Records, &records) != nil || len(records) == 0 {
+		return nil, "", nil
+	}
+
+	// other services, e.g. dynamodb streams, also send records
+	for _, record := range records {
+		if record.EventSource != s3EventSource {
+			return nil, "", nil
+		}
+	}
+
+	envelope := &Envelope{Type: EnvelopeS3, Source: s3EventSource, Subject: records[0].EventName, Body: msg.Body,
+		S3Records: make([]S3Record, 0, len(records))}
+
+	for _, record := range records {
+		eventTime, err := time.Parse(time.RFC3339, record.EventTime)
+		if err != nil {
+			return nil, "", fmt.Errorf("invalid s3 event time: %w", err)
+		}
+
+		// keys are url encoded, e.g. spaces are sent as +
+		key, err := url.QueryUnescape(record.S3.Object.Key)
+		if err != nil {
+			return nil, "", fmt.Errorf("invalid s3 object key %v: %w", record.S3.Object.Key, err)
+		}
+
+		envelope.S3Records = append(envelope.S3Records, S3Record{EventName: record.EventName, EventTime: eventTime, Region: record.AWSRegion,
+			Bucket: record.S3.Bucket.Name, Key: key, Size: record.S3.Object.Size, ETag: record.S3.Object.ETag, VersionID: record.S3.Object.VersionID})
+	}
+
+	envelope.Time = envelope.S3Records[0].EventTime
+
+	return envelope, string(notification.Records), nil
+}
@@ -0,0 +1,250 @@
+package client
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testTopicArn = "arn:aws:sns:us-east-1:123456789012:orders"
+
+	testS3Notification = `{"Records":[{"eventVersion":"2.1","eventSource":"aws:s3","awsRegion":"us-east-1",` +
+		`"eventTime":"2024-01-02T03:04:05.000Z","eventName":"ObjectCreated:Put",` +
+		`"s3":{"bucket":{"name":"uploads"},"object":{"key":"reports/jan+2024.csv","size":1024,"eTag":"abc123"}}}]}`
+
+	testEventBridgeEvent = `{"version":"0","id":"event-1","detail-type":"Order Created","source":"com.example.orders",` +
+		`"account":"123456789012","time":"2024-01-02T03:04:05Z","region":"us-east-1","resources":[],"detail":{"orderId":"order-1"}}`
+)
+
+// snsNotificationBody - returns the json body sns delivers for message, signed with key if set
+func snsNotificationBody(t *testing.T, message string, key *rsa.PrivateKey, signatureVersion string) string {
+	t.Helper()
+
+	return signedNotification(t, snsEnvelope{Type: snsNotification, MessageID: "notification-1", TopicArn: testTopicArn, Subject: "order",
+		Message: message, Timestamp: "2024-01-02T03:04:05.000Z", SignatureVersion: signatureVersion}, key)
+}
+
+// signedNotification - returns the json body of envelope, signed with key if set
+func signedNotification(t *testing.T, envelope snsEnvelope, key *rsa.PrivateKey) string {
+	t.Helper()
+
+	signatureVersion := envelope.SignatureVersion
+
+	if key != nil {
+		hashFunc, digest := crypto.SHA1, sha1.Sum([]byte(envelope.stringToSign()))
+		sum := digest[:]
+		if signatureVersion == "2" {
+			digest256 := sha256.Sum256([]byte(envelope.stringToSign()))
+			hashFunc, sum = crypto.SHA256, digest256[:]
+		}
+
+		signature, err := rsa.SignPKCS1v15(rand.Reader, key, hashFunc, sum)
+		require.NoError(t, err)
+		envelope.Signature = base64.StdEncoding.EncodeToString(signature)
+	}
+
+	body, err := json.Marshal(envelope)
+	require.NoError(t, err)
+
+	return string(body)
+}
+
+// writeCertificate - writes a self-signed pem certificate of key to a temporary file and returns its path
+func writeCertificate(t *testing.T, key *rsa.PrivateKey) string {
+	t.Helper()
+
+	template := &x509.Certificate{SerialNumber: big.NewInt(1), Subject: pkix.Name{CommonName: "sns.us-east-1.amazonaws.com"},
+		NotBefore: time.Now().Add(-time.Hour), NotAfter: time.Now().Add(time.Hour)}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "sns.pem")
+	require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+
+	return path
+}
+
+func TestUnwrap(t *testing.T) {
+	decoders, err := ParseEnvelopes("sns,eventbridge,s3", SNSConfig{})
+	require.NoError(t, err)
+
+	eventTime := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	testCases := map[string]struct {
+		msg           *Message
+		raw           bool
+		wantBody      string
+		wantEnvelopes []string
+		wantErr       bool
+	}{
+		"sns notification": {
+			msg:           &Message{Body: snsNotificationBody(t, `{"orderId":"order-1"}`, nil, "1")},
+			wantBody:      `{"orderId":"order-1"}`,
+			wantEnvelopes: []string{EnvelopeSNS},
+		},
+		"s3 event through sns": {
+			msg:           &Message{Body: snsNotificationBody(t, testS3Notification, nil, "1")},
+			wantBody:      testS3Notification[len(`{"Records":`) : len(testS3Notification)-1],
+			wantEnvelopes: []string{EnvelopeSNS, EnvelopeS3},
+		},
+		"eventbridge event": {
+			msg:           &Message{Body: testEventBridgeEvent},
+			wantBody:      `{"orderId":"order-1"}`,
+			wantEnvelopes: []string{EnvelopeEventBridge},
+		},
+		"raw sns delivery": {
+			msg:           &Message{Body: testEventBridgeEvent, Attributes: map[string]string{"type": "order"}},
+			raw:           true,
+			wantBody:      `{"orderId":"order-1"}`,
+			wantEnvelopes: []string{EnvelopeSNS, EnvelopeEventBridge},
+		},
+		"plain message": {
+			msg:      &Message{Body: `{"orderId":"order-1"}`},
+			wantBody: `{"orderId":"order-1"}`,
+		},
+		"malformed eventbridge time": {
+			msg:     &Message{Body: `{"id":"event-1","detail-type":"Order Created","source":"orders","time":"yesterday","detail":{}}`},
+			wantErr: true,
+		},
+	}
+
+	for name, tc := range testCases {
+		decoders := decoders
+		if tc.raw {
+			decoders = append([]EnvelopeDecoder{&SNSDecoder{Raw: true}}, decoders[1:]...)
+		}
+		tc.msg.RawBody = tc.msg.Body
+
+		err := unwrap(tc.msg, decoders)
+		if tc.wantErr {
+			require.True(t, IsPermanent(err), name)
+			continue
+		}
+		require.NoError(t, err, name)
+
+		require.Equal(t, tc.wantBody, tc.msg.Body, name)
+		require.Len(t, tc.msg.Envelopes, len(tc.wantEnvelopes), name)
+		for i, envelope := range tc.msg.Envelopes {
+			require.Equal(t, tc.wantEnvelopes[i], envelope.Type, name)
+		}
+		if len(tc.msg.Envelopes) > 0 {
+			require.Equal(t, tc.msg.RawBody, tc.msg.Envelopes[0].Body, name)
+		}
+	}
+
+	// metadata of every kind of envelope
+	msg := &Message{Body: snsNotificationBody(t, testS3Notification, nil, "1")}
+	require.NoError(t, unwrap(msg, decoders))
+
+	sns := msg.Envelopes[0]
+	require.Equal(t, "notification-1", sns.ID)
+	require.Equal(t, testTopicArn, sns.Source)
+	require.Equal(t, "order", sns.Subject)
+	require.Equal(t, eventTime, sns.Time)
+
+	s3 := msg.Envelopes[1]
+	require.Equal(t, "ObjectCreated:Put", s3.Subject)
+	require.Equal(t, []S3Record{{EventName: "ObjectCreated:Put", EventTime: eventTime, Region: "us-east-1", Bucket: "uploads",
+		Key: "reports/jan 2024.csv", Size: 1024, ETag: "abc123"}}, s3.S3Records)
+
+	msg = &Message{Body: testEventBridgeEvent}
+	require.NoError(t, unwrap(msg, decoders))
+	require.Equal(t, &Envelope{Type: EnvelopeEventBridge, ID: "event-1", Source: "com.example.orders", Subject: "Order Created", Time: eventTime,
+		Attributes: map[string]string{"version": "0", "account": "123456789012", "region": "us-east-1"}, Body: testEventBridgeEvent}, msg.Envelopes[0])
+
+	_, err = ParseEnvelopes("sns,kinesis", SNSConfig{})
+	require.Error(t, err)
+}
+
+func TestSNSSignature(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	certFile := writeCertificate(t, key)
+	decoder, err := NewSNSDecoder(SNSConfig{CertFile: certFile, TopicArns: []string{testTopicArn}, MaxAge: time.Hour})
+	require.NoError(t, err)
+
+	notification := func(version string, signer *rsa.PrivateKey, change func(e *snsEnvelope)) string {
+		envelope := snsEnvelope{Type: snsNotification, MessageID: "notification-1", TopicArn: testTopicArn, Message: `{"orderId":"order-1"}`,
+			Timestamp: time.Now().UTC().Format(time.RFC3339), SignatureVersion: version}
+		if change != nil {
+			change(&envelope)
+		}
+		return signedNotification(t, envelope, signer)
+	}
+
+	tampered := notification("1", key, nil)
+	var envelope snsEnvelope
+	require.NoError(t, json.Unmarshal([]byte(tampered), &envelope))
+	envelope.Message = `{"orderId":"order-2"}`
+	tamperedBody, err := json.Marshal(envelope)
+	require.NoError(t, err)
+
+	testCases := map[string]struct {
+		body    string
+		wantErr bool
+	}{
+		"signature version 1": {
+			body: notification("1", key, nil),
+		},
+		"signature version 2": {
+			body: notification("2", key, nil),
+		},
+		"signed by another key": {
+			body:    notification("2", otherKey, nil),
+			wantErr: true,
+		},
+		"tampered message": {
+			body:    string(tamperedBody),
+			wantErr: true,
+		},
+		"unsigned message": {
+			body:    `{"orderId":"order-1"}`,
+			wantErr: true,
+		},
+		"signed by another account's topic": {
+			body:    notification("2", key, func(e *snsEnvelope) { e.TopicArn = "arn:aws:sns:us-east-1:999999999999:orders" }),
+			wantErr: true,
+		},
+		"replayed notification": {
+			body:    notification("2", key, func(e *snsEnvelope) { e.Timestamp = time.Now().Add(-2 * time.Hour).UTC().Format(time.RFC3339) }),
+			wantErr: true,
+		},
+	}
+
+	for name, tc := range testCases {
+		envelope, payload, err := decoder.Decode(&Message{Body: tc.body})
+		if tc.wantErr {
+			require.Error(t, err, name)
+			continue
+		}
+		require.NoError(t, err, name)
+		require.NotNil(t, envelope, name)
+		require.Equal(t, `{"orderId":"order-1"}`, payload, name)
+	}
+
+	_, err = NewSNSDecoder(SNSConfig{CertFile: filepath.Join(t.TempDir(), "missing.pem"), TopicArns: []string{testTopicArn}})
+	require.Error(t, err)
+
+	// the certificate alone accepts any account's topic
+	_, err = NewSNSDecoder(SNSConfig{CertFile: certFile})
+	require.Error(t, err)
+}
@@ -0,0 +1,177 @@
+package client
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// actions taken once the error budget is exceeded
+const (
+	// stops the client with an error
+	ErrorActionExit = "exit"
+	// stops receiving for a while then starts over with a fresh budget
+	ErrorActionPause = "pause"
+	// keeps receiving one message at a time at the maximum backoff until the budget recovers
+	ErrorActionDegrade = "degrade"
+)
+
+type outcome struct {
+	at     time.Time
+	failed bool
+}
+
+// ErrorBudget - tracks the errors within a sliding time window
+// exceeded once the window holds more than MaxErrors errors
+// or once the ratio of errors to outcomes is over MaxErrorRatio
+type ErrorBudget struct {
+	Window time.Duration
+	// 0 disables the error count limit
+	MaxErrors int
+	// 0 disables the error ratio limit
+	MaxErrorRatio float64
+	// number of outcomes the window needs before the ratio is checked
+	MinSamples int
+
+	mu       sync.Mutex
+	outcomes []outcome
+}
+
+// NewErrorBudget - creates new ErrorBudget
+func NewErrorBudget(window time.Duration, maxErrors int, maxErrorRatio float64, minSamples int) *ErrorBudget {
+	return &ErrorBudget{Window: window, MaxErrors: maxErrors, MaxErrorRatio: maxErrorRatio, MinSamples: minSamples}
+}
+
+// RecordSuccess - records a successful call or message
+func (b *ErrorBudget) RecordSuccess() {
+	b.record(false)
+}
+
+// RecordError - records a failed call or message
+func (b *ErrorBudget) RecordError() {
+	b.record(true)
+}
+
+func (b *ErrorBudget) record(failed bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	b.prune(now)
+	b.outcomes = append(b.outcomes, outcome{at: now, failed: failed})
+}
+
+// prune - drops the outcomes that slid out of the window; caller holds the lock
+func (b *ErrorBudget) prune(now time.Time) {
+	cutoff := now.Add(-b.Window)
+
+	i := 0
+	for i < len(b.outcomes) && !b.outcomes[i].at.After(cutoff) {
+		i++
+	}
+
+	b.outcomes = b.outcomes[i:]
+}
+
+// SetLimits - changes the window and limits of the budget, keeping the recorded outcomes
+func (b *ErrorBudget) SetLimits(window time.Duration, maxErrors int, maxErrorRatio float64, minSamples int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.Window, b.MaxErrors, b.MaxErrorRatio, b.MinSamples = window, maxErrors, maxErrorRatio, minSamples
+}
+
+// counts - returns the number of errors and outcomes in the window; caller holds the lock
+func (b *ErrorBudget) counts() (int, int) {
+	b.prune(time.Now())
+
+	errors := 0
+	for _, o := range b.outcomes {
+		if o.failed {
+			errors++
+		}
+	}
+
+	return errors, len(b.outcomes)
+}
+
+// Exceeded - checks if the errors within the window are over the budget
+func (b *ErrorBudget) Exceeded() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	errors, total := b.counts()
+
+	if b.MaxErrors > 0 && errors > b.MaxErrors {
+		return true
+	}
+
+	if b.MaxErrorRatio > 0 && total > 0 && total >= b.MinSamples {
+		return float64(errors)/float64(total) > b.MaxErrorRatio
+	}
+
+	return false
+}
+
+// Reset - forgets every recorded outcome
+func (b *ErrorBudget) Reset() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.outcomes = nil
+}
+
+func (b *ErrorBudget) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	errors, total := b.counts()
+
+	return fmt.Sprintf("%v error(s) out of %v in the last %v", errors, total, b.Window)
+}
+
+// shortest wait between failing calls, whatever the base and max are set to
+const minBackoff = time.Second
+
+// backoff - exponentially growing wait between failing calls
+type backoff struct {
+	Base time.Duration
+	Max  time.Duration
+
+	current time.Duration
+}
+
+// next - returns the wait before the next call and doubles it for the one after
+// never less than minBackoff so a zero or negative base can't make the calls spin
+func (b *backoff) next() time.Duration {
+	max := b.Max
+	if max < minBackoff {
+		max = minBackoff
+	}
+
+	if b.current == 0 {
+		b.current = b.Base
+	}
+
+	if b.current < minBackoff {
+		b.current = minBackoff
+	}
+
+	wait := b.current
+
+	b.current *= 2
+	if b.current > max {
+		b.current = max
+	}
+
+	if wait > max {
+		wait = max
+	}
+
+	return wait
+}
+
+// reset - starts over from the base wait after a successful call
+func (b *backoff) reset() {
+	b.current = 0
+}
@@ -0,0 +1,91 @@
+package client
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestErrorBudgetExceeded(t *testing.T) {
+	testCases := map[string]struct {
+		budget    *ErrorBudget
+		errors    int
+		successes int
+		exceeded  bool
+	}{
+		"within error limit": {
+			budget:   NewErrorBudget(time.Minute, 3, 0, 0),
+			errors:   3,
+			exceeded: false,
+		},
+		"over error limit": {
+			budget:   NewErrorBudget(time.Minute, 3, 0, 0),
+			errors:   4,
+			exceeded: true,
+		},
+		"within error ratio": {
+			budget:    NewErrorBudget(time.Minute, 0, 0.5, 4),
+			errors:    2,
+			successes: 2,
+			exceeded:  false,
+		},
+		"over error ratio": {
+			budget:    NewErrorBudget(time.Minute, 0, 0.5, 4),
+			errors:    3,
+			successes: 1,
+			exceeded:  true,
+		},
+		"too few samples for ratio": {
+			budget:   NewErrorBudget(time.Minute, 0, 0.5, 4),
+			errors:   3,
+			exceeded: false,
+		},
+	}
+
+	for name, tc := range testCases {
+		for i := 0; i < tc.errors; i++ {
+			tc.budget.RecordError()
+		}
+
+		for i := 0; i < tc.successes; i++ {
+			tc.budget.RecordSuccess()
+		}
+
+		require.Equal(t, tc.exceeded, tc.budget.Exceeded(), name)
+	}
+}
+
+func TestErrorBudgetWindow(t *testing.T) {
+	budget := NewErrorBudget(50*time.Millisecond, 1, 0, 0)
+
+	budget.RecordError()
+	budget.RecordError()
+	require.True(t, budget.Exceeded())
+
+	// old errors slide out of the window
+	time.Sleep(60 * time.Millisecond)
+	require.False(t, budget.Exceeded())
+
+	budget.RecordError()
+	budget.RecordError()
+	budget.Reset()
+	require.False(t, budget.Exceeded())
+}
+
+func TestBackoff(t *testing.T) {
+	retry := &backoff{Base: time.Second, Max: 5 * time.Second}
+
+	require.Equal(t, time.Second, retry.next())
+	require.Equal(t, 2*time.Second, retry.next())
+	require.Equal(t, 4*time.Second, retry.next())
+	require.Equal(t, 5*time.Second, retry.next())
+
+	retry.reset()
+	require.Equal(t, time.Second, retry.next())
+
+	// misconfigured waits are floored instead of retrying in a tight loop
+	retry = &backoff{Base: 0, Max: -time.Second}
+	require.Equal(t, minBackoff, retry.next())
+	require.Equal(t, minBackoff, retry.next())
+}
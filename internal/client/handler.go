@@ -0,0 +1,75 @@
+package client
+
+import (
+	"context"
+	"errors"
+)
+
+// Message - sqs message received from the sqsservice
+type Message struct {
+	// receipt handle of the message
+	ID string
+	// id assigned by sqs; the same across receives of the message
+	MessageID string
+	// payload of the message; the body wrapped in its envelopes once they're unwrapped
+	Body string
+	// body as received from the sqsservice
+	RawBody string
+	// envelopes unwrapped from the body, outermost first, e.g. an sns notification holding an s3 event
+	Envelopes []*Envelope
+	// number of times the message has been received, including this one
+	ReceiveCount int64
+	// string and number message attributes the message was sent with
+	Attributes map[string]string
+}
+
+// Handler - business logic processing the received messages
+// returning nil deletes the message from the queue
+// returning an error leaves the message in the queue to be retried
+// returning a PermanentError moves the message to the dead-letter queue
+type Handler interface {
+	Handle(ctx context.Context, msg *Message) error
+}
+
+// HandlerFunc - adapter to use ordinary functions as handlers
+type HandlerFunc func(ctx context.Context, msg *Message) error
+
+// Handle - calls f(ctx, msg)
+func (f HandlerFunc) Handle(ctx context.Context, msg *Message) error {
+	return f(ctx, msg)
+}
+
+// PermanentError - error of a message that will never be processed successfully
+// so retrying it is pointless
+type PermanentError struct {
+	Err error
+}
+
+func (e *PermanentError) Error() string {
+	return "permanent error: " + e.Err.Error()
+}
+
+func (e *PermanentError) Unwrap() error {
+	return e.Err
+}
+
+// Permanent - wraps err to dead-letter the message instead of retrying it
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	return &PermanentError{Err: err}
+}
+
+// IsPermanent - checks if err or any error it wraps is a PermanentError
+func IsPermanent(err error) bool {
+	var permanentErr *PermanentError
+
+	return errors.As(err, &permanentErr)
+}
+
+// noopHandler - accepts every message so it's deleted right away
+var noopHandler = HandlerFunc(func(ctx context.Context, msg *Message) error {
+	return nil
+})
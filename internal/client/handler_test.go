@@ -0,0 +1,35 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsPermanent(t *testing.T) {
+	testCases := map[string]struct {
+		err       error
+		permanent bool
+	}{
+		"permanent error": {
+			err:       Permanent(errors.New("malformed")),
+			permanent: true,
+		},
+		"wrapped permanent error": {
+			err:       fmt.Errorf("decoding: %w", Permanent(errors.New("malformed"))),
+			permanent: true,
+		},
+		"transient error": {
+			err:       errors.New("timeout"),
+			permanent: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		require.Equal(t, tc.permanent, IsPermanent(tc.err))
+	}
+
+	require.NoError(t, Permanent(nil))
+}
@@ -0,0 +1,113 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// JSONPath - subset of JSONPath selecting a single value of a json document
+// supports child fields and array indexes, e.g. $.detail.order.id, $.items[0].sku or $['order-id']
+type JSONPath struct {
+	path string
+	// field names and array indexes, from the root
+	steps []interface{}
+}
+
+// ParseJSONPath - compiles path; the leading $ is optional
+func ParseJSONPath(path string) (*JSONPath, error) {
+	p := &JSONPath{path: path}
+
+	rest := strings.TrimPrefix(strings.TrimSpace(path), "$")
+	for rest != "" {
+		switch {
+		case strings.HasPrefix(rest, "['"):
+			end := strings.Index(rest, "']")
+			if end < 0 {
+				return nil, fmt.Errorf("invalid json path %v: unclosed bracket", path)
+			}
+			p.steps = append(p.steps, rest[2:end])
+			rest = rest[end+2:]
+		case strings.HasPrefix(rest, "["):
+			end := strings.Index(rest, "]")
+			if end < 0 {
+				return nil, fmt.Errorf("invalid json path %v: unclosed bracket", path)
+			}
+			index, err := strconv.Atoi(rest[1:end])
+			if err != nil || index < 0 {
+				return nil, fmt.Errorf("invalid json path %v: invalid index %v", path, rest[1:end])
+			}
+			p.steps = append(p.steps, index)
+			rest = rest[end+1:]
+		case strings.HasPrefix(rest, "."):
+			rest = rest[1:]
+			end := strings.IndexAny(rest, ".[")
+			if end < 0 {
+				end = len(rest)
+			}
+			if end == 0 {
+				return nil, fmt.Errorf("invalid json path %v: empty field", path)
+			}
+			p.steps = append(p.steps, rest[:end])
+			rest = rest[end:]
+		default:
+			return nil, fmt.Errorf("invalid json path %v: unexpected %v", path, rest)
+		}
+	}
+
+	if len(p.steps) == 0 {
+		return nil, fmt.Errorf("invalid json path %v: no field", path)
+	}
+
+	return p, nil
+}
+
+func (p *JSONPath) String() string {
+	return p.path
+}
+
+// Lookup - returns the selected value of the json body
+// strings are returned as is and other values as json; returns false if the body isn't json or has no such value
+func (p *JSONPath) Lookup(body string) (string, bool) {
+	var value interface{}
+
+	decoder := json.NewDecoder(strings.NewReader(body))
+	decoder.UseNumber()
+	if err := decoder.Decode(&value); err != nil {
+		return "", false
+	}
+
+	for _, step := range p.steps {
+		switch s := step.(type) {
+		case string:
+			object, ok := value.(map[string]interface{})
+			if !ok {
+				return "", false
+			}
+			if value, ok = object[s]; !ok {
+				return "", false
+			}
+		case int:
+			array, ok := value.([]interface{})
+			if !ok || s >= len(array) {
+				return "", false
+			}
+			value = array[s]
+		}
+	}
+
+	switch v := value.(type) {
+	case nil:
+		return "", false
+	case string:
+		return v, true
+	}
+
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return "", false
+	}
+
+	return string(raw), true
+}
@@ -0,0 +1,79 @@
+package client
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSONPath(t *testing.T) {
+	body := `{"detail": {"order": {"id": "order-1", "total": 12.50}}, "items": [{"sku": "sku-1"}, {"sku": 2}], "order-id": "order-2", "empty": null}`
+
+	testCases := map[string]struct {
+		path     string
+		want     string
+		wantOk   bool
+		parseErr bool
+	}{
+		"nested field": {
+			path:   "$.detail.order.id",
+			want:   "order-1",
+			wantOk: true,
+		},
+		"number keeps its json form": {
+			path:   "$.detail.order.total",
+			want:   "12.50",
+			wantOk: true,
+		},
+		"array index": {
+			path:   "$.items[1].sku",
+			want:   "2",
+			wantOk: true,
+		},
+		"quoted field without root": {
+			path:   "['order-id']",
+			want:   "order-2",
+			wantOk: true,
+		},
+		"object as json": {
+			path:   "$.items[0]",
+			want:   `{"sku":"sku-1"}`,
+			wantOk: true,
+		},
+		"missing field": {
+			path: "$.detail.customer",
+		},
+		"index out of range": {
+			path: "$.items[5]",
+		},
+		"null value": {
+			path: "$.empty",
+		},
+		"root only": {
+			path:     "$",
+			parseErr: true,
+		},
+		"invalid index": {
+			path:     "$.items[first]",
+			parseErr: true,
+		},
+	}
+
+	for name, tc := range testCases {
+		path, err := ParseJSONPath(tc.path)
+		if tc.parseErr {
+			require.Error(t, err, name)
+			continue
+		}
+		require.NoError(t, err, name)
+
+		value, ok := path.Lookup(body)
+		require.Equal(t, tc.wantOk, ok, name)
+		require.Equal(t, tc.want, value, name)
+	}
+
+	path, err := ParseJSONPath("$.id")
+	require.NoError(t, err)
+	_, ok := path.Lookup("not json")
+	require.False(t, ok)
+}
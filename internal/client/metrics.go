@@ -0,0 +1,68 @@
+package client
+
+import (
+	"github.com/alvinlucillo/sqs-processor/internal/metrics"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const (
+	metricsNamespace = "sqsclient"
+)
+
+var (
+	rpcMetrics = metrics.NewRPC(prometheus.DefaultRegisterer, metricsNamespace)
+
+	receives = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "receives_total",
+		Help:      "Number of successful ReceiveMessage calls to the sqsservice.",
+	})
+	emptyReceives = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "empty_receives_total",
+		Help:      "Number of successful ReceiveMessage calls to the sqsservice that returned no messages.",
+	})
+	messagesReceived = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "messages_received_total",
+		Help:      "Number of messages received from the sqsservice, polled or streamed.",
+	})
+	messagesDeleted = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "messages_deleted_total",
+		Help:      "Number of messages the handler processed and the client deleted.",
+	})
+	messagesDuplicate = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "messages_duplicate_total",
+		Help:      "Number of messages deleted without being handled since their key was already processed.",
+	})
+	messagesFailed = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "messages_failed_total",
+		Help:      "Number of messages the handler failed to process by what was done with them: retry, dead_letter or max_attempts.",
+	}, []string{"action"})
+	messagesRouted = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "messages_routed_total",
+		Help:      "Number of messages a Router dispatched by route: the route's name, fallback or none.",
+	}, []string{"route"})
+	envelopesUnwrapped = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "envelopes_unwrapped_total",
+		Help:      "Number of envelopes unwrapped from the received messages by type: sns, eventbridge or s3.",
+	}, []string{"type"})
+	handlerDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Name:      "handler_duration_seconds",
+		Help:      "Duration of the handler by result: success, error, permanent_error or not_handled.",
+		Buckets:   prometheus.ExponentialBuckets(0.001, 2, 18),
+	}, []string{"result"})
+	messagesInFlight = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "messages_in_flight",
+		Help:      "Number of received messages being handled or waiting for a free worker.",
+	})
+)
@@ -0,0 +1,65 @@
+package client
+
+import (
+	"sync"
+	"time"
+)
+
+// polling strategies selectable through the environment
+const (
+	PollingStrategyFixed    = "fixed"
+	PollingStrategyAdaptive = "adaptive"
+)
+
+// PollingPolicy - decides how long the client waits before its next poll
+// requested is the number of messages asked for and received the number that came back
+type PollingPolicy interface {
+	Next(requested int, received int) time.Duration
+}
+
+// FixedPolling - always waits the same interval between polls
+type FixedPolling struct {
+	Interval time.Duration
+}
+
+// Next - returns the fixed interval
+func (p *FixedPolling) Next(requested int, received int) time.Duration {
+	return p.Interval
+}
+
+// AdaptivePolling - polls again right away after a full batch
+// backs off progressively from Min to Max on empty receives and starts over from Min on any activity
+type AdaptivePolling struct {
+	Min time.Duration
+	Max time.Duration
+
+	mu      sync.Mutex
+	current time.Duration
+}
+
+// Next - returns 0 after a full batch, Min after a partial one, and a growing interval after empty ones
+func (p *AdaptivePolling) Next(requested int, received int) time.Duration {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	switch {
+	case received > 0 && received >= requested:
+		p.current = 0
+		return 0
+	case received > 0:
+		p.current = 0
+		return p.Min
+	}
+
+	if p.current == 0 {
+		p.current = p.Min
+	} else {
+		p.current *= 2
+	}
+
+	if p.current > p.Max {
+		p.current = p.Max
+	}
+
+	return p.current
+}
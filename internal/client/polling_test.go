@@ -0,0 +1,32 @@
+package client
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdaptivePolling(t *testing.T) {
+	policy := &AdaptivePolling{Min: time.Second, Max: 5 * time.Second}
+
+	// full batches are followed by an immediate poll
+	require.Equal(t, time.Duration(0), policy.Next(10, 10))
+
+	// empty receives back off up to the ceiling
+	require.Equal(t, time.Second, policy.Next(10, 0))
+	require.Equal(t, 2*time.Second, policy.Next(10, 0))
+	require.Equal(t, 4*time.Second, policy.Next(10, 0))
+	require.Equal(t, 5*time.Second, policy.Next(10, 0))
+
+	// activity starts over
+	require.Equal(t, time.Second, policy.Next(10, 3))
+	require.Equal(t, time.Second, policy.Next(10, 0))
+}
+
+func TestFixedPolling(t *testing.T) {
+	policy := &FixedPolling{Interval: 5 * time.Second}
+
+	require.Equal(t, 5*time.Second, policy.Next(10, 10))
+	require.Equal(t, 5*time.Second, policy.Next(10, 0))
+}
@@ -0,0 +1,238 @@
+package client
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	pb "github.com/alvinlucillo/sqs-processor/protogen/sqs"
+)
+
+// time stop waits for the cancelled handlers to return before giving up on them
+const defaultCancelTimeout = 5 * time.Second
+
+// workerPool - processes messages with a bounded number of concurrent workers
+// a message holds a slot from the time it's reserved until its worker is done with it
+// so the poller only receives as many messages as the pool can take
+type workerPool struct {
+	jobs  chan *pb.SQSResponseMessage
+	slots chan struct{}
+	// held by the messages being worked on; a message suspended while waiting, e.g. for its route, frees its own
+	active chan struct{}
+	wg     sync.WaitGroup
+
+	mu sync.Mutex
+	// receipt handle -> messages submitted but not yet finished
+	pending map[string]*pb.SQSResponseMessage
+	// receipt handles of the pending messages a worker started on
+	started map[string]bool
+
+	// passed to work; cancelled once stopping the pool times out
+	ctx    context.Context
+	cancel context.CancelFunc
+	// time stop waits for the handlers to return once ctx is cancelled
+	cancelTimeout time.Duration
+}
+
+// workerKey - key of the pool in the context passed to work
+type workerKey struct{}
+
+// newWorkerPool - starts concurrency workers calling work for every submitted message
+// up to queueDepth messages wait for a free worker on top of the ones being worked on
+func newWorkerPool(concurrency int, queueDepth int, work func(ctx context.Context, msg *pb.SQSResponseMessage)) *workerPool {
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+
+	if queueDepth < 0 {
+		queueDepth = 0
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	p := &workerPool{
+		jobs:    make(chan *pb.SQSResponseMessage, concurrency+queueDepth),
+		slots:   make(chan struct{}, concurrency+queueDepth),
+		active:  make(chan struct{}, concurrency),
+		pending: make(map[string]*pb.SQSResponseMessage),
+		started: make(map[string]bool),
+		ctx:     ctx,
+		cancel:  cancel,
+
+		cancelTimeout: defaultCancelTimeout,
+	}
+
+	workCtx := context.WithValue(ctx, workerKey{}, p)
+
+	// a goroutine per slot so suspended messages don't keep the queued ones from being worked on
+	// only concurrency of them work at once
+	for i := 0; i < concurrency+queueDepth; i++ {
+		p.wg.Add(1)
+
+		go func() {
+			defer p.wg.Done()
+
+			for msg := range p.jobs {
+				// messages still queued after stopping timed out are left to the caller of stop
+				select {
+				case p.active <- struct{}{}:
+					if p.start(msg) {
+						work(workCtx, msg)
+						p.finish(msg)
+					}
+					<-p.active
+				case <-p.ctx.Done():
+				}
+
+				<-p.slots
+			}
+		}()
+	}
+
+	return p
+}
+
+// suspendWorker - frees the worker of the message handled with ctx while it waits, e.g. for a slot of its route,
+// so the pool works on other messages meanwhile
+// the returned resume must be called before working on the message again; it waits for a free worker
+// no-op for contexts not passed by a pool
+func suspendWorker(ctx context.Context) (resume func()) {
+	p, ok := ctx.Value(workerKey{}).(*workerPool)
+	if !ok {
+		return func() {}
+	}
+
+	<-p.active
+
+	return func() {
+		p.active <- struct{}{}
+	}
+}
+
+// reserve - blocks until a slot is free then takes up to max free slots
+// returns the number of slots taken
+func (p *workerPool) reserve(ctx context.Context, max int) (int, error) {
+	if max <= 0 {
+		max = 1
+	}
+
+	select {
+	case p.slots <- struct{}{}:
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	}
+
+	reserved := 1
+	for reserved < max {
+		select {
+		case p.slots <- struct{}{}:
+			reserved++
+		default:
+			return reserved, nil
+		}
+	}
+
+	return reserved, nil
+}
+
+// release - frees reserved slots that weren't used
+func (p *workerPool) release(n int) {
+	for i := 0; i < n; i++ {
+		<-p.slots
+	}
+}
+
+// submit - hands the message to the workers; a slot must be reserved for it
+func (p *workerPool) submit(msg *pb.SQSResponseMessage) {
+	p.mu.Lock()
+	p.pending[msg.MessageID] = msg
+	p.mu.Unlock()
+
+	messagesInFlight.Inc()
+
+	p.jobs <- msg
+}
+
+// start - marks a message as taken by a worker; false once stopping timed out, leaving it to the caller of stop
+func (p *workerPool) start(msg *pb.SQSResponseMessage) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.ctx.Err() != nil {
+		return false
+	}
+
+	p.started[msg.MessageID] = true
+
+	return true
+}
+
+// finish - stops tracking a message its worker is done with
+// messages stop already returned were counted out of the in-flight gauge by it
+func (p *workerPool) finish(msg *pb.SQSResponseMessage) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if _, ok := p.pending[msg.MessageID]; ok {
+		delete(p.pending, msg.MessageID)
+		delete(p.started, msg.MessageID)
+		messagesInFlight.Dec()
+	}
+}
+
+// stop - waits up to timeout for the workers to finish the submitted messages
+// once timed out, cancels the context passed to work, waits up to cancelTimeout for the workers to return
+// and returns the messages no worker started on; started ones are settled by their workers
+// also returns the started messages whose handlers ignored the cancellation and were given up on
+func (p *workerPool) stop(timeout time.Duration) (unstarted []*pb.SQSResponseMessage, abandoned []*pb.SQSResponseMessage) {
+	close(p.jobs)
+
+	done := make(chan struct{})
+	go func() {
+		p.wg.Wait()
+		close(done)
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case <-done:
+		p.cancel()
+		return nil, nil
+	case <-timer.C:
+	}
+
+	p.cancel()
+
+	p.mu.Lock()
+	unstarted = make([]*pb.SQSResponseMessage, 0, len(p.pending))
+	for receiptHandle, msg := range p.pending {
+		if !p.started[receiptHandle] {
+			unstarted = append(unstarted, msg)
+			delete(p.pending, receiptHandle)
+		}
+	}
+	p.mu.Unlock()
+
+	messagesInFlight.Sub(float64(len(unstarted)))
+
+	// so nothing is settled once the caller releases the unstarted messages and closes the connection
+	// unless a handler doesn't return, which would otherwise keep the client from stopping
+	cancelTimer := time.NewTimer(p.cancelTimeout)
+	defer cancelTimer.Stop()
+
+	select {
+	case <-done:
+		return unstarted, nil
+	case <-cancelTimer.C:
+	}
+
+	p.mu.Lock()
+	for _, msg := range p.pending {
+		abandoned = append(abandoned, msg)
+	}
+	p.mu.Unlock()
+
+	return unstarted, abandoned
+}
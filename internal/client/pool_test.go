@@ -0,0 +1,144 @@
+package client
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	pb "github.com/alvinlucillo/sqs-processor/protogen/sqs"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorkerPool(t *testing.T) {
+	var running, maxRunning, processed int64
+	unblock := make(chan struct{})
+
+	pool := newWorkerPool(2, 1, func(ctx context.Context, msg *pb.SQSResponseMessage) {
+		n := atomic.AddInt64(&running, 1)
+		for {
+			max := atomic.LoadInt64(&maxRunning)
+			if n <= max || atomic.CompareAndSwapInt64(&maxRunning, max, n) {
+				break
+			}
+		}
+
+		<-unblock
+		atomic.AddInt64(&running, -1)
+		atomic.AddInt64(&processed, 1)
+	})
+
+	// the pool takes as many messages as workers plus queue depth
+	reserved, err := pool.reserve(context.Background(), 10)
+	require.NoError(t, err)
+	require.Equal(t, 3, reserved)
+
+	for i := 0; i < reserved; i++ {
+		pool.submit(&pb.SQSResponseMessage{MessageID: MockMessageID})
+	}
+
+	// no capacity is left until a worker finishes
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err = pool.reserve(ctx, 1)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	close(unblock)
+	unstarted, abandoned := pool.stop(time.Second)
+	require.Empty(t, unstarted)
+	require.Empty(t, abandoned)
+
+	require.Equal(t, int64(3), atomic.LoadInt64(&processed))
+	require.Equal(t, int64(2), atomic.LoadInt64(&maxRunning))
+}
+
+func TestWorkerPoolStopTimeout(t *testing.T) {
+	inFlight := testutil.ToFloat64(messagesInFlight)
+	var returned int64
+
+	pool := newWorkerPool(1, 1, func(ctx context.Context, msg *pb.SQSResponseMessage) {
+		// handler only gives up once the pool times out
+		<-ctx.Done()
+		atomic.AddInt64(&returned, 1)
+	})
+
+	reserved, err := pool.reserve(context.Background(), 2)
+	require.NoError(t, err)
+	require.Equal(t, 2, reserved)
+
+	pool.submit(&pb.SQSResponseMessage{MessageID: "message-1"})
+	pool.submit(&pb.SQSResponseMessage{MessageID: "message-2"})
+
+	// only the queued message is returned; the running one is left to its worker, which stop waits for
+	unstarted, abandoned := pool.stop(50 * time.Millisecond)
+	require.Equal(t, []*pb.SQSResponseMessage{{MessageID: "message-2"}}, unstarted)
+	require.Empty(t, abandoned)
+	require.Equal(t, int64(1), atomic.LoadInt64(&returned))
+
+	// every message is counted out of the in-flight gauge once
+	require.Equal(t, inFlight, testutil.ToFloat64(messagesInFlight))
+}
+
+func TestWorkerPoolStopAbandon(t *testing.T) {
+	started := make(chan struct{})
+	unblock := make(chan struct{})
+	defer close(unblock)
+
+	pool := newWorkerPool(1, 0, func(ctx context.Context, msg *pb.SQSResponseMessage) {
+		close(started)
+		// handler ignores the cancellation
+		<-unblock
+	})
+	pool.cancelTimeout = 50 * time.Millisecond
+
+	reserved, err := pool.reserve(context.Background(), 1)
+	require.NoError(t, err)
+	require.Equal(t, 1, reserved)
+
+	pool.submit(&pb.SQSResponseMessage{MessageID: "message-1"})
+	<-started
+
+	// stop gives up on the handler instead of waiting for it forever
+	start := time.Now()
+	unstarted, abandoned := pool.stop(50 * time.Millisecond)
+	require.Less(t, time.Since(start), time.Second)
+	require.Empty(t, unstarted)
+	require.Equal(t, []*pb.SQSResponseMessage{{MessageID: "message-1"}}, abandoned)
+}
+
+func TestWorkerPoolSuspend(t *testing.T) {
+	waiting := make(chan struct{})
+	proceed := make(chan struct{})
+	var worked int64
+
+	pool := newWorkerPool(1, 1, func(ctx context.Context, msg *pb.SQSResponseMessage) {
+		if msg.MessageID == "message-1" {
+			// e.g. waiting for a slot of its route
+			resume := suspendWorker(ctx)
+			close(waiting)
+			<-proceed
+			resume()
+		}
+
+		atomic.AddInt64(&worked, 1)
+	})
+
+	reserved, err := pool.reserve(context.Background(), 2)
+	require.NoError(t, err)
+	require.Equal(t, 2, reserved)
+
+	pool.submit(&pb.SQSResponseMessage{MessageID: "message-1"})
+	<-waiting
+	pool.submit(&pb.SQSResponseMessage{MessageID: "message-2"})
+
+	// the only worker is free for the queued message while the first one is suspended
+	require.Eventually(t, func() bool { return atomic.LoadInt64(&worked) == 1 }, time.Second, 10*time.Millisecond)
+
+	close(proceed)
+	unstarted, abandoned := pool.stop(time.Second)
+	require.Empty(t, unstarted)
+	require.Empty(t, abandoned)
+	require.Equal(t, int64(2), atomic.LoadInt64(&worked))
+}
@@ -0,0 +1,63 @@
+package client
+
+import (
+	"encoding/json"
+	"os"
+	"sync"
+	"time"
+)
+
+// QuarantinedMessage - line of the quarantine file
+type QuarantinedMessage struct {
+	Time         time.Time `json:"time"`
+	MessageID    string    `json:"messageID"`
+	Body         string    `json:"body"`
+	ReceiveCount int64     `json:"receiveCount"`
+	// last error the message failed with
+	Error string `json:"error"`
+}
+
+// QuarantineFile - appends the messages over the maximum attempts to a file, one json object per line
+// e.g. to inspect and replay them when there's no dead-letter queue
+type QuarantineFile struct {
+	Path string
+
+	mu   sync.Mutex
+	file *os.File
+}
+
+// NewQuarantineFile - opens or creates the file at path
+func NewQuarantineFile(path string) (*QuarantineFile, error) {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, err
+	}
+
+	return &QuarantineFile{Path: path, file: file}, nil
+}
+
+// Add - appends the message, as received, and the error it failed with
+// the file is synced so the message is kept even if the client crashes once it's deleted from the queue
+func (q *QuarantineFile) Add(msg *Message, reason error) error {
+	line, err := json.Marshal(QuarantinedMessage{Time: time.Now().UTC(), MessageID: msg.MessageID, Body: msg.RawBody,
+		ReceiveCount: msg.ReceiveCount, Error: reason.Error()})
+	if err != nil {
+		return err
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if _, err := q.file.Write(append(line, '\n')); err != nil {
+		return err
+	}
+
+	return q.file.Sync()
+}
+
+func (q *QuarantineFile) Close() error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return q.file.Close()
+}
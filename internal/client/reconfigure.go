@@ -0,0 +1,219 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/alvinlucillo/sqs-processor/internal/config"
+	"github.com/alvinlucillo/sqs-processor/internal/logging"
+	"github.com/alvinlucillo/sqs-processor/internal/tracing"
+
+	"github.com/rs/zerolog"
+)
+
+const (
+	// longest visibility timeout and wait time accepted by sqs
+	maxVisibilityTimeout = 43200
+	maxWaitTime          = 20
+)
+
+// liveSettings - settings of the environment Reconfigure applies while running
+// the others, e.g. the sqsservice target or the concurrency, only apply on restart
+var liveSettings = map[string]bool{
+	"PollingInterval":    true,
+	"PollingStrategy":    true,
+	"PollingMinInterval": true,
+	"VisibilityTimeout":  true,
+	"WaitTime":           true,
+	"MaximumMessages":    true,
+	"MaximumWaitTime":    true,
+	"MaximumUnacked":     true,
+	"ErrorRateLimit":     true,
+	"ErrorWindow":        true,
+	"ErrorRatioLimit":    true,
+	"ErrorMinSamples":    true,
+	"ErrorAction":        true,
+	"ErrorPauseDuration": true,
+	"DrainTimeout":       true,
+	"MaxAttempts":        true,
+	"LogLevel":           true,
+}
+
+// Validate - checks the settings are known and within the limits of sqs
+// returns every invalid setting at once
+func (env Environment) Validate() error {
+	errs := make([]error, 0)
+
+	switch env.ErrorAction {
+	case ErrorActionExit, ErrorActionPause, ErrorActionDegrade:
+	default:
+		errs = append(errs, fmt.Errorf("unknown error action: %v", env.ErrorAction))
+	}
+
+	switch env.PollingStrategy {
+	case PollingStrategyFixed, PollingStrategyAdaptive:
+	default:
+		errs = append(errs, fmt.Errorf("unknown polling strategy: %v", env.PollingStrategy))
+	}
+
+	if _, err := zerolog.ParseLevel(env.LogLevel); err != nil {
+		errs = append(errs, err)
+	}
+
+	switch env.LogFormat {
+	case logging.FormatJSON, logging.FormatConsole, "":
+	default:
+		errs = append(errs, fmt.Errorf("unknown log format: %v", env.LogFormat))
+	}
+
+	switch env.TracingExporter {
+	case tracing.ExporterNone, tracing.ExporterOTLP, tracing.ExporterFile, "":
+	default:
+		errs = append(errs, fmt.Errorf("unknown tracing exporter: %v", env.TracingExporter))
+	}
+
+	if env.VisibilityTimeout < 0 || env.VisibilityTimeout > maxVisibilityTimeout {
+		errs = append(errs, fmt.Errorf("visibility timeout must be between 0 and %v: %v", maxVisibilityTimeout, env.VisibilityTimeout))
+	}
+
+	if env.WaitTime < 0 || env.WaitTime > maxWaitTime {
+		errs = append(errs, fmt.Errorf("wait time must be between 0 and %v: %v", maxWaitTime, env.WaitTime))
+	}
+
+	if env.MaximumMessages < 1 {
+		errs = append(errs, fmt.Errorf("maximum messages must be at least 1: %v", env.MaximumMessages))
+	}
+
+	if env.UseStream && env.MaximumUnacked < 1 {
+		errs = append(errs, fmt.Errorf("maximum unacked must be at least 1: %v", env.MaximumUnacked))
+	}
+
+	if env.ErrorBackoffBase < 1 || env.ErrorBackoffMax < env.ErrorBackoffBase {
+		errs = append(errs, fmt.Errorf("error backoff base must be at least 1 and no more than the error backoff max: %v, %v",
+			env.ErrorBackoffBase, env.ErrorBackoffMax))
+	}
+
+	// adaptive polling doubles the interval after empty receives, starting from the min
+	if env.PollingStrategy == PollingStrategyAdaptive && (env.PollingMinInterval < 1 || env.PollingInterval < env.PollingMinInterval) {
+		errs = append(errs, fmt.Errorf("adaptive polling requires a min interval of at least 1 and no more than the polling interval: %v, %v",
+			env.PollingMinInterval, env.PollingInterval))
+	}
+
+	if env.Concurrency < 1 {
+		errs = append(errs, fmt.Errorf("concurrency must be at least 1: %v", env.Concurrency))
+	}
+
+	if env.QueueDepth < 0 || env.PollingInterval < 0 || env.PollingMinInterval < 0 || env.DrainTimeout < 0 || env.ConfigReloadInterval < 0 ||
+		env.RateLimitRetries < 0 || env.DedupTTL < 0 || env.MaxAttempts < 0 || env.SNSMaxAge < 0 {
+		errs = append(errs, errors.New("queue depth, polling intervals, drain timeout, config reload interval, rate limit retries, dedup ttl, max attempts and sns max age can't be negative"))
+	}
+
+	if env.DedupKeyPath != "" {
+		if _, err := ParseJSONPath(env.DedupKeyPath); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	// tokens aren't sent over plaintext tcp, even to localhost
+	if env.AuthTokenFile != "" && env.TLSCAFile == "" && env.TLSCertFile == "" && !isUnixTarget(env.SQSServiceTarget) {
+		errs = append(errs, errors.New("auth token requires TLS or a unix socket target"))
+	}
+
+	if err := env.Routes.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+
+	if _, err := envelopeTypes(env.Envelopes); err != nil {
+		errs = append(errs, err)
+	}
+
+	// raw deliveries aren't signed, so anyone able to send to the queue could pass for the topic
+	if env.SNSRawDelivery && env.SNSCertFile != "" {
+		errs = append(errs, errors.New("sns signature verification requires a subscription without raw delivery"))
+	}
+
+	// every account's topics are signed with the same certificate, so the signature alone doesn't prove the sender
+	if env.SNSCertFile != "" && len(splitList(env.SNSTopicArns)) == 0 {
+		errs = append(errs, errors.New("sns signature verification requires the arns of the accepted topics"))
+	}
+
+	if env.ErrorRatioLimit < 0 || env.ErrorRatioLimit > 1 {
+		errs = append(errs, fmt.Errorf("error ratio limit must be between 0 and 1: %v", env.ErrorRatioLimit))
+	}
+
+	if env.TracingSampleRatio < 0 || env.TracingSampleRatio > 1 {
+		errs = append(errs, fmt.Errorf("tracing sample ratio must be between 0 and 1: %v", env.TracingSampleRatio))
+	}
+
+	return errors.Join(errs...)
+}
+
+// newPollingPolicy - returns the policy of the environment's polling strategy
+func newPollingPolicy(env Environment) PollingPolicy {
+	if env.PollingStrategy == PollingStrategyFixed {
+		return &FixedPolling{Interval: time.Duration(env.PollingInterval) * time.Second}
+	}
+
+	return &AdaptivePolling{Min: time.Duration(env.PollingMinInterval) * time.Second, Max: time.Duration(env.PollingInterval) * time.Second}
+}
+
+// Reconfigure - applies the polling, receive, error budget, drain, max attempts and log level settings of env while running
+// the other settings that changed are logged as needing a restart
+// polls and subscriptions already made keep the settings they were made with
+func (s *SQSClient) Reconfigure(env Environment) error {
+	l := s.Logger.With().Str("function", "Reconfigure").Logger()
+
+	if err := env.Validate(); err != nil {
+		l.Error().Err(err).Msg("Invalid environment")
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	changed := config.Changed(s.env, env)
+	if len(changed) == 0 {
+		return nil
+	}
+
+	applied := make([]string, 0, len(changed))
+	for _, name := range changed {
+		if !liveSettings[name] {
+			l.Warn().Str("setting", name).Msg("Setting changed, restart to apply it")
+			continue
+		}
+		applied = append(applied, name)
+	}
+
+	// only changed levels are applied so a reload doesn't undo SetLogLevel or the debug toggle
+	if env.LogLevel != s.env.LogLevel {
+		if err := logging.SetConfiguredLevel(env.LogLevel); err != nil {
+			return err
+		}
+	}
+
+	s.PollingInterval = env.PollingInterval
+	s.VisibilityTimeout = env.VisibilityTimeout
+	s.WaitTime = env.WaitTime
+	s.MaximumMessages = env.MaximumMessages
+	s.MaximumWaitTime = env.MaximumWaitTime
+	s.MaximumUnacked = env.MaximumUnacked
+	s.ErrorAction = env.ErrorAction
+	s.ErrorPauseDuration = env.ErrorPauseDuration
+	s.DrainTimeout = env.DrainTimeout
+	s.MaxAttempts = env.MaxAttempts
+	s.ErrorBudget.SetLimits(time.Duration(env.ErrorWindow)*time.Second, env.ErrorRateLimit, env.ErrorRatioLimit, env.ErrorMinSamples)
+
+	if !s.customPolling {
+		s.PollingPolicy = newPollingPolicy(env)
+	}
+
+	s.env = env
+
+	if len(applied) > 0 {
+		l.Info().Strs("settings", applied).Msg("Applied changed settings")
+	}
+
+	return nil
+}
@@ -0,0 +1,168 @@
+package client
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kelseyhightower/envconfig"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+)
+
+// defaultEnvironment - returns the environment with every default
+func defaultEnvironment(t *testing.T) Environment {
+	var env Environment
+	require.NoError(t, envconfig.Process("clienttest", &env))
+
+	return env
+}
+
+func TestValidate(t *testing.T) {
+	testCases := map[string]struct {
+		change  func(env *Environment)
+		wantErr bool
+	}{
+		"defaults": {
+			change: func(env *Environment) {},
+		},
+		"unknown error action": {
+			change:  func(env *Environment) { env.ErrorAction = "retry" },
+			wantErr: true,
+		},
+		"unknown polling strategy": {
+			change:  func(env *Environment) { env.PollingStrategy = "sometimes" },
+			wantErr: true,
+		},
+		"unknown log level": {
+			change:  func(env *Environment) { env.LogLevel = "verbose" },
+			wantErr: true,
+		},
+		"wait time over sqs limit": {
+			change:  func(env *Environment) { env.WaitTime = 21 },
+			wantErr: true,
+		},
+		"no error backoff": {
+			change:  func(env *Environment) { env.ErrorBackoffBase = 0 },
+			wantErr: true,
+		},
+		"error backoff max under base": {
+			change:  func(env *Environment) { env.ErrorBackoffMax = 0 },
+			wantErr: true,
+		},
+		"adaptive polling without min interval": {
+			change:  func(env *Environment) { env.PollingMinInterval = 0 },
+			wantErr: true,
+		},
+		"adaptive polling interval under min interval": {
+			change:  func(env *Environment) { env.PollingMinInterval = 10 },
+			wantErr: true,
+		},
+		"fixed polling without min interval": {
+			change: func(env *Environment) {
+				env.PollingStrategy = PollingStrategyFixed
+				env.PollingMinInterval = 0
+			},
+		},
+		"invalid route": {
+			change:  func(env *Environment) { env.Routes = RouteConfigs{{Name: "orders"}} },
+			wantErr: true,
+		},
+		"no concurrency": {
+			change:  func(env *Environment) { env.Concurrency = 0 },
+			wantErr: true,
+		},
+		"auth token over plaintext": {
+			change:  func(env *Environment) { env.AuthTokenFile = "token" },
+			wantErr: true,
+		},
+		"auth token over a unix socket": {
+			change: func(env *Environment) {
+				env.AuthTokenFile = "token"
+				env.SQSServiceTarget = "unix:///var/run/sqsservice.sock"
+			},
+		},
+		"sns signature without topics": {
+			change:  func(env *Environment) { env.SNSCertFile = "sns.pem" },
+			wantErr: true,
+		},
+		"sns signature with topics": {
+			change: func(env *Environment) {
+				env.SNSCertFile = "sns.pem"
+				env.SNSTopicArns = "arn:aws:sns:us-east-1:123456789012:orders"
+			},
+		},
+		"unknown envelope": {
+			change:  func(env *Environment) { env.Envelopes = "sns,kinesis" },
+			wantErr: true,
+		},
+		"sns signature of raw deliveries": {
+			change: func(env *Environment) {
+				env.SNSRawDelivery = true
+				env.SNSCertFile = "sns.pem"
+			},
+			wantErr: true,
+		},
+		"error ratio over 1": {
+			change:  func(env *Environment) { env.ErrorRatioLimit = 2 },
+			wantErr: true,
+		},
+	}
+
+	for name, tc := range testCases {
+		env := defaultEnvironment(t)
+		tc.change(&env)
+
+		err := env.Validate()
+		if tc.wantErr {
+			require.Error(t, err, name)
+		} else {
+			require.NoError(t, err, name)
+		}
+	}
+}
+
+func TestReconfigure(t *testing.T) {
+	defer zerolog.SetGlobalLevel(zerolog.GlobalLevel())
+	zerolog.SetGlobalLevel(zerolog.InfoLevel)
+
+	env := defaultEnvironment(t)
+	sqsClient := &SQSClient{Logger: zerolog.Nop(), ErrorBudget: NewErrorBudget(time.Minute, 10, 0, 20), Concurrency: env.Concurrency,
+		PollingPolicy: newPollingPolicy(env), env: env}
+
+	reloaded := env
+	reloaded.PollingStrategy = PollingStrategyFixed
+	reloaded.PollingInterval = 30
+	reloaded.MaximumMessages = 10
+	reloaded.ErrorRateLimit = 3
+	reloaded.LogLevel = "debug"
+	reloaded.Concurrency = 8
+
+	require.NoError(t, sqsClient.Reconfigure(reloaded))
+
+	require.Equal(t, &FixedPolling{Interval: 30 * time.Second}, sqsClient.PollingPolicy)
+	require.Equal(t, 10, sqsClient.MaximumMessages)
+	require.Equal(t, 3, sqsClient.ErrorBudget.MaxErrors)
+	require.Equal(t, zerolog.DebugLevel, zerolog.GlobalLevel())
+	// only applies on restart
+	require.Equal(t, env.Concurrency, sqsClient.Concurrency)
+
+	// a level changed since, e.g. by the debug toggle, is kept when the reload doesn't change it
+	zerolog.SetGlobalLevel(zerolog.WarnLevel)
+	reloaded.MaximumMessages = 5
+	require.NoError(t, sqsClient.Reconfigure(reloaded))
+	require.Equal(t, zerolog.WarnLevel, zerolog.GlobalLevel())
+
+	// invalid settings are rejected as a whole
+	invalid := reloaded
+	invalid.MaximumMessages = 7
+	invalid.ErrorAction = "retry"
+	require.Error(t, sqsClient.Reconfigure(invalid))
+	require.Equal(t, 5, sqsClient.MaximumMessages)
+
+	// a policy set by WithPollingPolicy is kept
+	custom := &FixedPolling{Interval: time.Second}
+	sqsClient.PollingPolicy, sqsClient.customPolling = custom, true
+	reloaded.PollingInterval = 60
+	require.NoError(t, sqsClient.Reconfigure(reloaded))
+	require.Same(t, custom, sqsClient.PollingPolicy)
+}
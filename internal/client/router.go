@@ -0,0 +1,286 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// route label of the messages handled by the router's fallback
+	fallbackRoute = "fallback"
+	// route label of the messages no route matched and that have no fallback
+	unroutedRoute = "none"
+)
+
+// ErrNoRoute - returned, wrapped as permanent, for messages no route matches when the router has no fallback
+var ErrNoRoute = errors.New("no route matches the message")
+
+// errNotHandled - wrapped by the errors of messages given up on before being handled, e.g. while waiting for their route
+// so the client releases them without counting a failed attempt
+var errNotHandled = errors.New("message wasn't handled")
+
+// Matcher - selects the messages of a route
+type Matcher interface {
+	Match(msg *Message) bool
+}
+
+// MatcherFunc - adapter to use ordinary functions as matchers
+type MatcherFunc func(msg *Message) bool
+
+// Match - calls f(msg)
+func (f MatcherFunc) Match(msg *Message) bool {
+	return f(msg)
+}
+
+// AttributeEquals - matches the messages sent with the message attribute name set to value
+func AttributeEquals(name string, value string) Matcher {
+	return MatcherFunc(func(msg *Message) bool {
+		attribute, ok := msg.Attributes[name]
+		return ok && attribute == value
+	})
+}
+
+// JSONFieldEquals - matches the messages whose json body has value at path, e.g. $.detail.type
+// non-string values are compared as json, e.g. 5 or true
+func JSONFieldEquals(path string, value string) (Matcher, error) {
+	jsonPath, err := ParseJSONPath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return MatcherFunc(func(msg *Message) bool {
+		field, ok := jsonPath.Lookup(msg.Body)
+		return ok && field == value
+	}), nil
+}
+
+// BodyMatches - matches the messages whose body contains a match of the regular expression pattern
+func BodyMatches(pattern string) (Matcher, error) {
+	expr, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid body pattern: %w", err)
+	}
+
+	return MatcherFunc(func(msg *Message) bool {
+		return expr.MatchString(msg.Body)
+	}), nil
+}
+
+// Route - handler of the messages its matcher selects
+type Route struct {
+	// label of the route's messages in sqsclient_messages_routed_total, e.g. orders
+	Name    string
+	Matcher Matcher
+	Handler Handler
+	// most messages the route handles at once; 0 doesn't limit it besides the client's concurrency
+	Concurrency int
+}
+
+// RouteConfig - route defined in the environment or a config file
+// its handler is looked up by name among the handlers the program registers
+// exactly one of Attribute, JSONPath and BodyPattern selects its messages
+type RouteConfig struct {
+	Name string `yaml:"name"`
+	// name the route's handler is registered under; defaults to Name
+	Handler string `yaml:"handler"`
+	// message attribute, or JSONPath of the json body, whose value must be Value
+	Attribute string `yaml:"attribute"`
+	JSONPath  string `yaml:"jsonPath"`
+	Value     string `yaml:"value"`
+	// regular expression the body must contain a match of
+	BodyPattern string `yaml:"bodyPattern"`
+	Concurrency int    `yaml:"concurrency"`
+}
+
+// RouteConfigs - routes defined in the environment or a config file, in the order they're matched
+type RouteConfigs []RouteConfig
+
+// Decode - reads the routes as a yaml or json list; used by envconfig and the config file
+func (r *RouteConfigs) Decode(value string) error {
+	var routes RouteConfigs
+	if err := yaml.Unmarshal([]byte(value), &routes); err != nil {
+		return fmt.Errorf("invalid routes: %w", err)
+	}
+
+	*r = routes
+
+	return nil
+}
+
+// matcher - returns the matcher of the route's attribute, json path or body pattern
+func (c RouteConfig) matcher() (Matcher, error) {
+	selectors := 0
+	for _, selector := range []string{c.Attribute, c.JSONPath, c.BodyPattern} {
+		if selector != "" {
+			selectors++
+		}
+	}
+
+	if selectors != 1 {
+		return nil, fmt.Errorf("route %v requires exactly one of attribute, jsonPath and bodyPattern", c.Name)
+	}
+
+	switch {
+	case c.Attribute != "":
+		return AttributeEquals(c.Attribute, c.Value), nil
+	case c.JSONPath != "":
+		return JSONFieldEquals(c.JSONPath, c.Value)
+	}
+
+	return BodyMatches(c.BodyPattern)
+}
+
+// Validate - checks the routes could be added to a router, besides their handlers being registered
+func (r RouteConfigs) Validate() error {
+	router := NewRouter(nil)
+
+	for _, c := range r {
+		matcher, err := c.matcher()
+		if err != nil {
+			return err
+		}
+
+		// handlers are only looked up by Router, so any will do here
+		noop := HandlerFunc(func(ctx context.Context, msg *Message) error { return nil })
+		if err := router.Add(Route{Name: c.Name, Matcher: matcher, Handler: noop, Concurrency: c.Concurrency}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Router - creates a router of the routes handing their messages to the handlers registered under their names
+// messages no route matches go to fallback
+func (r RouteConfigs) Router(handlers map[string]Handler, fallback Handler) (*Router, error) {
+	router := NewRouter(fallback)
+
+	for _, c := range r {
+		matcher, err := c.matcher()
+		if err != nil {
+			return nil, err
+		}
+
+		name := c.Handler
+		if name == "" {
+			name = c.Name
+		}
+
+		handler, ok := handlers[name]
+		if !ok {
+			return nil, fmt.Errorf("route %v uses unknown handler: %v", c.Name, name)
+		}
+
+		if err := router.Add(Route{Name: c.Name, Matcher: matcher, Handler: handler, Concurrency: c.Concurrency}); err != nil {
+			return nil, err
+		}
+	}
+
+	return router, nil
+}
+
+// route - registered route and the slots limiting its concurrency; internally used
+type route struct {
+	Route
+	// nil if the route's concurrency isn't limited
+	slots chan struct{}
+}
+
+// acquire - takes a slot of the route, suspending the client's worker while they're all taken
+func (rt *route) acquire(ctx context.Context) error {
+	select {
+	case rt.slots <- struct{}{}:
+		return nil
+	default:
+	}
+
+	resume := suspendWorker(ctx)
+	defer resume()
+
+	select {
+	case rt.slots <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("%w: waiting for route %v: %w", errNotHandled, rt.Name, ctx.Err())
+	}
+}
+
+// Router - Handler dispatching every message to the first registered route matching it
+// e.g. by a message attribute, a field of the json body or a pattern of the body
+// routes must be added before the client starts handling messages
+type Router struct {
+	// handles the messages no route matches; if nil they're dead-lettered with ErrNoRoute
+	Fallback Handler
+
+	routes []*route
+}
+
+// NewRouter - creates a router handling the messages no route matches with fallback
+func NewRouter(fallback Handler) *Router {
+	return &Router{Fallback: fallback}
+}
+
+// Add - registers rt after the routes already added, so it only gets the messages they don't match
+func (r *Router) Add(rt Route) error {
+	if rt.Name == "" || rt.Matcher == nil || rt.Handler == nil {
+		return errors.New("route requires a name, a matcher and a handler")
+	}
+
+	if rt.Name == fallbackRoute || rt.Name == unroutedRoute {
+		return fmt.Errorf("route name %v is reserved", rt.Name)
+	}
+
+	if rt.Concurrency < 0 {
+		return fmt.Errorf("route concurrency can't be negative: %v", rt.Concurrency)
+	}
+
+	for _, registered := range r.routes {
+		if registered.Name == rt.Name {
+			return fmt.Errorf("route %v already added", rt.Name)
+		}
+	}
+
+	registered := &route{Route: rt}
+	if rt.Concurrency > 0 {
+		registered.slots = make(chan struct{}, rt.Concurrency)
+	}
+
+	r.routes = append(r.routes, registered)
+
+	return nil
+}
+
+// Handle - hands msg to the first route matching it, or to the fallback
+// a message waiting for a slot of its route frees its worker of the client meanwhile, so other routes aren't held up
+// and is released without counting as a failed attempt if ctx is done before it gets one
+func (r *Router) Handle(ctx context.Context, msg *Message) error {
+	for _, rt := range r.routes {
+		if !rt.Matcher.Match(msg) {
+			continue
+		}
+
+		messagesRouted.WithLabelValues(rt.Name).Inc()
+
+		if rt.slots != nil {
+			if err := rt.acquire(ctx); err != nil {
+				return err
+			}
+			defer func() { <-rt.slots }()
+		}
+
+		return rt.Handler.Handle(ctx, msg)
+	}
+
+	if r.Fallback == nil {
+		messagesRouted.WithLabelValues(unroutedRoute).Inc()
+		return Permanent(ErrNoRoute)
+	}
+
+	messagesRouted.WithLabelValues(fallbackRoute).Inc()
+
+	return r.Fallback.Handle(ctx, msg)
+}
@@ -0,0 +1,180 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRouter(t *testing.T) {
+	handled := ""
+	handler := func(name string) Handler {
+		return HandlerFunc(func(ctx context.Context, msg *Message) error {
+			handled = name
+			return nil
+		})
+	}
+
+	byType, err := JSONFieldEquals("$.detail.type", "refund")
+	require.NoError(t, err)
+	byPattern, err := BodyMatches(`^ping`)
+	require.NoError(t, err)
+
+	router := NewRouter(handler("fallback"))
+	require.NoError(t, router.Add(Route{Name: "orders", Matcher: AttributeEquals("type", "order"), Handler: handler("orders")}))
+	require.NoError(t, router.Add(Route{Name: "refunds", Matcher: byType, Handler: handler("refunds")}))
+	require.NoError(t, router.Add(Route{Name: "pings", Matcher: byPattern, Handler: handler("pings")}))
+
+	testCases := map[string]struct {
+		msg  *Message
+		want string
+	}{
+		"attribute": {
+			msg:  &Message{Body: `{"detail":{"type":"refund"}}`, Attributes: map[string]string{"type": "order"}},
+			want: "orders",
+		},
+		"json field": {
+			msg:  &Message{Body: `{"detail":{"type":"refund"}}`, Attributes: map[string]string{"type": "payment"}},
+			want: "refunds",
+		},
+		"body pattern": {
+			msg:  &Message{Body: "ping 1"},
+			want: "pings",
+		},
+		"fallback": {
+			msg:  &Message{Body: `{"detail":{"type":"order"}}`},
+			want: "fallback",
+		},
+	}
+
+	for name, tc := range testCases {
+		handled = ""
+		require.NoError(t, router.Handle(context.Background(), tc.msg), name)
+		require.Equal(t, tc.want, handled, name)
+	}
+
+	// without a fallback, unmatched messages are dead-lettered
+	router.Fallback = nil
+	err = router.Handle(context.Background(), &Message{Body: "pong"})
+	require.True(t, IsPermanent(err))
+	require.ErrorIs(t, err, ErrNoRoute)
+}
+
+func TestRouterAdd(t *testing.T) {
+	router := NewRouter(nil)
+	matcher := AttributeEquals("type", "order")
+
+	require.NoError(t, router.Add(Route{Name: "orders", Matcher: matcher, Handler: noopHandler}))
+
+	testCases := map[string]Route{
+		"no matcher":           {Name: "payments", Handler: noopHandler},
+		"no handler":           {Name: "payments", Matcher: matcher},
+		"no name":              {Matcher: matcher, Handler: noopHandler},
+		"reserved name":        {Name: fallbackRoute, Matcher: matcher, Handler: noopHandler},
+		"duplicate name":       {Name: "orders", Matcher: matcher, Handler: noopHandler},
+		"negative concurrency": {Name: "payments", Matcher: matcher, Handler: noopHandler, Concurrency: -1},
+	}
+
+	for name, rt := range testCases {
+		require.Error(t, router.Add(rt), name)
+	}
+
+	_, err := JSONFieldEquals("detail..type", "refund")
+	require.Error(t, err)
+	_, err = BodyMatches("(")
+	require.Error(t, err)
+}
+
+func TestRouteConfigs(t *testing.T) {
+	var routes RouteConfigs
+	require.NoError(t, routes.Decode(`[{name: orders, attribute: type, value: order, concurrency: 2},
+		{"name": "refunds", "handler": "payments", "jsonPath": "$.detail.type", "value": "refund"}, {name: pings, bodyPattern: "^ping"}]`))
+	require.NoError(t, routes.Validate())
+
+	handled := ""
+	handler := func(name string) Handler {
+		return HandlerFunc(func(ctx context.Context, msg *Message) error {
+			handled = name
+			return nil
+		})
+	}
+
+	router, err := routes.Router(map[string]Handler{"orders": handler("orders"), "payments": handler("payments"), "pings": handler("pings")},
+		handler("fallback"))
+	require.NoError(t, err)
+
+	testCases := map[string]struct {
+		msg  *Message
+		want string
+	}{
+		"by attribute":    {msg: &Message{Body: "{}", Attributes: map[string]string{"type": "order"}}, want: "orders"},
+		"by handler name": {msg: &Message{Body: `{"detail":{"type":"refund"}}`}, want: "payments"},
+		"by pattern":      {msg: &Message{Body: "ping"}, want: "pings"},
+		"fallback":        {msg: &Message{Body: "pong"}, want: "fallback"},
+	}
+
+	for name, tc := range testCases {
+		require.NoError(t, router.Handle(context.Background(), tc.msg), name)
+		require.Equal(t, tc.want, handled, name)
+	}
+
+	// handlers are registered by the program, so a route naming another one fails
+	_, err = routes.Router(map[string]Handler{"orders": noopHandler}, nil)
+	require.Error(t, err)
+
+	invalid := map[string]RouteConfigs{
+		"no matcher":       {{Name: "orders"}},
+		"two matchers":     {{Name: "orders", Attribute: "type", BodyPattern: "order"}},
+		"invalid pattern":  {{Name: "orders", BodyPattern: "("}},
+		"invalid path":     {{Name: "orders", JSONPath: "detail..type"}},
+		"no name":          {{Attribute: "type", Value: "order"}},
+		"duplicate name":   {{Name: "orders", Attribute: "type"}, {Name: "orders", BodyPattern: "order"}},
+		"negative workers": {{Name: "orders", Attribute: "type", Concurrency: -1}},
+	}
+
+	for name, routes := range invalid {
+		require.Error(t, routes.Validate(), name)
+	}
+
+	require.Error(t, routes.Decode("name: orders"))
+}
+
+func TestRouterConcurrency(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+
+	router := NewRouter(nil)
+	require.NoError(t, router.Add(Route{Name: "slow", Matcher: MatcherFunc(func(msg *Message) bool { return true }), Concurrency: 1,
+		Handler: HandlerFunc(func(ctx context.Context, msg *Message) error {
+			started <- struct{}{}
+			<-release
+			return nil
+		})}))
+
+	done := make(chan error)
+	go func() {
+		done <- router.Handle(context.Background(), &Message{})
+	}()
+	<-started
+
+	// the route's only slot is taken so the second message waits until its context is done
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	err := router.Handle(ctx, &Message{})
+	require.True(t, errors.Is(err, context.DeadlineExceeded))
+	require.ErrorIs(t, err, errNotHandled)
+	require.False(t, IsPermanent(err))
+
+	close(release)
+	require.NoError(t, <-done)
+
+	// the slot is free again once the first message is handled
+	go func() {
+		done <- router.Handle(context.Background(), &Message{})
+	}()
+	<-started
+	require.NoError(t, <-done)
+}
@@ -0,0 +1,329 @@
+package config
+
+// package used by sqsservice and client to read their settings from a yaml or json file
+// environment variables win over the file, which wins over the defaults of the envconfig tags
+// settings implementing envconfig.Decoder get lists of objects as json, e.g. the client's routes
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/kelseyhightower/envconfig"
+	"gopkg.in/yaml.v3"
+)
+
+// usageTemplate - lists the field name, key, alternative key, default and required tag of every setting
+var usageTemplate = template.Must(template.New("keys").Parse(
+	"{{range .}}{{.Name}}\t{{.Key}}\t{{.Alt}}\t{{.Tags.Get \"default\"}}\t{{.Tags.Get \"required\"}}\n{{end}}"))
+
+// setting - field of the spec and the environment variables it's read from
+type setting struct {
+	name     string
+	keys     []string
+	def      string
+	required bool
+}
+
+// FileKey - returns the environment variable holding the path of the config file, e.g. APP_CONFIG_FILE
+func FileKey(prefix string) string {
+	if prefix == "" {
+		return "CONFIG_FILE"
+	}
+
+	return strings.ToUpper(prefix) + "_CONFIG_FILE"
+}
+
+// Load - fills spec, a pointer to an envconfig struct, from the environment variables and the config file
+// the file is named by the FileKey variable and is optional
+// its keys are the spec's field names in any case, with or without underscores, e.g. queueName or queue_name
+// nested keys are joined, so prefetch: {workers: 2} sets PrefetchWorkers
+func Load(prefix string, spec interface{}) error {
+	return LoadFile(prefix, spec, os.Getenv(FileKey(prefix)))
+}
+
+// LoadFile - fills spec from the environment variables and the config file at path; path is optional
+// every setting takes the value of its environment variable, else of the file, else its default
+func LoadFile(prefix string, spec interface{}, path string) error {
+	if path == "" {
+		return envconfig.Process(prefix, spec)
+	}
+
+	values, err := readFile(path)
+	if err != nil {
+		return err
+	}
+
+	settings, err := gatherSettings(prefix, spec)
+	if err != nil {
+		return err
+	}
+
+	fileValues := make(map[string]string)
+	for _, s := range settings {
+		if value, ok := values[normalize(s.name)]; ok {
+			fileValues[s.name] = value
+			delete(values, normalize(s.name))
+		}
+	}
+
+	if len(values) > 0 {
+		unknown := make([]string, 0, len(values))
+		for key := range values {
+			unknown = append(unknown, key)
+		}
+		sort.Strings(unknown)
+
+		return fmt.Errorf("unknown settings in %v: %v", path, strings.Join(unknown, ", "))
+	}
+
+	// the values are decoded into spec directly so the process' environment is never changed
+	target := reflect.ValueOf(spec).Elem()
+	for _, s := range settings {
+		value, ok := lookupEnv(s.keys)
+		if !ok {
+			value, ok = fileValues[s.name]
+		}
+		if !ok && s.def != "" {
+			value, ok = s.def, true
+		}
+
+		if !ok {
+			if s.required {
+				return fmt.Errorf("required key %v missing value", s.keys[0])
+			}
+			continue
+		}
+
+		if err := decode(target.FieldByName(s.name), value); err != nil {
+			return fmt.Errorf("invalid value of %v: %w", s.keys[0], err)
+		}
+	}
+
+	return nil
+}
+
+// gatherSettings - returns the settings of spec as envconfig reads them
+func gatherSettings(prefix string, spec interface{}) ([]setting, error) {
+	buf := &bytes.Buffer{}
+	if err := envconfig.Usaget(prefix, spec, buf, usageTemplate); err != nil {
+		return nil, err
+	}
+
+	settings := make([]setting, 0)
+	for _, line := range strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n") {
+		fields := strings.Split(line, "\t")
+		if len(fields) != 5 {
+			continue
+		}
+
+		required, _ := strconv.ParseBool(fields[4])
+
+		s := setting{name: fields[0], keys: []string{fields[1]}, def: fields[3], required: required}
+		if fields[2] != "" && fields[2] != fields[1] {
+			s.keys = append(s.keys, fields[2])
+		}
+		settings = append(settings, s)
+	}
+
+	return settings, nil
+}
+
+// decode - sets field to value, parsed the way envconfig parses the environment
+func decode(field reflect.Value, value string) error {
+	if !field.CanSet() {
+		return fmt.Errorf("unsupported field: %v", field.Type())
+	}
+
+	// structured settings, e.g. lists of objects, decode themselves
+	if decoder, ok := field.Addr().Interface().(envconfig.Decoder); ok {
+		return decoder.Decode(value)
+	}
+
+	if field.Type() == reflect.TypeOf(time.Duration(0)) {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		field.SetInt(int64(d))
+		return nil
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		field.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(value, 0, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(value, 0, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(value, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetFloat(f)
+	case reflect.Slice:
+		items := strings.Split(value, ",")
+		if strings.TrimSpace(value) == "" {
+			items = nil
+		}
+
+		slice := reflect.MakeSlice(field.Type(), len(items), len(items))
+		for i, item := range items {
+			if err := decode(slice.Index(i), item); err != nil {
+				return err
+			}
+		}
+		field.Set(slice)
+	default:
+		return fmt.Errorf("unsupported field: %v", field.Type())
+	}
+
+	return nil
+}
+
+// lookupEnv - returns the value of the first of keys set in the environment
+func lookupEnv(keys []string) (string, bool) {
+	for _, key := range keys {
+		if value, ok := os.LookupEnv(key); ok {
+			return value, true
+		}
+	}
+
+	return "", false
+}
+
+// normalize - returns the name a setting is matched by, ignoring case, underscores and dashes
+func normalize(name string) string {
+	return strings.ToLower(strings.NewReplacer("_", "", "-", "").Replace(name))
+}
+
+// readFile - returns the file's values as environment variable values by normalized key
+// yaml is a superset of json so both are read the same way
+func readFile(path string) (map[string]string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var document map[string]interface{}
+	if err := yaml.Unmarshal(content, &document); err != nil {
+		return nil, fmt.Errorf("invalid config file %v: %w", path, err)
+	}
+
+	values := make(map[string]string)
+	flatten(values, "", document)
+
+	return values, nil
+}
+
+// flatten - joins the keys of nested maps and formats the values the way envconfig parses them
+func flatten(values map[string]string, prefix string, document map[string]interface{}) {
+	for key, value := range document {
+		name := prefix + normalize(key)
+
+		switch v := value.(type) {
+		case nil:
+		case map[string]interface{}:
+			flatten(values, name, v)
+		case []interface{}:
+			if structured(v) {
+				// left as json for the setting to decode, since the items can't be joined
+				content, _ := json.Marshal(v)
+				values[name] = string(content)
+				continue
+			}
+
+			items := make([]string, 0, len(v))
+			for _, item := range v {
+				items = append(items, fmt.Sprint(item))
+			}
+			values[name] = strings.Join(items, ",")
+		default:
+			values[name] = fmt.Sprint(v)
+		}
+	}
+}
+
+// structured - whether any item of the list is a map or a list itself
+func structured(list []interface{}) bool {
+	for _, item := range list {
+		switch item.(type) {
+		case map[string]interface{}, []interface{}:
+			return true
+		}
+	}
+
+	return false
+}
+
+// Changed - returns the names of the fields whose values differ between two specs of the same type
+func Changed(old interface{}, new interface{}) []string {
+	oldValue, newValue := reflect.Indirect(reflect.ValueOf(old)), reflect.Indirect(reflect.ValueOf(new))
+
+	changed := make([]string, 0)
+	for i := 0; i < oldValue.NumField(); i++ {
+		if !reflect.DeepEqual(oldValue.Field(i).Interface(), newValue.Field(i).Interface()) {
+			changed = append(changed, oldValue.Type().Field(i).Name)
+		}
+	}
+
+	return changed
+}
+
+// Watch - calls reload whenever the file at path changes until ctx is done
+// the file is checked every interval; the check compares its modification time and size
+func Watch(ctx context.Context, path string, interval time.Duration, reload func()) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	modTime, size := stat(path)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		// configmaps are updated by swapping a symlink, which changes the modification time of the target
+		newModTime, newSize := stat(path)
+		if newModTime.Equal(modTime) && newSize == size {
+			continue
+		}
+		modTime, size = newModTime, newSize
+
+		reload()
+	}
+}
+
+func stat(path string) (time.Time, int64) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}, -1
+	}
+
+	return info.ModTime(), info.Size()
+}
@@ -0,0 +1,172 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// testRoutes - structured setting decoding its json value
+type testRoutes []struct {
+	Name string `json:"name"`
+}
+
+func (r *testRoutes) Decode(value string) error {
+	return json.Unmarshal([]byte(value), r)
+}
+
+type testSpec struct {
+	QueueName       string   `split_words:"true" default:"queue"`
+	PollingInterval int      `split_words:"true" default:"5"`
+	ErrorRatioLimit float64  `split_words:"true" default:"0"`
+	UseStream       bool     `split_words:"true" default:"false"`
+	RedactFields    []string `split_words:"true" default:"messageBody"`
+	TLSCertFile     string   `envconfig:"TLS_CERT_FILE"`
+	Secret          string   `required:"true"`
+	Routes          testRoutes
+}
+
+func TestLoadFile(t *testing.T) {
+	dir := t.TempDir()
+
+	testCases := map[string]struct {
+		content string
+		env     map[string]string
+		want    testSpec
+		wantErr bool
+	}{
+		"yaml file": {
+			content: "queueName: orders\npolling_interval: 10\nerror-ratio-limit: 0.5\nuseStream: true\nredactFields: [messageBody, reason]\ntlsCertFile: cert.pem\nsecret: file\n",
+			want: testSpec{QueueName: "orders", PollingInterval: 10, ErrorRatioLimit: 0.5, UseStream: true,
+				RedactFields: []string{"messageBody", "reason"}, TLSCertFile: "cert.pem", Secret: "file"},
+		},
+		"json file": {
+			content: `{"queueName": "orders", "secret": "file"}`,
+			want:    testSpec{QueueName: "orders", PollingInterval: 5, RedactFields: []string{"messageBody"}, Secret: "file"},
+		},
+		"nested keys": {
+			content: "queue:\n  name: orders\npolling:\n  interval: 10\nsecret: file\n",
+			want:    testSpec{QueueName: "orders", PollingInterval: 10, RedactFields: []string{"messageBody"}, Secret: "file"},
+		},
+		"environment over file": {
+			content: "queueName: orders\ntlsCertFile: cert.pem\n",
+			env:     map[string]string{"TEST_QUEUE_NAME": "payments", "TLS_CERT_FILE": "other.pem", "TEST_SECRET": "env"},
+			want:    testSpec{QueueName: "payments", PollingInterval: 5, RedactFields: []string{"messageBody"}, TLSCertFile: "other.pem", Secret: "env"},
+		},
+		"invalid environment value": {
+			content: "pollingInterval: 10\nsecret: file\n",
+			env:     map[string]string{"TEST_POLLING_INTERVAL": "often"},
+			wantErr: true,
+		},
+		"structured setting": {
+			content: "routes:\n  - name: orders\n  - name: refunds\nsecret: file\n",
+			want: testSpec{QueueName: "queue", PollingInterval: 5, RedactFields: []string{"messageBody"}, Secret: "file",
+				Routes: testRoutes{{Name: "orders"}, {Name: "refunds"}}},
+		},
+		"structured setting in the environment": {
+			content: "secret: file\n",
+			env:     map[string]string{"TEST_ROUTES": `[{"name": "payments"}]`},
+			want: testSpec{QueueName: "queue", PollingInterval: 5, RedactFields: []string{"messageBody"}, Secret: "file",
+				Routes: testRoutes{{Name: "payments"}}},
+		},
+		"unknown setting": {
+			content: "queueName: orders\nqueueNmae: orders\nsecret: file\n",
+			wantErr: true,
+		},
+		"invalid value": {
+			content: "pollingInterval: often\nsecret: file\n",
+			wantErr: true,
+		},
+		"missing required setting": {
+			content: "queueName: orders\n",
+			wantErr: true,
+		},
+		"invalid file": {
+			content: "queueName: [orders\n",
+			wantErr: true,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			for key, value := range tc.env {
+				t.Setenv(key, value)
+			}
+
+			path := filepath.Join(dir, "config.yaml")
+			require.NoError(t, os.WriteFile(path, []byte(tc.content), 0600))
+
+			var spec testSpec
+			err := LoadFile("test", &spec, path)
+			if tc.wantErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tc.want, spec)
+
+			// the file's values don't leak into the environment
+			_, ok := os.LookupEnv("TEST_POLLING_INTERVAL")
+			require.False(t, ok)
+			_, ok = os.LookupEnv("TEST_SECRET")
+			require.Equal(t, tc.env["TEST_SECRET"] != "", ok)
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("queueName: orders\nsecret: file\n"), 0600))
+
+	t.Setenv(FileKey("test"), path)
+
+	var spec testSpec
+	require.NoError(t, Load("test", &spec))
+	require.Equal(t, "orders", spec.QueueName)
+
+	// without a file only the environment is read
+	t.Setenv(FileKey("test"), "")
+	require.Error(t, Load("test", &testSpec{}))
+}
+
+func TestChanged(t *testing.T) {
+	old := testSpec{QueueName: "orders", RedactFields: []string{"messageBody"}}
+	new := testSpec{QueueName: "payments", RedactFields: []string{"messageBody", "reason"}}
+
+	require.Equal(t, []string{"QueueName", "RedactFields"}, Changed(old, &new))
+	require.Empty(t, Changed(old, old))
+}
+
+func TestWatch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("queueName: orders\n"), 0600))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	reloads := make(chan struct{}, 10)
+	go Watch(ctx, path, 10*time.Millisecond, func() {
+		reloads <- struct{}{}
+	})
+
+	// an unchanged file isn't reloaded
+	select {
+	case <-reloads:
+		t.Fatal("unexpected reload")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	require.NoError(t, os.WriteFile(path, []byte("queueName: payments\n"), 0600))
+
+	select {
+	case <-reloads:
+	case <-time.After(time.Second):
+		t.Fatal("file change not detected")
+	}
+}
@@ -0,0 +1,183 @@
+package logging
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// formats logs are written in
+const (
+	FormatJSON    = "json"
+	FormatConsole = "console"
+)
+
+var (
+	configuredMu sync.Mutex
+	// level set by the environment or config file, which the debug toggle switches back to
+	configuredLevel = zerolog.InfoLevel.String()
+)
+
+type Config struct {
+	// trace, debug, info, warn, error, fatal, panic or disabled
+	Level string
+	// json or console
+	Format string
+	// number of identical messages logged per SamplePeriod below the warn level; 0 disables sampling
+	SampleBurst  int
+	SamplePeriod time.Duration
+}
+
+// NewLogger - creates the logger of a binary writing to w
+// the level is set globally so SetLevel changes it for every logger at runtime
+func NewLogger(w io.Writer, config Config) (zerolog.Logger, error) {
+	if err := SetConfiguredLevel(config.Level); err != nil {
+		return zerolog.Nop(), err
+	}
+
+	switch config.Format {
+	case FormatJSON, "":
+	case FormatConsole:
+		w = zerolog.ConsoleWriter{Out: w, TimeFormat: time.RFC3339}
+	default:
+		return zerolog.Nop(), fmt.Errorf("unknown log format: %v", config.Format)
+	}
+
+	logger := zerolog.New(w).With().Timestamp().Logger()
+
+	if config.SampleBurst > 0 {
+		logger = logger.Hook(NewSampler(config.SampleBurst, config.SamplePeriod))
+	}
+
+	return logger, nil
+}
+
+// SetLevel - changes the level of every logger
+func SetLevel(level string) error {
+	parsed, err := zerolog.ParseLevel(level)
+	if err != nil {
+		return err
+	}
+
+	// an empty level parses as NoLevel, which would log everything
+	if parsed == zerolog.NoLevel {
+		parsed = zerolog.InfoLevel
+	}
+
+	zerolog.SetGlobalLevel(parsed)
+
+	return nil
+}
+
+// SetConfiguredLevel - changes the level of every logger to the one set by the environment or config file
+// unlike SetLevel, it's also the level the debug toggle switches back to
+func SetConfiguredLevel(level string) error {
+	configuredMu.Lock()
+	defer configuredMu.Unlock()
+
+	if err := SetLevel(level); err != nil {
+		return err
+	}
+	configuredLevel = level
+
+	return nil
+}
+
+// ConfiguredLevel - returns the level last set by SetConfiguredLevel
+func ConfiguredLevel() string {
+	configuredMu.Lock()
+	defer configuredMu.Unlock()
+
+	return configuredLevel
+}
+
+// Level - returns the current level of every logger
+func Level() string {
+	return zerolog.GlobalLevel().String()
+}
+
+// Sampler - hook dropping the repetitions of a message over burst within a period
+// warnings and errors are never dropped
+type Sampler struct {
+	Burst  int
+	Period time.Duration
+
+	mu sync.Mutex
+	// level and message -> number of times it was logged in the current period
+	counts      map[string]int
+	periodStart time.Time
+}
+
+// NewSampler - creates new Sampler; period defaults to a second
+func NewSampler(burst int, period time.Duration) *Sampler {
+	if period <= 0 {
+		period = time.Second
+	}
+
+	return &Sampler{Burst: burst, Period: period, counts: make(map[string]int)}
+}
+
+func (s *Sampler) Run(e *zerolog.Event, level zerolog.Level, msg string) {
+	if level >= zerolog.WarnLevel {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// the counts are dropped every period so messages with changing values don't pile up
+	if now := time.Now(); now.Sub(s.periodStart) >= s.Period {
+		s.counts = make(map[string]int)
+		s.periodStart = now
+	}
+
+	key := level.String() + msg
+	s.counts[key]++
+
+	if s.counts[key] > s.Burst {
+		e.Discard()
+	}
+}
+
+// ToggleDebugOnSignal - switches every logger between debug and the configured level each time one of the signals is received
+// e.g. on SIGUSR1 sent with kubectl exec; returns a function that stops listening
+// the configured level is read when switching back, so it's the one of the latest config reload
+func ToggleDebugOnSignal(logger zerolog.Logger, signals ...os.Signal) func() {
+	signalChan := make(chan os.Signal, 1)
+	signal.Notify(signalChan, signals...)
+
+	done := make(chan struct{})
+
+	go func() {
+		for {
+			select {
+			case <-done:
+				return
+			case <-signalChan:
+			}
+
+			next := zerolog.DebugLevel.String()
+			if zerolog.GlobalLevel() <= zerolog.DebugLevel {
+				next = ConfiguredLevel()
+			}
+
+			if err := SetLevel(next); err != nil {
+				logger.Error().Err(err).Msg("Failed to change log level")
+				continue
+			}
+
+			// logged at warn so it shows whatever the new level is
+			logger.Warn().Str("level", Level()).Msg("Changed log level")
+		}
+	}()
+
+	return func() {
+		signal.Stop(signalChan)
+		close(done)
+	}
+}
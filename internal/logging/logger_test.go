@@ -0,0 +1,134 @@
+package logging
+
+import (
+	"bytes"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewLogger(t *testing.T) {
+	defer zerolog.SetGlobalLevel(zerolog.GlobalLevel())
+
+	testCases := map[string]struct {
+		config  Config
+		err     bool
+		written bool
+		json    bool
+	}{
+		"json": {
+			config:  Config{Level: "info", Format: FormatJSON},
+			written: true,
+			json:    true,
+		},
+		"console": {
+			config:  Config{Level: "debug", Format: FormatConsole},
+			written: true,
+		},
+		"level above the message": {
+			config: Config{Level: "warn"},
+		},
+		"unknown level": {
+			config: Config{Level: "verbose"},
+			err:    true,
+		},
+		"unknown format": {
+			config: Config{Level: "info", Format: "xml"},
+			err:    true,
+		},
+	}
+
+	for name, tc := range testCases {
+		buf := &bytes.Buffer{}
+
+		logger, err := NewLogger(buf, tc.config)
+		if tc.err {
+			require.Error(t, err, name)
+			continue
+		}
+		require.NoError(t, err, name)
+
+		logger.Info().Msg("hello")
+
+		require.Equal(t, tc.written, buf.Len() > 0, name)
+		if tc.written {
+			require.Equal(t, tc.json, bytes.HasPrefix(buf.Bytes(), []byte("{")), name)
+		}
+	}
+}
+
+func TestSetLevel(t *testing.T) {
+	defer zerolog.SetGlobalLevel(zerolog.GlobalLevel())
+
+	require.NoError(t, SetLevel("debug"))
+	require.Equal(t, "debug", Level())
+
+	// empty means the default level
+	require.NoError(t, SetLevel(""))
+	require.Equal(t, "info", Level())
+
+	require.Error(t, SetLevel("verbose"))
+	require.Equal(t, "info", Level())
+}
+
+func TestSampler(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := zerolog.New(buf).Hook(NewSampler(2, time.Hour))
+
+	for i := 0; i < 5; i++ {
+		logger.Info().Msg("Polling")
+		logger.Info().Msg("Received message")
+		logger.Warn().Msg("Failed to poll")
+	}
+
+	lines := logLines(t, buf)
+
+	counts := make(map[string]int)
+	for _, line := range lines {
+		counts[line["message"].(string)]++
+	}
+
+	// warnings are never dropped
+	require.Equal(t, map[string]int{"Polling": 2, "Received message": 2, "Failed to poll": 5}, counts)
+
+	// the counts start over every period
+	sampler := NewSampler(1, 100*time.Millisecond)
+	buf.Reset()
+	logger = zerolog.New(buf).Hook(sampler)
+
+	logger.Info().Msg("Polling")
+	logger.Info().Msg("Polling")
+	time.Sleep(200 * time.Millisecond)
+	logger.Info().Msg("Polling")
+
+	require.Len(t, logLines(t, buf), 2)
+}
+
+func TestToggleDebugOnSignal(t *testing.T) {
+	defer zerolog.SetGlobalLevel(zerolog.GlobalLevel())
+	defer SetConfiguredLevel(ConfiguredLevel())
+	require.NoError(t, SetConfiguredLevel("info"))
+
+	stop := ToggleDebugOnSignal(zerolog.Nop(), syscall.SIGUSR1)
+	defer stop()
+
+	require.NoError(t, syscall.Kill(syscall.Getpid(), syscall.SIGUSR1))
+	require.Eventually(t, func() bool { return Level() == "debug" }, time.Second, time.Millisecond)
+
+	require.NoError(t, syscall.Kill(syscall.Getpid(), syscall.SIGUSR1))
+	require.Eventually(t, func() bool { return Level() == "info" }, time.Second, time.Millisecond)
+
+	// a reload while debugging changes the level the toggle switches back to
+	require.NoError(t, syscall.Kill(syscall.Getpid(), syscall.SIGUSR1))
+	require.Eventually(t, func() bool { return Level() == "debug" }, time.Second, time.Millisecond)
+
+	configuredMu.Lock()
+	configuredLevel = "warn"
+	configuredMu.Unlock()
+
+	require.NoError(t, syscall.Kill(syscall.Getpid(), syscall.SIGUSR1))
+	require.Eventually(t, func() bool { return Level() == "warn" }, time.Second, time.Millisecond)
+}
@@ -0,0 +1,275 @@
+package logging
+
+// package used by sqsservice to log every call it handles
+// calls are identified by a request id that's propagated from the caller or assigned by the interceptor
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+const (
+	packageName = "logging"
+
+	// metadata key the request id is read from and returned in
+	RequestIDKey = "x-request-id"
+
+	// longest request id accepted from a caller; longer ones are replaced
+	maxRequestIDLength = 128
+	// name of the fields logged as message ids
+	messageIDField = "messageID"
+)
+
+type requestIDKey struct{}
+
+// RequestID - returns the request id the interceptor assigned to the call
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// WithRequestID - returns logger with the request id of the call, if any
+func WithRequestID(ctx context.Context, logger zerolog.Logger) zerolog.Logger {
+	id := RequestID(ctx)
+	if id == "" {
+		return logger
+	}
+
+	return logger.With().Str("requestID", id).Logger()
+}
+
+// NewRequestID - returns a random request id
+func NewRequestID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return ""
+	}
+
+	return hex.EncodeToString(b)
+}
+
+// Interceptor - logs the method, duration, status code and message ids of every call
+// the request and response are logged at debug level once redacted
+type Interceptor struct {
+	Redactor *Redactor
+	// services whose calls are only logged at debug level, e.g. grpc.health.v1.Health polled by kubelet probes
+	QuietServices []string
+	Logger        zerolog.Logger
+}
+
+// NewInterceptor - creates new Interceptor
+func NewInterceptor(logger zerolog.Logger, redactor *Redactor) *Interceptor {
+	return &Interceptor{
+		Redactor: redactor,
+		Logger:   logger.With().Str("package", packageName).Logger(),
+	}
+}
+
+// requestID - returns the context carrying the caller's request id, or a new one if the caller sent none
+// the id is sent back in the response headers
+func requestID(ctx context.Context) (context.Context, string) {
+	id := ""
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(RequestIDKey); len(values) > 0 {
+			id = values[0]
+		}
+	}
+
+	if id == "" || len(id) > maxRequestIDLength {
+		id = NewRequestID()
+	}
+
+	// fails outside of grpc calls, e.g. for calls of the gateway, which returns the id itself
+	_ = grpc.SetHeader(ctx, metadata.Pairs(RequestIDKey, id))
+
+	return context.WithValue(ctx, requestIDKey{}, id), id
+}
+
+// callLogger - returns the logger of the call
+func (i *Interceptor) callLogger(ctx context.Context, fullMethod string, id string) zerolog.Logger {
+	c := i.Logger.With().Str("method", fullMethod).Str("requestID", id)
+
+	if spanContext := trace.SpanContextFromContext(ctx); spanContext.IsValid() {
+		c = c.Str("traceID", spanContext.TraceID().String())
+	}
+
+	return c.Logger()
+}
+
+// event - returns the event the end of a call is logged with; failed calls are logged as warnings or errors
+func (i *Interceptor) event(l *zerolog.Logger, fullMethod string, err error) *zerolog.Event {
+	switch status.Code(err) {
+	case codes.OK:
+		for _, service := range i.QuietServices {
+			if strings.HasPrefix(fullMethod, "/"+service+"/") {
+				return l.Debug()
+			}
+		}
+
+		return l.Info()
+	case codes.Unknown, codes.Internal, codes.DataLoss, codes.Unavailable:
+		return l.Error().Err(err)
+	default:
+		return l.Warn().Err(err)
+	}
+}
+
+// payload - adds the redacted message to the event when debug logs are enabled
+func (i *Interceptor) payload(e *zerolog.Event, key string, m interface{}) *zerolog.Event {
+	if i.Redactor == nil || i.Logger.GetLevel() > zerolog.DebugLevel || zerolog.GlobalLevel() > zerolog.DebugLevel {
+		return e
+	}
+
+	if raw := i.Redactor.Redact(m); raw != nil {
+		e = e.RawJSON(key, raw)
+	}
+
+	return e
+}
+
+// Unary - interceptor for unary calls
+func (i *Interceptor) Unary() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, id := requestID(ctx)
+		l := i.callLogger(ctx, info.FullMethod, id)
+
+		start := time.Now()
+		resp, err := handler(ctx, req)
+
+		e := i.event(&l, info.FullMethod, err).
+			Dur("duration", time.Since(start)).
+			Str("code", status.Code(err).String())
+
+		ids := append(messageIDs(req), messageIDs(resp)...)
+		if len(ids) > 0 {
+			e = e.Strs("messageIDs", ids)
+		}
+
+		e = i.payload(e, "request", req)
+		if err == nil {
+			e = i.payload(e, "response", resp)
+		}
+
+		e.Msg("Handled call")
+
+		return resp, err
+	}
+}
+
+// Stream - interceptor for streaming calls
+// every streamed message is logged at debug level, and the call once it ends
+func (i *Interceptor) Stream() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, id := requestID(ss.Context())
+		l := i.callLogger(ctx, info.FullMethod, id)
+
+		stream := &loggingStream{ServerStream: ss, ctx: ctx, interceptor: i, logger: l}
+
+		start := time.Now()
+		err := handler(srv, stream)
+
+		i.event(&l, info.FullMethod, err).
+			Dur("duration", time.Since(start)).
+			Str("code", status.Code(err).String()).
+			Int("received", stream.received).
+			Int("sent", stream.sent).
+			Msg("Handled stream")
+
+		return err
+	}
+}
+
+// loggingStream - server stream logging the messages it carries
+type loggingStream struct {
+	grpc.ServerStream
+	ctx         context.Context
+	interceptor *Interceptor
+	logger      zerolog.Logger
+	// only used by the handler's goroutine
+	received, sent int
+}
+
+func (s *loggingStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *loggingStream) RecvMsg(m interface{}) error {
+	err := s.ServerStream.RecvMsg(m)
+	if err == nil {
+		s.received++
+		s.log("Received stream message", m)
+	}
+
+	return err
+}
+
+func (s *loggingStream) SendMsg(m interface{}) error {
+	err := s.ServerStream.SendMsg(m)
+	if err == nil {
+		s.sent++
+		s.log("Sent stream message", m)
+	}
+
+	return err
+}
+
+func (s *loggingStream) log(msg string, m interface{}) {
+	e := s.logger.Debug()
+	if !e.Enabled() {
+		return
+	}
+
+	if ids := messageIDs(m); len(ids) > 0 {
+		e = e.Strs("messageIDs", ids)
+	}
+
+	s.interceptor.payload(e, "message", m).Msg(msg)
+}
+
+// messageIDs - returns the values of every messageID field of the message and of the messages it holds
+func messageIDs(m interface{}) []string {
+	message, ok := m.(proto.Message)
+	if !ok || message == nil {
+		return nil
+	}
+
+	reflected := message.ProtoReflect()
+	if !reflected.IsValid() {
+		return nil
+	}
+
+	return appendMessageIDs(nil, reflected)
+}
+
+func appendMessageIDs(ids []string, m protoreflect.Message) []string {
+	m.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		switch {
+		case fd.IsList() && fd.Kind() == protoreflect.MessageKind:
+			list := v.List()
+			for i := 0; i < list.Len(); i++ {
+				ids = appendMessageIDs(ids, list.Get(i).Message())
+			}
+		case fd.IsMap():
+		case fd.Kind() == protoreflect.MessageKind:
+			ids = appendMessageIDs(ids, v.Message())
+		case fd.Kind() == protoreflect.StringKind && string(fd.Name()) == messageIDField:
+			ids = append(ids, v.String())
+		}
+
+		return true
+	})
+
+	return ids
+}
@@ -0,0 +1,241 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	pb "github.com/alvinlucillo/sqs-processor/protogen/sqs"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// logLines - decodes the json log lines written to buf
+func logLines(t *testing.T, buf *bytes.Buffer) []map[string]interface{} {
+	lines := make([]map[string]interface{}, 0)
+
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+
+		var fields map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(line), &fields))
+		lines = append(lines, fields)
+	}
+
+	return lines
+}
+
+func TestRedact(t *testing.T) {
+	testCases := map[string]struct {
+		fields  []string
+		pattern string
+		message interface{}
+		want    string
+	}{
+		"redacted fields": {
+			fields:  DefaultRedactedFields,
+			message: &pb.SQSDeadLetterMessageRequest{MessageID: "message-1", MessageBody: "jane@example.com", Reason: "invalid jane@example.com"},
+			want:    `{"messageID":"message-1","messageBody":"[REDACTED]","reason":"[REDACTED]"}`,
+		},
+		"fields matched by proto name": {
+			fields:  []string{"message_body"},
+			message: &pb.SQSSendMessageRequest{MessageBody: "secret"},
+			want:    `{"messageBody":"[REDACTED]"}`,
+		},
+		"nested fields": {
+			fields:  DefaultRedactedFields,
+			message: &pb.SQSReceiveMessageResponse{Messages: []*pb.SQSResponseMessage{{MessageID: "message-1", MessageBody: "secret"}}},
+			want:    `{"messages":[{"messageID":"message-1","messageBody":"[REDACTED]"}]}`,
+		},
+		"pattern": {
+			pattern: `[\w.]+@[\w.]+`,
+			message: &pb.SQSNackMessageRequest{MessageID: "id of jane@example.com"},
+			want:    `{"messageID":"id of [REDACTED]"}`,
+		},
+		"not a proto message": {
+			message: "jane@example.com",
+		},
+	}
+
+	for name, tc := range testCases {
+		redactor, err := NewRedactor(tc.fields, tc.pattern)
+		require.NoError(t, err, name)
+
+		got := redactor.Redact(tc.message)
+		if tc.want == "" {
+			require.Nil(t, got, name)
+			continue
+		}
+		require.JSONEq(t, tc.want, string(got), name)
+	}
+
+	_, err := NewRedactor(nil, "(")
+	require.Error(t, err)
+}
+
+func TestMessageIDs(t *testing.T) {
+	require.Equal(t, []string{"message-1"}, messageIDs(&pb.SQSDeleteMessageRequest{MessageID: "message-1"}))
+	require.Equal(t, []string{"message-1", "message-2"}, messageIDs(&pb.SQSReceiveMessageResponse{Messages: []*pb.SQSResponseMessage{
+		{MessageID: "message-1"}, {MessageID: "message-2"},
+	}}))
+	require.Empty(t, messageIDs(&pb.SQSReceiveMessageRequest{}))
+	require.Empty(t, messageIDs((*pb.SQSReceiveMessageResponse)(nil)))
+	require.Empty(t, messageIDs(nil))
+}
+
+func TestUnary(t *testing.T) {
+	testCases := map[string]struct {
+		incoming      metadata.MD
+		err           error
+		wantLevel     string
+		wantRequestID string
+	}{
+		"successful call": {
+			wantLevel: "info",
+		},
+		"propagated request id": {
+			incoming:      metadata.Pairs(RequestIDKey, "request-1"),
+			wantLevel:     "info",
+			wantRequestID: "request-1",
+		},
+		"rejected call": {
+			err:       status.Error(codes.PermissionDenied, "denied"),
+			wantLevel: "warn",
+		},
+		"failed call": {
+			err:       errors.New("failed deleting message"),
+			wantLevel: "error",
+		},
+	}
+
+	for name, tc := range testCases {
+		buf := &bytes.Buffer{}
+		redactor, err := NewRedactor(DefaultRedactedFields, "")
+		require.NoError(t, err, name)
+		interceptor := NewInterceptor(zerolog.New(buf).Level(zerolog.DebugLevel), redactor)
+
+		ctx := context.Background()
+		if tc.incoming != nil {
+			ctx = metadata.NewIncomingContext(ctx, tc.incoming)
+		}
+
+		req := &pb.SQSDeadLetterMessageRequest{MessageID: "message-1", MessageBody: "jane@example.com"}
+		var handlerRequestID string
+
+		_, err = interceptor.Unary()(ctx, req, &grpc.UnaryServerInfo{FullMethod: "/sqs.SQSService/DeadLetterMessage"},
+			func(ctx context.Context, req interface{}) (interface{}, error) {
+				handlerRequestID = RequestID(ctx)
+				return &pb.SQSReceiveMessageResponse{}, tc.err
+			})
+		require.Equal(t, tc.err, err, name)
+
+		lines := logLines(t, buf)
+		require.Len(t, lines, 1, name)
+		line := lines[0]
+
+		require.Equal(t, tc.wantLevel, line["level"], name)
+		require.Equal(t, "/sqs.SQSService/DeadLetterMessage", line["method"], name)
+		require.Equal(t, status.Code(tc.err).String(), line["code"], name)
+		require.Equal(t, []interface{}{"message-1"}, line["messageIDs"], name)
+		require.Contains(t, line, "duration", name)
+		require.NotEmpty(t, handlerRequestID, name)
+		require.Equal(t, handlerRequestID, line["requestID"], name)
+		if tc.wantRequestID != "" {
+			require.Equal(t, tc.wantRequestID, handlerRequestID, name)
+		}
+
+		// bodies never hit the logs
+		require.NotContains(t, buf.String(), "jane@example.com", name)
+		require.Equal(t, "[REDACTED]", line["request"].(map[string]interface{})["messageBody"], name)
+	}
+}
+
+func TestUnaryQuietServices(t *testing.T) {
+	buf := &bytes.Buffer{}
+	interceptor := NewInterceptor(zerolog.New(buf).Level(zerolog.InfoLevel), nil)
+	interceptor.QuietServices = []string{"grpc.health.v1.Health"}
+
+	_, err := interceptor.Unary()(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: "/grpc.health.v1.Health/Check"},
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			return nil, nil
+		})
+	require.NoError(t, err)
+	require.Empty(t, buf.String())
+}
+
+// fakeStream - server stream receiving the messages in recv and keeping the sent ones
+type fakeStream struct {
+	grpc.ServerStream
+	recv []*pb.SQSDeleteMessageRequest
+	sent []interface{}
+}
+
+func (s *fakeStream) Context() context.Context {
+	return context.Background()
+}
+
+func (s *fakeStream) SetHeader(metadata.MD) error {
+	return nil
+}
+
+func (s *fakeStream) RecvMsg(m interface{}) error {
+	if len(s.recv) == 0 {
+		return io.EOF
+	}
+
+	proto.Merge(m.(proto.Message), s.recv[0])
+	s.recv = s.recv[1:]
+
+	return nil
+}
+
+func (s *fakeStream) SendMsg(m interface{}) error {
+	s.sent = append(s.sent, m)
+	return nil
+}
+
+func TestStream(t *testing.T) {
+	buf := &bytes.Buffer{}
+	redactor, err := NewRedactor(DefaultRedactedFields, "")
+	require.NoError(t, err)
+	interceptor := NewInterceptor(zerolog.New(buf).Level(zerolog.DebugLevel), redactor)
+
+	ss := &fakeStream{recv: []*pb.SQSDeleteMessageRequest{{MessageID: "message-1"}}}
+
+	err = interceptor.Stream()(nil, ss, &grpc.StreamServerInfo{FullMethod: "/sqs.SQSService/StreamMessages"},
+		func(srv interface{}, stream grpc.ServerStream) error {
+			require.NotEmpty(t, RequestID(stream.Context()))
+
+			require.NoError(t, stream.SendMsg(&pb.SQSResponseMessage{MessageID: "message-1", MessageBody: "secret"}))
+			require.NoError(t, stream.RecvMsg(&pb.SQSDeleteMessageRequest{}))
+			require.Equal(t, io.EOF, stream.RecvMsg(&pb.SQSDeleteMessageRequest{}))
+
+			return nil
+		})
+	require.NoError(t, err)
+
+	lines := logLines(t, buf)
+	require.Len(t, lines, 3)
+
+	require.Equal(t, "Sent stream message", lines[0]["message"])
+	require.Equal(t, []interface{}{"message-1"}, lines[0]["messageIDs"])
+	require.Equal(t, "Received stream message", lines[1]["message"])
+
+	require.Equal(t, "Handled stream", lines[2]["message"])
+	require.Equal(t, float64(1), lines[2]["sent"])
+	require.Equal(t, float64(1), lines[2]["received"])
+	require.Equal(t, "OK", lines[2]["code"])
+
+	require.NotContains(t, buf.String(), "secret")
+}
@@ -0,0 +1,106 @@
+package logging
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	// replaces the redacted values
+	redacted = "[REDACTED]"
+)
+
+// DefaultRedactedFields - fields holding message contents, which can carry any PII
+var DefaultRedactedFields = []string{"messageBody", "reason"}
+
+// Redactor - removes sensitive values from the payloads written to the logs
+type Redactor struct {
+	// names of the fields whose values are always redacted, matched case-insensitively
+	// against the proto and json names, e.g. messageBody or message_body
+	Fields []string
+	// redacts the matching parts of every other string value, e.g. email addresses; unused if nil
+	Pattern *regexp.Regexp
+}
+
+// NewRedactor - creates new Redactor; pattern is a regular expression, unused if empty
+func NewRedactor(fields []string, pattern string) (*Redactor, error) {
+	redactor := &Redactor{Fields: fields}
+
+	if pattern != "" {
+		var err error
+		if redactor.Pattern, err = regexp.Compile(pattern); err != nil {
+			return nil, err
+		}
+	}
+
+	return redactor, nil
+}
+
+// Redact - returns the message as json with its sensitive values redacted
+// returns nil if the message isn't a proto message
+func (r *Redactor) Redact(m interface{}) json.RawMessage {
+	message, ok := m.(proto.Message)
+	if !ok {
+		return nil
+	}
+
+	// proto names so the fields match how the proto file spells them
+	raw, err := protojson.MarshalOptions{UseProtoNames: true}.Marshal(message)
+	if err != nil {
+		return nil
+	}
+
+	var value interface{}
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return nil
+	}
+
+	redactedRaw, err := json.Marshal(r.redact(value))
+	if err != nil {
+		return nil
+	}
+
+	return redactedRaw
+}
+
+// redact - walks the decoded json replacing sensitive values
+func (r *Redactor) redact(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, field := range v {
+			if r.redactedField(key) {
+				v[key] = redacted
+				continue
+			}
+
+			v[key] = r.redact(field)
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = r.redact(item)
+		}
+	case string:
+		if r.Pattern != nil {
+			return r.Pattern.ReplaceAllString(v, redacted)
+		}
+	}
+
+	return value
+}
+
+// redactedField - checks if the field's values are always redacted
+func (r *Redactor) redactedField(name string) bool {
+	normalized := strings.ReplaceAll(name, "_", "")
+
+	for _, field := range r.Fields {
+		if strings.EqualFold(normalized, strings.ReplaceAll(field, "_", "")) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,195 @@
+package metrics
+
+// package used by sqsservice and client to expose prometheus metrics
+// every package registers its own metrics with the default registry, so each binary only exposes the ones it uses
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+// RPC - counts and times grpc calls by method and status code
+type RPC struct {
+	Calls    *prometheus.CounterVec
+	Duration *prometheus.HistogramVec
+	InFlight *prometheus.GaugeVec
+}
+
+// NewRPC - creates the rpc metrics, e.g. sqsservice_rpc_calls_total for the sqsservice namespace
+func NewRPC(registerer prometheus.Registerer, namespace string) *RPC {
+	factory := promauto.With(registerer)
+
+	return &RPC{
+		Calls: factory.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "rpc_calls_total",
+			Help:      "Number of finished grpc calls by method and status code.",
+		}, []string{"method", "code"}),
+		Duration: factory.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "rpc_duration_seconds",
+			Help:      "Duration of grpc calls by method; streams last until they end.",
+			Buckets:   []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10, 20, 30, 60},
+		}, []string{"method"}),
+		InFlight: factory.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "rpc_in_flight",
+			Help:      "Number of grpc calls in progress by method.",
+		}, []string{"method"}),
+	}
+}
+
+// observe - records a finished call
+func (m *RPC) observe(method string, start time.Time, err error) {
+	m.Calls.WithLabelValues(method, status.Code(err).String()).Inc()
+	m.Duration.WithLabelValues(method).Observe(time.Since(start).Seconds())
+}
+
+// UnaryServerInterceptor - records the unary calls a server handles
+func (m *RPC) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		inFlight := m.InFlight.WithLabelValues(info.FullMethod)
+		inFlight.Inc()
+		defer inFlight.Dec()
+
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		m.observe(info.FullMethod, start, err)
+
+		return resp, err
+	}
+}
+
+// StreamServerInterceptor - records the streams a server handles once they end
+func (m *RPC) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		inFlight := m.InFlight.WithLabelValues(info.FullMethod)
+		inFlight.Inc()
+		defer inFlight.Dec()
+
+		start := time.Now()
+		err := handler(srv, ss)
+		m.observe(info.FullMethod, start, err)
+
+		return err
+	}
+}
+
+// UnaryClientInterceptor - records the unary calls a client makes
+func (m *RPC) UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		inFlight := m.InFlight.WithLabelValues(method)
+		inFlight.Inc()
+		defer inFlight.Dec()
+
+		start := time.Now()
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		m.observe(method, start, err)
+
+		return err
+	}
+}
+
+// StreamClientInterceptor - records the streams a client opens once they fail to open or end
+func (m *RPC) StreamClientInterceptor() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		start := time.Now()
+
+		cs, err := streamer(ctx, desc, cc, method, opts...)
+		if err != nil {
+			m.observe(method, start, err)
+			return nil, err
+		}
+
+		m.InFlight.WithLabelValues(method).Inc()
+
+		stream := &clientStream{ClientStream: cs, ended: make(chan struct{}), done: func(err error) {
+			m.InFlight.WithLabelValues(method).Dec()
+			m.observe(method, start, err)
+		}}
+
+		// a stream the client cancels without receiving its error again still ends
+		go func() {
+			select {
+			case <-ctx.Done():
+				stream.end(status.FromContextError(ctx.Err()).Err())
+			case <-stream.ended:
+			}
+		}()
+
+		return stream, nil
+	}
+}
+
+// clientStream - client stream calling done once when it ends
+type clientStream struct {
+	grpc.ClientStream
+	once  sync.Once
+	ended chan struct{}
+	done  func(err error)
+}
+
+func (s *clientStream) end(err error) {
+	s.once.Do(func() {
+		close(s.ended)
+		s.done(err)
+	})
+}
+
+func (s *clientStream) RecvMsg(m interface{}) error {
+	err := s.ClientStream.RecvMsg(m)
+
+	if err != nil {
+		// the server ending the stream is a successful call
+		callErr := err
+		if errors.Is(err, io.EOF) {
+			callErr = nil
+		}
+
+		s.end(callErr)
+	}
+
+	return err
+}
+
+// Server - serves /metrics with the metrics of the default registry
+type Server struct {
+	HTTPServer *http.Server
+	Listener   net.Listener
+}
+
+// NewServer - creates new Server serving on listener
+func NewServer(listener net.Listener) *Server {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+
+	return &Server{
+		HTTPServer: &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second},
+		Listener:   listener,
+	}
+}
+
+// Serve - serves until Shutdown is called
+func (s *Server) Serve() error {
+	if err := s.HTTPServer.Serve(s.Listener); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+
+	return nil
+}
+
+// Shutdown - stops serving once the open scrapes are done
+func (s *Server) Shutdown(ctx context.Context) error {
+	return s.HTTPServer.Shutdown(ctx)
+}
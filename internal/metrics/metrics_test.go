@@ -0,0 +1,150 @@
+package metrics
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	m := NewRPC(prometheus.NewRegistry(), "test")
+	interceptor := m.UnaryServerInterceptor()
+
+	testCases := map[string]struct {
+		err  error
+		code string
+	}{
+		"successful call": {
+			err:  nil,
+			code: "OK",
+		},
+		"failed call": {
+			err:  status.Error(codes.PermissionDenied, "denied"),
+			code: "PermissionDenied",
+		},
+		"failed call without status": {
+			err:  io.ErrUnexpectedEOF,
+			code: "Unknown",
+		},
+	}
+
+	for name, tc := range testCases {
+		info := &grpc.UnaryServerInfo{FullMethod: "/sqs.SQSService/" + name}
+
+		_, err := interceptor(context.Background(), nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+			require.Equal(t, float64(1), testutil.ToFloat64(m.InFlight.WithLabelValues(info.FullMethod)), name)
+			return nil, tc.err
+		})
+		require.Equal(t, tc.err, err, name)
+
+		require.Equal(t, float64(1), testutil.ToFloat64(m.Calls.WithLabelValues(info.FullMethod, tc.code)), name)
+		require.Equal(t, float64(0), testutil.ToFloat64(m.InFlight.WithLabelValues(info.FullMethod)), name)
+	}
+}
+
+// recvStream - client stream returning err from every RecvMsg
+type recvStream struct {
+	grpc.ClientStream
+	err error
+}
+
+func (s *recvStream) RecvMsg(m interface{}) error {
+	return s.err
+}
+
+func TestStreamClientInterceptor(t *testing.T) {
+	m := NewRPC(prometheus.NewRegistry(), "test")
+	interceptor := m.StreamClientInterceptor()
+
+	testCases := map[string]struct {
+		openErr error
+		recvErr error
+		cancel  bool
+		code    string
+	}{
+		"stream ended by the server": {
+			recvErr: io.EOF,
+			code:    "OK",
+		},
+		"broken stream": {
+			recvErr: status.Error(codes.Unavailable, "gone"),
+			code:    "Unavailable",
+		},
+		"stream cancelled by the client": {
+			cancel: true,
+			code:   "Canceled",
+		},
+		"stream not opened": {
+			openErr: status.Error(codes.Unauthenticated, "no token"),
+			code:    "Unauthenticated",
+		},
+	}
+
+	for name, tc := range testCases {
+		method := "/sqs.SQSService/" + name
+		ctx, cancel := context.WithCancel(context.Background())
+
+		cs, err := interceptor(ctx, &grpc.StreamDesc{}, nil, method,
+			func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+				if tc.openErr != nil {
+					return nil, tc.openErr
+				}
+				return &recvStream{err: tc.recvErr}, nil
+			})
+
+		if tc.openErr == nil {
+			require.NoError(t, err, name)
+			require.Equal(t, float64(1), testutil.ToFloat64(m.InFlight.WithLabelValues(method)), name)
+
+			if tc.cancel {
+				cancel()
+				require.Eventually(t, func() bool {
+					return testutil.ToFloat64(m.InFlight.WithLabelValues(method)) == 0
+				}, time.Second, time.Millisecond, name)
+			}
+
+			// only the first error ends the call
+			require.Equal(t, tc.recvErr, cs.RecvMsg(nil), name)
+			require.Equal(t, tc.recvErr, cs.RecvMsg(nil), name)
+		}
+		cancel()
+
+		require.Equal(t, float64(1), testutil.ToFloat64(m.Calls.WithLabelValues(method, tc.code)), name)
+		require.Equal(t, float64(0), testutil.ToFloat64(m.InFlight.WithLabelValues(method)), name)
+	}
+}
+
+func TestServer(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	server := NewServer(listener)
+
+	done := make(chan error, 1)
+	go func() {
+		done <- server.Serve()
+	}()
+
+	resp, err := http.Get("http://" + listener.Addr().String() + "/metrics")
+	require.NoError(t, err)
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	require.NoError(t, err)
+
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Contains(t, string(body), "go_goroutines")
+
+	require.NoError(t, server.Shutdown(context.Background()))
+	require.NoError(t, <-done)
+}
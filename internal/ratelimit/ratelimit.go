@@ -0,0 +1,330 @@
+package ratelimit
+
+// package used by sqsservice to keep its callers within the sqs api quotas and cost budget
+// and by client to wait as long as the sqsservice asks before calling again
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+const (
+	packageName = "ratelimit"
+
+	// metadata key of the number of milliseconds a rejected caller should wait before calling again
+	RetryAfterKey = "retry-after-ms"
+
+	// limits every method without its own limit when used as a method name
+	AllMethods = "*"
+)
+
+// Limit - number of calls allowed per second, and how many of them can be made at once
+type Limit struct {
+	Rate  float64
+	Burst int
+}
+
+// ParseLimits - parses limits by method or queue name, e.g. ReceiveMessage=10:20,SendMessage=50
+// each limit is rate:burst; the burst defaults to the rate rounded up
+func ParseLimits(s string) (map[string]Limit, error) {
+	limits := make(map[string]Limit)
+
+	for _, rule := range strings.Split(s, ",") {
+		rule = strings.TrimSpace(rule)
+		if rule == "" {
+			continue
+		}
+
+		name, value, found := strings.Cut(rule, "=")
+		name = strings.TrimSpace(name)
+		if !found || name == "" {
+			return nil, fmt.Errorf("invalid rate limit: %v", rule)
+		}
+
+		rate, burst, hasBurst := strings.Cut(strings.TrimSpace(value), ":")
+
+		limit := Limit{}
+		var err error
+		if limit.Rate, err = strconv.ParseFloat(rate, 64); err != nil || limit.Rate <= 0 {
+			return nil, fmt.Errorf("invalid rate of rate limit: %v", rule)
+		}
+
+		limit.Burst = int(math.Ceil(limit.Rate))
+		if hasBurst {
+			if limit.Burst, err = strconv.Atoi(burst); err != nil || limit.Burst < 1 {
+				return nil, fmt.Errorf("invalid burst of rate limit: %v", rule)
+			}
+		}
+
+		limits[name] = limit
+	}
+
+	return limits, nil
+}
+
+// Bucket - token bucket refilled at the limit's rate up to its burst
+type Bucket struct {
+	Limit Limit
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+// NewBucket - creates new Bucket, full
+func NewBucket(limit Limit) *Bucket {
+	return &Bucket{Limit: limit, tokens: float64(limit.Burst), last: time.Now()}
+}
+
+// Take - takes a token if there's one
+// returns how long until the next token otherwise
+func (b *Bucket) Take() (bool, time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if wait := b.wait(); wait > 0 {
+		return false, wait
+	}
+
+	b.tokens--
+
+	return true, 0
+}
+
+// Wait - returns how long until the bucket has a token, without taking it; 0 if it has one
+func (b *Bucket) Wait() time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.wait()
+}
+
+// wait - refills the bucket and returns how long until it has a token; b.mu must be held
+func (b *Bucket) wait() time.Duration {
+	now := time.Now()
+	b.tokens = math.Min(float64(b.Limit.Burst), b.tokens+now.Sub(b.last).Seconds()*b.Limit.Rate)
+	b.last = now
+
+	if b.tokens >= 1 {
+		return 0
+	}
+
+	return time.Duration((1 - b.tokens) / b.Limit.Rate * float64(time.Second))
+}
+
+// Limiter - rejects the calls over the limits of their method or of the queues they call sqs on
+type Limiter struct {
+	// returns the names of the queues a method calls sqs on; queue limits are unused if nil
+	Queues func(fullMethod string) []string
+	// services never limited, e.g. grpc.health.v1.Health so probes aren't rejected when callers are busy
+	ExemptServices []string
+	Logger         zerolog.Logger
+
+	// serializes calls checking and taking tokens so a call only takes them once all of its buckets have one
+	takeMu       sync.Mutex
+	mu           sync.Mutex
+	methodLimits map[string]Limit
+	queueLimits  map[string]Limit
+	// method or queue name -> bucket, created on the first call
+	methodBuckets map[string]*Bucket
+	queueBuckets  map[string]*Bucket
+}
+
+// NewLimiter - creates new Limiter
+// methodLimits are by method name, e.g. ReceiveMessage, or AllMethods; queueLimits by queue name
+func NewLimiter(logger zerolog.Logger, methodLimits map[string]Limit, queueLimits map[string]Limit) *Limiter {
+	l := &Limiter{Logger: logger.With().Str("package", packageName).Logger()}
+	l.SetLimits(methodLimits, queueLimits)
+
+	return l
+}
+
+// SetLimits - replaces the limits; the buckets start full again
+func (l *Limiter) SetLimits(methodLimits map[string]Limit, queueLimits map[string]Limit) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.methodLimits, l.queueLimits = methodLimits, queueLimits
+	l.methodBuckets, l.queueBuckets = make(map[string]*Bucket), make(map[string]*Bucket)
+}
+
+// buckets - returns the buckets the call takes tokens from
+func (l *Limiter) buckets(fullMethod string) []*Bucket {
+	method := fullMethod[strings.LastIndex(fullMethod, "/")+1:]
+
+	queues := make([]string, 0)
+	if l.Queues != nil {
+		queues = l.Queues(fullMethod)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	buckets := make([]*Bucket, 0, 1+len(queues))
+
+	// methods without their own limit each get a bucket of the AllMethods limit
+	limit, ok := l.methodLimits[method]
+	if !ok {
+		limit, ok = l.methodLimits[AllMethods]
+	}
+	if ok {
+		if _, exists := l.methodBuckets[method]; !exists {
+			l.methodBuckets[method] = NewBucket(limit)
+		}
+		buckets = append(buckets, l.methodBuckets[method])
+	}
+
+	for _, queue := range queues {
+		limit, ok := l.queueLimits[queue]
+		if !ok {
+			continue
+		}
+
+		if _, exists := l.queueBuckets[queue]; !exists {
+			l.queueBuckets[queue] = NewBucket(limit)
+		}
+		buckets = append(buckets, l.queueBuckets[queue])
+	}
+
+	return buckets
+}
+
+// allow - takes a token from every bucket of the call
+// fails with ResourceExhausted carrying the longest wait if any is empty, without taking tokens from the others
+func (l *Limiter) allow(fullMethod string) (time.Duration, error) {
+	for _, service := range l.ExemptServices {
+		if strings.HasPrefix(fullMethod, "/"+service+"/") {
+			return 0, nil
+		}
+	}
+
+	l.takeMu.Lock()
+	defer l.takeMu.Unlock()
+
+	buckets := l.buckets(fullMethod)
+
+	retryAfter := time.Duration(0)
+	for _, bucket := range buckets {
+		if wait := bucket.Wait(); wait > retryAfter {
+			retryAfter = wait
+		}
+	}
+
+	if retryAfter == 0 {
+		for _, bucket := range buckets {
+			bucket.Take()
+		}
+
+		return 0, nil
+	}
+
+	l.Logger.Debug().Str("function", "allow").Str("method", fullMethod).Dur("retryAfter", retryAfter).Msg("Rejected call over rate limit")
+
+	st, err := status.New(codes.ResourceExhausted, fmt.Sprintf("rate limit of %v exceeded, retry after %v", fullMethod, retryAfter)).
+		WithDetails(&errdetails.RetryInfo{RetryDelay: durationpb.New(retryAfter)})
+	if err != nil {
+		return retryAfter, status.Error(codes.ResourceExhausted, fmt.Sprintf("rate limit of %v exceeded", fullMethod))
+	}
+
+	return retryAfter, st.Err()
+}
+
+// trailer - returns the metadata telling the caller how long to wait
+func trailer(retryAfter time.Duration) metadata.MD {
+	return metadata.Pairs(RetryAfterKey, strconv.FormatInt(int64(math.Ceil(float64(retryAfter)/float64(time.Millisecond))), 10))
+}
+
+// Unary - interceptor for unary calls
+func (l *Limiter) Unary() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		retryAfter, err := l.allow(info.FullMethod)
+		if err != nil {
+			// fails outside of grpc calls, e.g. for calls of the gateway, which reads the error's details
+			_ = grpc.SetTrailer(ctx, trailer(retryAfter))
+			return nil, err
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// Stream - interceptor for streaming calls
+// only opening the stream is limited, not the messages it carries
+func (l *Limiter) Stream() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		retryAfter, err := l.allow(info.FullMethod)
+		if err != nil {
+			ss.SetTrailer(trailer(retryAfter))
+			return err
+		}
+
+		return handler(srv, ss)
+	}
+}
+
+// RetryAfter - returns how long the sqsservice asked to wait before calling again if err is a rejection over a rate limit
+func RetryAfter(err error) (time.Duration, bool) {
+	st, ok := status.FromError(err)
+	if !ok || st.Code() != codes.ResourceExhausted {
+		return 0, false
+	}
+
+	for _, detail := range st.Details() {
+		if info, ok := detail.(*errdetails.RetryInfo); ok && info.RetryDelay != nil {
+			return info.RetryDelay.AsDuration(), true
+		}
+	}
+
+	return 0, false
+}
+
+// retryKey - key of the context stopping the retries of a call in its context
+type retryKey struct{}
+
+// WithRetryContext - makes UnaryClientInterceptor give up retrying the calls made with ctx once retryCtx is done
+// unlike cancelling ctx, it doesn't cut short an attempt already made, e.g. a receive whose messages would be lost
+func WithRetryContext(ctx context.Context, retryCtx context.Context) context.Context {
+	return context.WithValue(ctx, retryKey{}, retryCtx)
+}
+
+// UnaryClientInterceptor - retries calls rejected over a rate limit after waiting as long as the sqsservice asks
+// gives up after retries attempts, or once ctx or the context set with WithRetryContext is done
+func UnaryClientInterceptor(retries int) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		// never done unless set
+		retryCtx, ok := ctx.Value(retryKey{}).(context.Context)
+		if !ok {
+			retryCtx = context.Background()
+		}
+
+		for attempt := 0; ; attempt++ {
+			err := invoker(ctx, method, req, reply, cc, opts...)
+
+			retryAfter, ok := RetryAfter(err)
+			if !ok || attempt >= retries || retryCtx.Err() != nil {
+				return err
+			}
+
+			select {
+			case <-ctx.Done():
+				return err
+			case <-retryCtx.Done():
+				return err
+			case <-time.After(retryAfter):
+			}
+		}
+	}
+}
@@ -0,0 +1,208 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+func TestParseLimits(t *testing.T) {
+	testCases := map[string]struct {
+		limits  string
+		want    map[string]Limit
+		wantErr bool
+	}{
+		"rates and bursts": {
+			limits: "ReceiveMessage=10:20, SendMessage=2.5,*=100",
+			want: map[string]Limit{
+				"ReceiveMessage": {Rate: 10, Burst: 20},
+				"SendMessage":    {Rate: 2.5, Burst: 3},
+				AllMethods:       {Rate: 100, Burst: 100},
+			},
+		},
+		"no limits": {
+			limits: "",
+			want:   map[string]Limit{},
+		},
+		"missing rate": {
+			limits:  "ReceiveMessage",
+			wantErr: true,
+		},
+		"invalid rate": {
+			limits:  "ReceiveMessage=0",
+			wantErr: true,
+		},
+		"invalid burst": {
+			limits:  "ReceiveMessage=10:many",
+			wantErr: true,
+		},
+	}
+
+	for name, tc := range testCases {
+		limits, err := ParseLimits(tc.limits)
+		if tc.wantErr {
+			require.Error(t, err, name)
+			continue
+		}
+
+		require.NoError(t, err, name)
+		require.Equal(t, tc.want, limits, name)
+	}
+}
+
+func TestBucket(t *testing.T) {
+	bucket := NewBucket(Limit{Rate: 10, Burst: 2})
+
+	for i := 0; i < 2; i++ {
+		ok, _ := bucket.Take()
+		require.True(t, ok)
+	}
+
+	ok, wait := bucket.Take()
+	require.False(t, ok)
+	require.True(t, wait > 0 && wait <= 100*time.Millisecond, wait)
+
+	time.Sleep(wait)
+
+	ok, _ = bucket.Take()
+	require.True(t, ok)
+}
+
+func TestLimiter(t *testing.T) {
+	limiter := NewLimiter(zerolog.Nop(), map[string]Limit{"SendMessage": {Rate: 1, Burst: 2}, AllMethods: {Rate: 1, Burst: 1}},
+		map[string]Limit{"queue": {Rate: 1, Burst: 4}})
+	limiter.Queues = func(fullMethod string) []string {
+		return []string{"queue"}
+	}
+
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return "ok", nil
+	}
+	call := func(method string) error {
+		_, err := limiter.Unary()(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: "/sqs.SQSService/" + method}, handler)
+		return err
+	}
+
+	require.NoError(t, call("SendMessage"))
+	require.NoError(t, call("SendMessage"))
+	// over the method's limit
+	err := call("SendMessage")
+	require.Equal(t, codes.ResourceExhausted, status.Code(err))
+	retryAfter, ok := RetryAfter(err)
+	require.True(t, ok)
+	require.True(t, retryAfter > 0 && retryAfter <= time.Second, retryAfter)
+
+	// methods without their own limit each get a bucket of the default one
+	require.NoError(t, call("DeleteMessage"))
+	// the queue's tokens are shared by every method; the rejected call didn't use one up
+	require.NoError(t, call("NackMessage"))
+	require.Equal(t, codes.ResourceExhausted, status.Code(call("ChangeMessageVisibility")))
+
+	// exempt services are never limited
+	limiter.ExemptServices = []string{"grpc.health.v1.Health"}
+	for i := 0; i < 3; i++ {
+		_, err = limiter.Unary()(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: "/grpc.health.v1.Health/Check"}, handler)
+		require.NoError(t, err)
+	}
+
+	// new limits start with full buckets
+	limiter.SetLimits(map[string]Limit{"SendMessage": {Rate: 1, Burst: 2}}, nil)
+	require.NoError(t, call("SendMessage"))
+	require.NoError(t, call("NackMessage"))
+}
+
+func TestRetryAfter(t *testing.T) {
+	throttled, err := status.New(codes.ResourceExhausted, "rate limit exceeded").
+		WithDetails(&errdetails.RetryInfo{RetryDelay: durationpb.New(250 * time.Millisecond)})
+	require.NoError(t, err)
+
+	retryAfter, ok := RetryAfter(throttled.Err())
+	require.True(t, ok)
+	require.Equal(t, 250*time.Millisecond, retryAfter)
+
+	_, ok = RetryAfter(status.Error(codes.ResourceExhausted, "quota exceeded"))
+	require.False(t, ok)
+
+	_, ok = RetryAfter(nil)
+	require.False(t, ok)
+}
+
+func TestUnaryClientInterceptor(t *testing.T) {
+	throttled, err := status.New(codes.ResourceExhausted, "rate limit exceeded").
+		WithDetails(&errdetails.RetryInfo{RetryDelay: durationpb.New(time.Millisecond)})
+	require.NoError(t, err)
+
+	testCases := map[string]struct {
+		rejections   int
+		retries      int
+		wantAttempts int
+		wantCode     codes.Code
+	}{
+		"retried until allowed": {
+			rejections:   2,
+			retries:      3,
+			wantAttempts: 3,
+		},
+		"retries exhausted": {
+			rejections:   5,
+			retries:      2,
+			wantAttempts: 3,
+			wantCode:     codes.ResourceExhausted,
+		},
+		"retries disabled": {
+			rejections:   1,
+			wantAttempts: 1,
+			wantCode:     codes.ResourceExhausted,
+		},
+	}
+
+	for name, tc := range testCases {
+		attempts := 0
+		invoker := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+			attempts++
+			if attempts <= tc.rejections {
+				return throttled.Err()
+			}
+			return nil
+		}
+
+		err := UnaryClientInterceptor(tc.retries)(context.Background(), "/sqs.SQSService/ReceiveMessage", nil, nil, nil, invoker)
+		require.Equal(t, tc.wantCode, status.Code(err), name)
+		require.Equal(t, tc.wantAttempts, attempts, name)
+	}
+}
+
+func TestUnaryClientInterceptorRetryContext(t *testing.T) {
+	throttled, err := status.New(codes.ResourceExhausted, "rate limit exceeded").
+		WithDetails(&errdetails.RetryInfo{RetryDelay: durationpb.New(time.Minute)})
+	require.NoError(t, err)
+
+	retryCtx, cancel := context.WithCancel(context.Background())
+
+	attempts := 0
+	invoker := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		attempts++
+
+		// the attempt itself isn't cancelled with the retry context
+		cancel()
+		require.NoError(t, ctx.Err())
+
+		return throttled.Err()
+	}
+
+	ctx := WithRetryContext(context.Background(), retryCtx)
+
+	start := time.Now()
+	err = UnaryClientInterceptor(3)(ctx, "/sqs.SQSService/ReceiveMessage", nil, nil, nil, invoker)
+	require.Equal(t, codes.ResourceExhausted, status.Code(err))
+	require.Equal(t, 1, attempts)
+	require.Less(t, time.Since(start), time.Second)
+}
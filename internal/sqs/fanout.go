@@ -0,0 +1,130 @@
+package sqs
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/alvinlucillo/sqs-processor/internal/tracing"
+)
+
+// maximum number of receives running in parallel for a single batch
+const maxFanOut = 10
+
+// fanOut - aggregates messages from parallel receives until MaximumMessages or MaximumWaitTime is reached
+// messages received more than once are only returned once, with their latest receipt handle
+func (s *SQSService) fanOut(ctx context.Context, sqsConfig *SQSReceiveMsgConfig) (*SQSResult, error) {
+	l := s.Logger.With().Str("function", "fanOut").Logger()
+
+	maximumWaitTime := sqsConfig.MaximumWaitTime
+	if maximumWaitTime <= 0 {
+		maximumWaitTime = sqsConfig.WaitingTime
+	}
+	deadline := time.Now().Add(time.Duration(maximumWaitTime) * time.Second)
+
+	workers := int((sqsConfig.MaximumMessages + MaxBatchSize - 1) / MaxBatchSize)
+	if workers > maxFanOut {
+		workers = maxFanOut
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	var firstErr error
+	// message id -> position in messages
+	seen := make(map[string]int)
+	messages := make([]SQSResultMessage, 0, sqsConfig.MaximumMessages)
+	// messages requested by receives still running
+	reserved := int64(0)
+
+	// reserve - claims up to a batch of the messages still missing; returns 0 when none are
+	reserve := func() int64 {
+		mu.Lock()
+		defer mu.Unlock()
+
+		missing := sqsConfig.MaximumMessages - int64(len(messages)) - reserved
+		if missing > MaxBatchSize {
+			missing = MaxBatchSize
+		}
+		if missing < 0 || firstErr != nil {
+			missing = 0
+		}
+		reserved += missing
+
+		return missing
+	}
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for attempt := 0; ; attempt++ {
+				// every worker receives at least once, even when there's no time to wait
+				if ctx.Err() != nil || (attempt > 0 && !time.Now().Before(deadline)) {
+					return
+				}
+
+				// long polls end by the deadline instead of being cut short
+				// since messages of a cancelled receive would stay invisible
+				waitingTime := sqsConfig.WaitingTime
+				if remaining := int64(time.Until(deadline) / time.Second); waitingTime > remaining {
+					waitingTime = remaining
+				}
+				if waitingTime < 0 {
+					waitingTime = 0
+				}
+
+				batchSize := reserve()
+				if batchSize == 0 {
+					return
+				}
+
+				result, err := s.GetSQSMessageWithContext(tracing.Detach(ctx), &SQSReceiveMsgConfig{
+					VisibilityTimeout: sqsConfig.VisibilityTimeout,
+					WaitingTime:       waitingTime,
+					MaximumMessages:   batchSize,
+				})
+
+				mu.Lock()
+				reserved -= batchSize
+
+				if err != nil {
+					if firstErr == nil {
+						firstErr = err
+					}
+					mu.Unlock()
+					return
+				}
+
+				for _, msg := range result.Messages {
+					if i, ok := seen[msg.MessageID]; ok && msg.MessageID != "" {
+						messages[i] = msg
+						continue
+					}
+
+					seen[msg.MessageID] = len(messages)
+					messages = append(messages, msg)
+				}
+				mu.Unlock()
+
+				// short polls give up on the first empty receive
+				if len(result.Messages) == 0 && sqsConfig.WaitingTime == 0 {
+					return
+				}
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	if firstErr != nil {
+		if len(messages) == 0 {
+			return nil, firstErr
+		}
+
+		l.Err(firstErr).Msgf("Failed to receive every batch, returning %v message(s)", len(messages))
+	}
+
+	return &SQSResult{Messages: messages}, nil
+}
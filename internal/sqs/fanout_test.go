@@ -0,0 +1,77 @@
+package sqs
+
+import (
+	"fmt"
+	"sync/atomic"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/sqs"
+	"github.com/stretchr/testify/require"
+)
+
+// fanOutSqsMock - returns full batches of messages whose ids repeat after distinct messages
+type fanOutSqsMock struct {
+	SqsMock
+	distinct int64
+	counter  int64
+}
+
+func (s *fanOutSqsMock) ReceiveMessageWithContext(ctx aws.Context, in *sqs.ReceiveMessageInput, opts ...request.Option) (*sqs.ReceiveMessageOutput, error) {
+	out := &sqs.ReceiveMessageOutput{}
+
+	for i := int64(0); i < *in.MaxNumberOfMessages; i++ {
+		n := atomic.AddInt64(&s.counter, 1)
+		out.Messages = append(out.Messages, &sqs.Message{
+			ReceiptHandle: aws.String(fmt.Sprintf("handle-%v", n)),
+			MessageId:     aws.String(fmt.Sprintf("id-%v", n%s.distinct)),
+			Body:          aws.String(SqsMessageBody),
+		})
+	}
+
+	return out, nil
+}
+
+func TestGetSQSMessageFanOut(t *testing.T) {
+	testCases := map[string]struct {
+		distinct        int64
+		maximumMessages int64
+		messages        int
+	}{
+		"aggregated batch": {
+			distinct:        1000,
+			maximumMessages: 45,
+			messages:        45,
+		},
+		"duplicates removed": {
+			distinct:        12,
+			maximumMessages: 30,
+			messages:        12,
+		},
+	}
+
+	for _, tc := range testCases {
+		svc := &SQSService{
+			Session:   &session.Session{},
+			SQSClient: &fanOutSqsMock{distinct: tc.distinct},
+			QueueURL:  aws.String(SqsQueueUrlPrefix + SqsQueueName),
+		}
+
+		out, err := svc.GetSQSMessage(&SQSReceiveMsgConfig{MaximumMessages: tc.maximumMessages, MaximumWaitTime: 1})
+		require.NoError(t, err)
+		require.Len(t, out.Messages, tc.messages)
+	}
+}
+
+func TestGetSQSMessageFanOutError(t *testing.T) {
+	svc := &SQSService{
+		Session:   &session.Session{},
+		SQSClient: &SqsMock{},
+		QueueURL:  aws.String(SqsQueueUrlPrefix + SqsErrQueueName),
+	}
+
+	_, err := svc.GetSQSMessage(&SQSReceiveMsgConfig{MaximumMessages: 25})
+	require.EqualError(t, err, ErrMessageFailedReceive)
+}
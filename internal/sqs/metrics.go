@@ -0,0 +1,72 @@
+package sqs
+
+import (
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const (
+	metricsNamespace = "sqsservice"
+)
+
+var (
+	apiCalls = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "sqs_api_calls_total",
+		Help:      "Number of sqs api calls by operation and aws error code; OK if the call succeeded.",
+	}, []string{"operation", "code"})
+	apiDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Name:      "sqs_api_duration_seconds",
+		Help:      "Duration of sqs api calls by operation, including retries and long polls.",
+		Buckets:   []float64{.01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10, 20, 30},
+	}, []string{"operation"})
+	receives = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "sqs_receives_total",
+		Help:      "Number of successful ReceiveMessage calls.",
+	})
+	emptyReceives = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "sqs_empty_receives_total",
+		Help:      "Number of successful ReceiveMessage calls that returned no messages.",
+	})
+	messagesReceived = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "messages_received_total",
+		Help:      "Number of messages received from the queue.",
+	})
+	messagesDeleted = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "messages_deleted_total",
+		Help:      "Number of messages deleted from the queue, including the dead-lettered ones.",
+	})
+	messagesDeadLettered = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "messages_dead_lettered_total",
+		Help:      "Number of messages moved to the dead-letter queue.",
+	})
+)
+
+// instrument - records every aws call made through the handlers once it completes
+func instrument(handlers *request.Handlers) {
+	handlers.Complete.PushBackNamed(request.NamedHandler{
+		Name: "sqsservice.metrics",
+		Fn: func(r *request.Request) {
+			code := "OK"
+			if r.Error != nil {
+				code = "Unknown"
+				if awsErr, ok := r.Error.(awserr.Error); ok {
+					code = awsErr.Code()
+				}
+			}
+
+			apiCalls.WithLabelValues(r.Operation.Name, code).Inc()
+			apiDuration.WithLabelValues(r.Operation.Name).Observe(time.Since(r.Time).Seconds())
+		},
+	})
+}
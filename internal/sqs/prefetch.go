@@ -0,0 +1,372 @@
+package sqs
+
+// prefetcher used by sqsservice to serve receives from memory
+// long polls sqs in the background and keeps buffered messages invisible until handed out
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+const (
+	// time a worker waits before polling again after a failed poll
+	prefetchRetryInterval = time.Second
+)
+
+type PrefetchConfig struct {
+	// number of concurrent long poll workers
+	Workers int
+	// maximum number of messages held in memory
+	BufferSize int
+	// number of seconds buffered messages are kept invisible; extended while they're in the buffer
+	VisibilityTimeout int64
+	// number of seconds each worker long polls for
+	WaitingTime int64
+}
+
+type bufferedMessage struct {
+	message SQSResultMessage
+	// time the message's visibility timeout lapses
+	expiry time.Time
+}
+
+// Prefetcher - fills a bounded buffer with messages polled by background workers
+type Prefetcher struct {
+	Service *SQSService
+	Config  PrefetchConfig
+	Logger  zerolog.Logger
+
+	mu     sync.Mutex
+	buffer []bufferedMessage
+	// room taken by workers that are still polling
+	reserved int
+	// closed and replaced whenever the buffer changes
+	changed chan struct{}
+
+	// serializes visibility changes so an extension never overrides the visibility of a handed out message
+	leaseMu sync.Mutex
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewPrefetcher - creates new Prefetcher; call Start to begin polling
+func NewPrefetcher(service *SQSService, config PrefetchConfig) *Prefetcher {
+	if config.Workers <= 0 {
+		config.Workers = 1
+	}
+
+	if config.BufferSize <= 0 {
+		config.BufferSize = MaxBatchSize
+	}
+
+	if config.VisibilityTimeout <= 0 {
+		config.VisibilityTimeout = 30
+	}
+
+	if config.WaitingTime <= 0 || config.WaitingTime > MaxWaitingTime {
+		config.WaitingTime = MaxWaitingTime
+	}
+
+	return &Prefetcher{
+		Service: service,
+		Config:  config,
+		Logger:  service.Logger.With().Str("component", "prefetcher").Logger(),
+		changed: make(chan struct{}),
+	}
+}
+
+// Start - starts the polling workers and the visibility keeper
+func (p *Prefetcher) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	p.cancel = cancel
+
+	for i := 0; i < p.Config.Workers; i++ {
+		p.wg.Add(1)
+		go p.poll(ctx)
+	}
+
+	p.wg.Add(1)
+	go p.keepInvisible(ctx)
+}
+
+// Stop - stops polling and releases the buffered messages back to the queue
+func (p *Prefetcher) Stop() {
+	l := p.Logger.With().Str("function", "Stop").Logger()
+
+	if p.cancel != nil {
+		p.cancel()
+	}
+	p.wg.Wait()
+
+	p.mu.Lock()
+	buffered := p.buffer
+	p.buffer = nil
+	p.mu.Unlock()
+
+	for _, msg := range buffered {
+		if err := p.Service.ChangeSQSMessageVisibility(msg.message.ID, 0); err != nil {
+			l.Err(err).Msgf("Failed to release buffered message: %v", msg.message.ID)
+		}
+	}
+
+	l.Info().Msgf("Released %v buffered message(s)", len(buffered))
+}
+
+// Receive - hands out up to MaximumMessages buffered messages
+// waits up to WaitingTime seconds for messages if the buffer is empty
+// handed out messages get the requested visibility timeout, or the prefetch one, from then on
+func (p *Prefetcher) Receive(ctx context.Context, sqsConfig *SQSReceiveMsgConfig) (*SQSResult, error) {
+	l := p.Logger.With().Str("function", "Receive").Logger()
+
+	maximumMessages := int(sqsConfig.MaximumMessages)
+	if maximumMessages <= 0 {
+		maximumMessages = 1
+	}
+
+	timer := time.NewTimer(time.Duration(sqsConfig.WaitingTime) * time.Second)
+	defer timer.Stop()
+
+	var taken []bufferedMessage
+
+	for {
+		var changed chan struct{}
+		taken, changed = p.take(maximumMessages)
+		if len(taken) > 0 {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-timer.C:
+			return &SQSResult{Messages: make([]SQSResultMessage, 0)}, nil
+		case <-changed:
+		}
+	}
+
+	messages := make([]SQSResultMessage, 0, len(taken))
+
+	p.leaseMu.Lock()
+	defer p.leaseMu.Unlock()
+
+	// messages get the prefetch visibility timeout if the requester doesn't set one
+	// their lease is reset either way since a buffered message may be close to becoming visible
+	visibilityTimeout := sqsConfig.VisibilityTimeout
+	if visibilityTimeout <= 0 {
+		visibilityTimeout = p.Config.VisibilityTimeout
+	}
+
+	for _, msg := range taken {
+		if err := p.Service.ChangeSQSMessageVisibility(msg.message.ID, visibilityTimeout); err != nil {
+			l.Err(err).Msgf("Failed to set visibility of message, dropping it: %v", msg.message.ID)
+			continue
+		}
+
+		messages = append(messages, msg.message)
+	}
+
+	return &SQSResult{Messages: messages}, nil
+}
+
+// take - removes up to max messages from the buffer
+// also returns the channel closed on the buffer's next change
+func (p *Prefetcher) take(max int) ([]bufferedMessage, chan struct{}) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if max > len(p.buffer) {
+		max = len(p.buffer)
+	}
+
+	taken := make([]bufferedMessage, max)
+	copy(taken, p.buffer[:max])
+	p.buffer = p.buffer[max:]
+
+	if max > 0 {
+		p.notify()
+	}
+
+	return taken, p.changed
+}
+
+// notify - wakes everyone waiting on the buffer; caller holds the lock
+func (p *Prefetcher) notify() {
+	close(p.changed)
+	p.changed = make(chan struct{})
+}
+
+// reserve - claims room in the buffer for a batch
+// returns 0 and the change channel if the buffer is full
+func (p *Prefetcher) reserve() (int, chan struct{}) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	room := p.Config.BufferSize - len(p.buffer) - p.reserved
+	if room <= 0 {
+		return 0, p.changed
+	}
+
+	if room > MaxBatchSize {
+		room = MaxBatchSize
+	}
+	p.reserved += room
+
+	return room, p.changed
+}
+
+// fill - adds polled messages to the buffer and frees the batch's reservation
+func (p *Prefetcher) fill(reserved int, messages []SQSResultMessage, expiry time.Time) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.reserved -= reserved
+
+	for _, msg := range messages {
+		p.buffer = append(p.buffer, bufferedMessage{message: msg, expiry: expiry})
+	}
+
+	p.notify()
+}
+
+// poll - long polls sqs while the buffer has room
+func (p *Prefetcher) poll(ctx context.Context) {
+	defer p.wg.Done()
+
+	l := p.Logger.With().Str("function", "poll").Logger()
+
+	for {
+		room, changed := p.reserve()
+		if room == 0 {
+			select {
+			case <-ctx.Done():
+				return
+			case <-changed:
+				continue
+			}
+		}
+
+		sqsConfig := &SQSReceiveMsgConfig{
+			VisibilityTimeout: p.Config.VisibilityTimeout,
+			WaitingTime:       p.Config.WaitingTime,
+			MaximumMessages:   int64(room),
+		}
+
+		expiry := time.Now().Add(time.Duration(p.Config.VisibilityTimeout) * time.Second)
+
+		result, err := p.Service.GetSQSMessageWithContext(ctx, sqsConfig)
+		if err != nil {
+			p.fill(room, nil, expiry)
+
+			if ctx.Err() != nil {
+				return
+			}
+
+			l.Err(err).Msg("Failed to prefetch messages")
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(prefetchRetryInterval):
+			}
+
+			continue
+		}
+
+		p.fill(room, result.Messages, expiry)
+	}
+}
+
+// keepInvisible - extends the visibility timeout of buffered messages about to become visible
+// messages whose extension fails are dropped from the buffer since another consumer may receive them
+func (p *Prefetcher) keepInvisible(ctx context.Context) {
+	defer p.wg.Done()
+
+	l := p.Logger.With().Str("function", "keepInvisible").Logger()
+
+	visibility := time.Duration(p.Config.VisibilityTimeout) * time.Second
+	// extends once a third of the visibility timeout is left
+	margin := visibility / 3
+
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		p.mu.Lock()
+		expiring := make([]string, 0)
+		for _, msg := range p.buffer {
+			if time.Until(msg.expiry) < margin {
+				expiring = append(expiring, msg.message.ID)
+			}
+		}
+		p.mu.Unlock()
+
+		if len(expiring) == 0 {
+			continue
+		}
+
+		expiry := time.Now().Add(visibility)
+		failed := make(map[string]bool)
+		extended := make(map[string]bool)
+
+		p.leaseMu.Lock()
+		for _, id := range expiring {
+			// skips messages handed out since they were collected
+			if !p.buffered(id) {
+				continue
+			}
+
+			if err := p.Service.ChangeSQSMessageVisibility(id, p.Config.VisibilityTimeout); err != nil {
+				l.Err(err).Msgf("Failed to extend visibility of buffered message, dropping it: %v", id)
+				failed[id] = true
+				continue
+			}
+
+			extended[id] = true
+		}
+		p.leaseMu.Unlock()
+
+		p.mu.Lock()
+		buffer := p.buffer[:0]
+		for _, msg := range p.buffer {
+			if failed[msg.message.ID] {
+				continue
+			}
+
+			if extended[msg.message.ID] {
+				msg.expiry = expiry
+			}
+
+			buffer = append(buffer, msg)
+		}
+		p.buffer = buffer
+
+		if len(failed) > 0 {
+			p.notify()
+		}
+		p.mu.Unlock()
+	}
+}
+
+// buffered - checks if the message is still in the buffer
+func (p *Prefetcher) buffered(id string) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, msg := range p.buffer {
+		if msg.message.ID == id {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,127 @@
+package sqs
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/sqs"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrefetcherReceive(t *testing.T) {
+	testCases := map[string]struct {
+		queueUrl string
+		messages int
+	}{
+		"receive from buffer": {
+			queueUrl: SqsQueueUrlPrefix + SqsQueueName,
+			messages: 3,
+		},
+		"empty buffer": {
+			queueUrl: SqsQueueUrlPrefix + SqsErrQueueName,
+			messages: 0,
+		},
+	}
+
+	for _, tc := range testCases {
+		svc := &SQSService{
+			Session:   &session.Session{},
+			SQSClient: &SqsMock{},
+			QueueURL:  aws.String(tc.queueUrl),
+		}
+
+		prefetcher := NewPrefetcher(svc, PrefetchConfig{Workers: 2, BufferSize: 5})
+		prefetcher.Start()
+
+		out, err := prefetcher.Receive(context.Background(), &SQSReceiveMsgConfig{MaximumMessages: 3, WaitingTime: 1, VisibilityTimeout: 10})
+		require.NoError(t, err)
+		require.Len(t, out.Messages, tc.messages)
+
+		prefetcher.Stop()
+	}
+}
+
+// visibilityMock - records the visibility timeouts messages are changed to
+type visibilityMock struct {
+	SqsMock
+
+	mu                 sync.Mutex
+	visibilityTimeouts []int64
+}
+
+func (s *visibilityMock) ChangeMessageVisibilityWithContext(ctx aws.Context, in *sqs.ChangeMessageVisibilityInput, opts ...request.Option) (*sqs.ChangeMessageVisibilityOutput, error) {
+	s.mu.Lock()
+	s.visibilityTimeouts = append(s.visibilityTimeouts, aws.Int64Value(in.VisibilityTimeout))
+	s.mu.Unlock()
+
+	return s.SqsMock.ChangeMessageVisibility(in)
+}
+
+func TestPrefetcherReceiveVisibility(t *testing.T) {
+	testCases := map[string]struct {
+		visibilityTimeout int64
+		want              int64
+	}{
+		"requested visibility timeout": {
+			visibilityTimeout: 10,
+			want:              10,
+		},
+		"prefetch visibility timeout": {
+			want: 40,
+		},
+	}
+
+	for name, tc := range testCases {
+		client := &visibilityMock{}
+		svc := &SQSService{
+			Session:   &session.Session{},
+			SQSClient: client,
+			QueueURL:  aws.String(SqsQueueUrlPrefix + SqsQueueName),
+		}
+
+		prefetcher := NewPrefetcher(svc, PrefetchConfig{Workers: 1, BufferSize: 1, VisibilityTimeout: 40})
+		prefetcher.Start()
+
+		out, err := prefetcher.Receive(context.Background(), &SQSReceiveMsgConfig{MaximumMessages: 1, WaitingTime: 1, VisibilityTimeout: tc.visibilityTimeout})
+		require.NoError(t, err, name)
+		require.Len(t, out.Messages, 1, name)
+
+		// the lease of the handed out message is reset however long it was buffered
+		client.mu.Lock()
+		require.Equal(t, []int64{tc.want}, client.visibilityTimeouts, name)
+		client.mu.Unlock()
+
+		prefetcher.Stop()
+	}
+}
+
+func TestPrefetcherBufferBound(t *testing.T) {
+	svc := &SQSService{
+		Session:   &session.Session{},
+		SQSClient: &SqsMock{},
+		QueueURL:  aws.String(SqsQueueUrlPrefix + SqsQueueName),
+	}
+
+	prefetcher := NewPrefetcher(svc, PrefetchConfig{Workers: 3, BufferSize: 4})
+	prefetcher.Start()
+	defer prefetcher.Stop()
+
+	require.Eventually(t, func() bool {
+		prefetcher.mu.Lock()
+		defer prefetcher.mu.Unlock()
+
+		return len(prefetcher.buffer) == 4
+	}, time.Second, 10*time.Millisecond)
+
+	// workers stop polling once the buffer is full
+	time.Sleep(50 * time.Millisecond)
+
+	prefetcher.mu.Lock()
+	require.Len(t, prefetcher.buffer, 4)
+	prefetcher.mu.Unlock()
+}
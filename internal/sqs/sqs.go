@@ -4,6 +4,10 @@ package sqs
 // calls aws apis
 
 import (
+	"context"
+	"errors"
+	"strconv"
+
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/credentials"
 	"github.com/aws/aws-sdk-go/aws/session"
@@ -14,13 +18,26 @@ import (
 
 const (
 	packageName = "sqs"
+
+	// message attribute holding why a message was dead-lettered
+	DeadLetterReasonAttribute = "DeadLetterReason"
+
+	// requests every message attribute of the received messages
+	allMessageAttributes = "All"
 )
 
+var ErrNoDeadLetterQueue = errors.New("no dead-letter queue configured")
+
 type SQSService struct {
 	Session   *session.Session
 	SQSClient sqsiface.SQSAPI
 	QueueURL  *string
-	Logger    zerolog.Logger
+	// nil if no dead-letter queue is configured
+	DeadLetterQueueURL *string
+	// names of the queues, used to report their health
+	QueueName           string
+	DeadLetterQueueName string
+	Logger              zerolog.Logger
 }
 
 // NewSQSService - creates new SQSService
@@ -45,6 +62,9 @@ func NewSQSService(config *SQSConfig) (*SQSService, error) {
 		return nil, err
 	}
 
+	instrument(&session.Handlers)
+	traceCalls(&session.Handlers)
+
 	sqsClient := sqs.New(session)
 
 	queueURL, err := getQueueURL(sqsClient, config.QueueName)
@@ -53,8 +73,20 @@ func NewSQSService(config *SQSConfig) (*SQSService, error) {
 		return nil, err
 	}
 
+	if config.DeadLetterQueueName != "" {
+		deadLetterQueueURL, err := getQueueURL(sqsClient, config.DeadLetterQueueName)
+		if err != nil {
+			l.Err(err).Msg("Failed to get dead-letter queue URL")
+			return nil, err
+		}
+
+		sqsService.DeadLetterQueueURL = deadLetterQueueURL.QueueUrl
+		sqsService.DeadLetterQueueName = config.DeadLetterQueueName
+	}
+
 	sqsService.Session = session
 	sqsService.QueueURL = queueURL.QueueUrl
+	sqsService.QueueName = config.QueueName
 	sqsService.SQSClient = sqsClient
 
 	return sqsService, nil
@@ -71,29 +103,143 @@ func getQueueURL(sqsClient sqsiface.SQSAPI, queueName string) (*sqs.GetQueueUrlO
 
 // DeleteSQSMessage - deletes sqs message
 func (s *SQSService) DeleteSQSMessage(id string) error {
+	return s.DeleteSQSMessageWithContext(context.Background(), id)
+}
+
+// DeleteSQSMessageWithContext - deletes sqs message
+func (s *SQSService) DeleteSQSMessageWithContext(ctx context.Context, id string) error {
 	input := &sqs.DeleteMessageInput{
 		QueueUrl:      s.QueueURL,
 		ReceiptHandle: aws.String(id),
 	}
 
 	// first value it returns isn't useful
-	_, err := s.SQSClient.DeleteMessage(input)
+	if _, err := s.SQSClient.DeleteMessageWithContext(ctx, input); err != nil {
+		return err
+	}
+
+	messagesDeleted.Inc()
+
+	return nil
+}
+
+// DeadLetterSQSMessage - moves the message to the dead-letter queue along with the reason
+func (s *SQSService) DeadLetterSQSMessage(id string, body string, reason string) error {
+	return s.DeadLetterSQSMessageWithContext(context.Background(), id, body, reason)
+}
+
+// DeadLetterSQSMessageWithContext - moves the message to the dead-letter queue along with the reason
+// the message is only deleted from the queue once it's in the dead-letter queue
+func (s *SQSService) DeadLetterSQSMessageWithContext(ctx context.Context, id string, body string, reason string) error {
+	if s.DeadLetterQueueURL == nil {
+		return ErrNoDeadLetterQueue
+	}
+
+	input := &sqs.SendMessageInput{
+		QueueUrl:    s.DeadLetterQueueURL,
+		MessageBody: aws.String(body),
+		MessageAttributes: traceAttributes(ctx, map[string]*sqs.MessageAttributeValue{
+			DeadLetterReasonAttribute: {DataType: aws.String("String"), StringValue: aws.String(reason)},
+		}),
+		MessageSystemAttributes: traceSystemAttributes(ctx),
+	}
+
+	if _, err := s.SQSClient.SendMessageWithContext(ctx, input); err != nil {
+		return err
+	}
+
+	if err := s.DeleteSQSMessageWithContext(ctx, id); err != nil {
+		return err
+	}
+
+	messagesDeadLettered.Inc()
+
+	return nil
+}
+
+// SendSQSMessage - sends a message to the queue and returns the id sqs assigned to it
+func (s *SQSService) SendSQSMessage(body string, delaySeconds int64) (string, error) {
+	return s.SendSQSMessageWithContext(context.Background(), body, delaySeconds)
+}
+
+// SendSQSMessageWithContext - sends a message to the queue and returns the id sqs assigned to it
+// the message only becomes visible to consumers after delaySeconds
+// the trace context of ctx is sent along so the message's consumers continue the trace
+func (s *SQSService) SendSQSMessageWithContext(ctx context.Context, body string, delaySeconds int64) (string, error) {
+	input := &sqs.SendMessageInput{
+		QueueUrl:                s.QueueURL,
+		MessageBody:             aws.String(body),
+		DelaySeconds:            aws.Int64(delaySeconds),
+		MessageAttributes:       traceAttributes(ctx, nil),
+		MessageSystemAttributes: traceSystemAttributes(ctx),
+	}
+
+	output, err := s.SQSClient.SendMessageWithContext(ctx, input)
+	if err != nil {
+		return "", err
+	}
+
+	return aws.StringValue(output.MessageId), nil
+}
+
+// ChangeSQSMessageVisibility - sets the number of seconds before the message becomes visible again
+func (s *SQSService) ChangeSQSMessageVisibility(id string, timeout int64) error {
+	return s.ChangeSQSMessageVisibilityWithContext(context.Background(), id, timeout)
+}
+
+// ChangeSQSMessageVisibilityWithContext - sets the number of seconds before the message becomes visible again
+// 0 makes the message immediately available to other consumers
+func (s *SQSService) ChangeSQSMessageVisibilityWithContext(ctx context.Context, id string, timeout int64) error {
+	input := &sqs.ChangeMessageVisibilityInput{
+		QueueUrl:          s.QueueURL,
+		ReceiptHandle:     aws.String(id),
+		VisibilityTimeout: aws.Int64(timeout),
+	}
+
+	_, err := s.SQSClient.ChangeMessageVisibilityWithContext(ctx, input)
+
+	return err
+}
+
+// CheckSQSQueue - fetches the queue's attributes to confirm the queue is reachable with the current credentials
+func (s *SQSService) CheckSQSQueue(ctx context.Context, queueURL *string) error {
+	input := &sqs.GetQueueAttributesInput{
+		QueueUrl:       queueURL,
+		AttributeNames: []*string{aws.String(sqs.QueueAttributeNameQueueArn)},
+	}
+
+	_, err := s.SQSClient.GetQueueAttributesWithContext(ctx, input)
 
 	return err
 }
 
 // GetSQSMessage - returns the messages
 func (s *SQSService) GetSQSMessage(sqsConfig *SQSReceiveMsgConfig) (*SQSResult, error) {
-	l := s.Logger.With().Str("function", "GetSQSMessage").Logger()
+	return s.GetSQSMessageWithContext(context.Background(), sqsConfig)
+}
+
+// GetSQSMessageWithContext - returns the messages
+// the aws call is cancelled once ctx is done, which cuts long polls short
+func (s *SQSService) GetSQSMessageWithContext(ctx context.Context, sqsConfig *SQSReceiveMsgConfig) (*SQSResult, error) {
+	l := s.Logger.With().Str("function", "GetSQSMessageWithContext").Logger()
+
+	// sqs caps a single receive at 10 messages so larger batches are aggregated from parallel receives
+	if sqsConfig.MaximumMessages > MaxBatchSize {
+		return s.fanOut(ctx, sqsConfig)
+	}
 
 	input := &sqs.ReceiveMessageInput{
 		QueueUrl:            s.QueueURL,
 		MaxNumberOfMessages: aws.Int64(sqsConfig.MaximumMessages),
 		VisibilityTimeout:   aws.Int64(sqsConfig.VisibilityTimeout),
 		WaitTimeSeconds:     aws.Int64(sqsConfig.WaitingTime),
+		AttributeNames: []*string{aws.String(sqs.MessageSystemAttributeNameApproximateReceiveCount),
+			aws.String(sqs.MessageSystemAttributeNameAwstraceHeader)},
+		// all of them so the client can route by them; the trace context is among them
+		MessageAttributeNames: aws.StringSlice([]string{allMessageAttributes}),
 	}
 
-	result, err := s.pollMessages(s.Session, input)
+	result, err := s.pollMessages(ctx, input)
 	if err != nil {
 		l.Err(err).Msgf("Failed to poll for messages")
 		return nil, err
@@ -103,7 +249,8 @@ func (s *SQSService) GetSQSMessage(sqsConfig *SQSReceiveMsgConfig) (*SQSResult,
 
 	if len(result) > 0 {
 		for _, msg := range result {
-			messages = append(messages, SQSResultMessage{ID: *msg.ReceiptHandle, Body: *msg.Body})
+			messages = append(messages, SQSResultMessage{ID: *msg.ReceiptHandle, MessageID: aws.StringValue(msg.MessageId),
+				Body: *msg.Body, ReceiveCount: receiveCount(msg), TraceContext: traceContext(msg), Attributes: messageAttributes(msg)})
 		}
 	}
 
@@ -111,15 +258,55 @@ func (s *SQSService) GetSQSMessage(sqsConfig *SQSReceiveMsgConfig) (*SQSResult,
 }
 
 // pollMessages - calls the actual aws api; internally used
-func (s *SQSService) pollMessages(sess *session.Session, sqsMessageInput *sqs.ReceiveMessageInput) ([]*sqs.Message, error) {
+func (s *SQSService) pollMessages(ctx context.Context, sqsMessageInput *sqs.ReceiveMessageInput) ([]*sqs.Message, error) {
 	l := s.Logger.With().Str("function", "pollMessages").Logger()
 
-	msgResult, err := s.SQSClient.ReceiveMessage(sqsMessageInput)
+	msgResult, err := s.SQSClient.ReceiveMessageWithContext(ctx, sqsMessageInput)
 
 	if err != nil {
 		l.Err(err).Msgf("Failed to query messages from SQS")
 		return nil, err
 	}
 
+	receives.Inc()
+	if len(msgResult.Messages) == 0 {
+		emptyReceives.Inc()
+	}
+	messagesReceived.Add(float64(len(msgResult.Messages)))
+
 	return msgResult.Messages, nil
 }
+
+// receiveCount - parses the message's ApproximateReceiveCount attribute; internally used
+// returns 0 if the attribute is missing or malformed
+func receiveCount(msg *sqs.Message) int64 {
+	value, ok := msg.Attributes[sqs.MessageSystemAttributeNameApproximateReceiveCount]
+	if !ok || value == nil {
+		return 0
+	}
+
+	count, err := strconv.ParseInt(*value, 10, 64)
+	if err != nil {
+		return 0
+	}
+
+	return count
+}
+
+// messageAttributes - returns the string and number message attributes of the message; internally used
+// number attributes, including custom types like Number.int, are returned as sent
+func messageAttributes(msg *sqs.Message) map[string]string {
+	if len(msg.MessageAttributes) == 0 {
+		return nil
+	}
+
+	attributes := make(map[string]string, len(msg.MessageAttributes))
+	for name, value := range msg.MessageAttributes {
+		if value == nil || value.StringValue == nil {
+			continue
+		}
+		attributes[name] = *value.StringValue
+	}
+
+	return attributes
+}
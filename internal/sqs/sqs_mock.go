@@ -7,6 +7,7 @@ import (
 	"errors"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
 	"github.com/aws/aws-sdk-go/service/sqs"
 	"github.com/aws/aws-sdk-go/service/sqs/sqsiface"
 )
@@ -19,11 +20,15 @@ const (
 	SqsMessageRcptHandle = "message-1"
 	SqsMessageId         = "message-id-1"
 	SqsMessageBody       = "message-body"
+	SqsMessageRcvCount   = "2"
 
 	ErrMessageId            = "error-id"
 	ErrMessageFailedDelete  = "failed deleting message"
 	errMessageFailedGetUrl  = "failed getting url"
 	ErrMessageFailedReceive = "failed receiving message"
+	ErrMessageFailedChange  = "failed changing message visibility"
+	ErrMessageFailedSend    = "failed sending message"
+	ErrMessageFailedGetAttr = "failed getting queue attributes"
 )
 
 type SqsMock struct {
@@ -41,6 +46,26 @@ func (s SqsMock) DeleteMessage(in *sqs.DeleteMessageInput) (*sqs.DeleteMessageOu
 	return s.deleteMessageOutput, nil
 }
 
+// DeleteMessageWithContext -- mocks sqs DeleteMessageWithContext
+func (s SqsMock) DeleteMessageWithContext(ctx aws.Context, in *sqs.DeleteMessageInput, opts ...request.Option) (*sqs.DeleteMessageOutput, error) {
+	return s.DeleteMessage(in)
+}
+
+// ChangeMessageVisibility -- mocks sqs ChangeMessageVisibility
+func (s SqsMock) ChangeMessageVisibility(in *sqs.ChangeMessageVisibilityInput) (*sqs.ChangeMessageVisibilityOutput, error) {
+
+	if *in.ReceiptHandle == ErrMessageId {
+		return nil, errors.New(ErrMessageFailedChange)
+	}
+
+	return &sqs.ChangeMessageVisibilityOutput{}, nil
+}
+
+// ChangeMessageVisibilityWithContext -- mocks sqs ChangeMessageVisibilityWithContext
+func (s SqsMock) ChangeMessageVisibilityWithContext(ctx aws.Context, in *sqs.ChangeMessageVisibilityInput, opts ...request.Option) (*sqs.ChangeMessageVisibilityOutput, error) {
+	return s.ChangeMessageVisibility(in)
+}
+
 // GetQueueUrl -- mocks sqs GetQueueUrl
 func (s SqsMock) GetQueueUrl(in *sqs.GetQueueUrlInput) (*sqs.GetQueueUrlOutput, error) {
 
@@ -54,6 +79,20 @@ func (s SqsMock) GetQueueUrl(in *sqs.GetQueueUrlInput) (*sqs.GetQueueUrlOutput,
 	}, nil
 }
 
+// SendMessage -- mocks sqs SendMessage
+func (s SqsMock) SendMessage(in *sqs.SendMessageInput) (*sqs.SendMessageOutput, error) {
+	if *in.QueueUrl == SqsQueueUrlPrefix+SqsErrQueueName {
+		return nil, errors.New(ErrMessageFailedSend)
+	}
+
+	return &sqs.SendMessageOutput{MessageId: aws.String(SqsMessageId)}, nil
+}
+
+// SendMessageWithContext -- mocks sqs SendMessageWithContext
+func (s SqsMock) SendMessageWithContext(ctx aws.Context, in *sqs.SendMessageInput, opts ...request.Option) (*sqs.SendMessageOutput, error) {
+	return s.SendMessage(in)
+}
+
 // ReceiveMessage -- mocks sqs ReceiveMessage
 func (s SqsMock) ReceiveMessage(in *sqs.ReceiveMessageInput) (*sqs.ReceiveMessageOutput, error) {
 	if *in.QueueUrl == SqsQueueUrlPrefix+SqsErrQueueName {
@@ -61,8 +100,29 @@ func (s SqsMock) ReceiveMessage(in *sqs.ReceiveMessageInput) (*sqs.ReceiveMessag
 	}
 
 	out := &sqs.ReceiveMessageOutput{
-		Messages: []*sqs.Message{{ReceiptHandle: aws.String(SqsMessageRcptHandle), Body: aws.String(SqsMessageBody)}},
+		Messages: []*sqs.Message{{ReceiptHandle: aws.String(SqsMessageRcptHandle), MessageId: aws.String(SqsMessageId), Body: aws.String(SqsMessageBody),
+			Attributes: map[string]*string{sqs.MessageSystemAttributeNameApproximateReceiveCount: aws.String(SqsMessageRcvCount)}}},
 	}
 
 	return out, nil
 }
+
+// ReceiveMessageWithContext -- mocks sqs ReceiveMessageWithContext
+func (s SqsMock) ReceiveMessageWithContext(ctx aws.Context, in *sqs.ReceiveMessageInput, opts ...request.Option) (*sqs.ReceiveMessageOutput, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return s.ReceiveMessage(in)
+}
+
+// GetQueueAttributesWithContext -- mocks sqs GetQueueAttributesWithContext
+func (s SqsMock) GetQueueAttributesWithContext(ctx aws.Context, in *sqs.GetQueueAttributesInput, opts ...request.Option) (*sqs.GetQueueAttributesOutput, error) {
+	if *in.QueueUrl == SqsQueueUrlPrefix+SqsErrQueueName {
+		return nil, errors.New(ErrMessageFailedGetAttr)
+	}
+
+	return &sqs.GetQueueAttributesOutput{
+		Attributes: map[string]*string{sqs.QueueAttributeNameQueueArn: aws.String("arn:aws:sqs:us-east-1:12345:" + SqsQueueName)},
+	}, nil
+}
@@ -1,11 +1,13 @@
 package sqs
 
 import (
+	"context"
 	"errors"
 	"testing"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/sqs"
 	"github.com/stretchr/testify/require"
 )
 
@@ -68,6 +70,37 @@ func TestDeleteSQSMessage(t *testing.T) {
 	}
 }
 
+func TestChangeSQSMessageVisibility(t *testing.T) {
+	svc := &SQSService{
+		Session:   &session.Session{},
+		SQSClient: &SqsMock{},
+	}
+
+	testCases := map[string]struct {
+		messageId string
+		err       error
+	}{
+		"successful change": {
+			messageId: "1",
+			err:       nil,
+		},
+		"failed change": {
+			messageId: ErrMessageId,
+			err:       errors.New(ErrMessageFailedChange),
+		},
+	}
+
+	for _, tc := range testCases {
+		err := svc.ChangeSQSMessageVisibility(tc.messageId, 0)
+
+		if tc.err == nil {
+			require.NoError(t, err)
+		} else {
+			require.Equal(t, tc.err, err)
+		}
+	}
+}
+
 func TestGetSQSMessage(t *testing.T) {
 	svc := &SQSService{
 		Session:   &session.Session{},
@@ -97,6 +130,139 @@ func TestGetSQSMessage(t *testing.T) {
 			require.NotNil(t, out)
 			require.Equal(t, len(out.Messages), 1)
 			require.Equal(t, out.Messages[0].Body, SqsMessageBody)
+			require.Equal(t, out.Messages[0].ReceiveCount, int64(2))
+		} else {
+			require.Equal(t, tc.err, err)
+		}
+	}
+}
+
+func TestMessageAttributes(t *testing.T) {
+	testCases := map[string]struct {
+		message *sqs.Message
+		want    map[string]string
+	}{
+		"string and number attributes": {
+			message: &sqs.Message{MessageAttributes: map[string]*sqs.MessageAttributeValue{
+				"type":     {DataType: aws.String("String"), StringValue: aws.String("order.created")},
+				"priority": {DataType: aws.String("Number.int"), StringValue: aws.String("5")},
+				"payload":  {DataType: aws.String("Binary"), BinaryValue: []byte("raw")},
+			}},
+			want: map[string]string{"type": "order.created", "priority": "5"},
+		},
+		"no attributes": {
+			message: &sqs.Message{},
+		},
+	}
+
+	for name, tc := range testCases {
+		require.Equal(t, tc.want, messageAttributes(tc.message), name)
+	}
+}
+
+func TestDeadLetterSQSMessage(t *testing.T) {
+	testCases := map[string]struct {
+		deadLetterQueueUrl *string
+		messageId          string
+		err                error
+	}{
+		"successful dead-letter": {
+			deadLetterQueueUrl: aws.String(SqsQueueUrlPrefix + SqsQueueName),
+			messageId:          "1",
+			err:                nil,
+		},
+		"no dead-letter queue": {
+			deadLetterQueueUrl: nil,
+			messageId:          "1",
+			err:                ErrNoDeadLetterQueue,
+		},
+		"failed send": {
+			deadLetterQueueUrl: aws.String(SqsQueueUrlPrefix + SqsErrQueueName),
+			messageId:          "1",
+			err:                errors.New(ErrMessageFailedSend),
+		},
+		"failed delete": {
+			deadLetterQueueUrl: aws.String(SqsQueueUrlPrefix + SqsQueueName),
+			messageId:          ErrMessageId,
+			err:                errors.New(ErrMessageFailedDelete),
+		},
+	}
+
+	for _, tc := range testCases {
+		svc := &SQSService{
+			Session:            &session.Session{},
+			SQSClient:          &SqsMock{},
+			DeadLetterQueueURL: tc.deadLetterQueueUrl,
+		}
+
+		err := svc.DeadLetterSQSMessage(tc.messageId, SqsMessageBody, "reason")
+
+		if tc.err == nil {
+			require.NoError(t, err)
+		} else {
+			require.Equal(t, tc.err, err)
+		}
+	}
+}
+
+func TestCheckSQSQueue(t *testing.T) {
+	testCases := map[string]struct {
+		queueUrl *string
+		err      error
+	}{
+		"reachable queue": {
+			queueUrl: aws.String(SqsQueueUrlPrefix + SqsQueueName),
+			err:      nil,
+		},
+		"unreachable queue": {
+			queueUrl: aws.String(SqsQueueUrlPrefix + SqsErrQueueName),
+			err:      errors.New(ErrMessageFailedGetAttr),
+		},
+	}
+
+	for _, tc := range testCases {
+		svc := &SQSService{
+			Session:   &session.Session{},
+			SQSClient: &SqsMock{},
+		}
+
+		err := svc.CheckSQSQueue(context.Background(), tc.queueUrl)
+
+		if tc.err == nil {
+			require.NoError(t, err)
+		} else {
+			require.Equal(t, tc.err, err)
+		}
+	}
+}
+
+func TestSendSQSMessage(t *testing.T) {
+	testCases := map[string]struct {
+		queueUrl *string
+		err      error
+	}{
+		"successful send": {
+			queueUrl: aws.String(SqsQueueUrlPrefix + SqsQueueName),
+			err:      nil,
+		},
+		"failed send": {
+			queueUrl: aws.String(SqsQueueUrlPrefix + SqsErrQueueName),
+			err:      errors.New(ErrMessageFailedSend),
+		},
+	}
+
+	for _, tc := range testCases {
+		svc := &SQSService{
+			Session:   &session.Session{},
+			SQSClient: &SqsMock{},
+			QueueURL:  tc.queueUrl,
+		}
+
+		messageID, err := svc.SendSQSMessage(SqsMessageBody, 0)
+
+		if tc.err == nil {
+			require.NoError(t, err)
+			require.Equal(t, SqsMessageId, messageID)
 		} else {
 			require.Equal(t, tc.err, err)
 		}
@@ -0,0 +1,119 @@
+package sqs
+
+import (
+	"context"
+
+	"github.com/alvinlucillo/sqs-processor/internal/tracing"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/sqs"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.20.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	tracerName = "github.com/alvinlucillo/sqs-processor/internal/sqs"
+)
+
+// spanKey - context key of the span started for an aws call; internally used
+type spanKey struct{}
+
+// traceCalls - wraps every aws call made through the handlers in a client span, including its retries
+func traceCalls(handlers *request.Handlers) {
+	handlers.Validate.PushFrontNamed(request.NamedHandler{
+		Name: "sqsservice.tracing.start",
+		Fn: func(r *request.Request) {
+			ctx, span := tracing.Tracer(tracerName).Start(r.Context(), "SQS."+r.Operation.Name,
+				trace.WithSpanKind(trace.SpanKindClient),
+				trace.WithAttributes(
+					semconv.RPCSystemKey.String("aws-api"),
+					semconv.RPCService("SQS"),
+					semconv.RPCMethod(r.Operation.Name),
+				))
+
+			r.SetContext(context.WithValue(ctx, spanKey{}, span))
+		},
+	})
+
+	handlers.Complete.PushBackNamed(request.NamedHandler{
+		Name: "sqsservice.tracing.end",
+		Fn: func(r *request.Request) {
+			span, ok := r.Context().Value(spanKey{}).(trace.Span)
+			if !ok {
+				return
+			}
+
+			if r.RequestID != "" {
+				span.SetAttributes(attribute.String("aws.request_id", r.RequestID))
+			}
+			span.SetAttributes(attribute.Int("aws.retry_count", r.RetryCount))
+
+			if r.Error != nil {
+				span.RecordError(r.Error)
+
+				code := "Unknown"
+				if awsErr, ok := r.Error.(awserr.Error); ok {
+					code = awsErr.Code()
+				}
+				span.SetStatus(codes.Error, code)
+			}
+
+			span.End()
+		},
+	})
+}
+
+// traceAttributes - returns the trace context of ctx as the message attributes propagating it; internally used
+func traceAttributes(ctx context.Context, attributes map[string]*sqs.MessageAttributeValue) map[string]*sqs.MessageAttributeValue {
+	fields := tracing.Inject(ctx)
+	if len(fields) == 0 {
+		return attributes
+	}
+
+	if attributes == nil {
+		attributes = make(map[string]*sqs.MessageAttributeValue, len(fields))
+	}
+
+	for name, value := range fields {
+		attributes[name] = &sqs.MessageAttributeValue{DataType: aws.String("String"), StringValue: aws.String(value)}
+	}
+
+	return attributes
+}
+
+// traceSystemAttributes - returns the AWSTraceHeader system attribute of ctx for x-ray; nil if ctx isn't traced
+func traceSystemAttributes(ctx context.Context) map[string]*sqs.MessageSystemAttributeValue {
+	header := tracing.AWSTraceHeader(ctx)
+	if header == "" {
+		return nil
+	}
+
+	return map[string]*sqs.MessageSystemAttributeValue{
+		sqs.MessageSystemAttributeNameForSendsAwstraceHeader: {DataType: aws.String("String"), StringValue: aws.String(header)},
+	}
+}
+
+// traceContext - returns the trace context the message was sent with; internally used
+// the message attributes set by traceAttributes win over the AWSTraceHeader set by x-ray instrumented producers
+func traceContext(msg *sqs.Message) map[string]string {
+	fields := make(map[string]string)
+
+	for _, name := range tracing.Fields() {
+		if value, ok := msg.MessageAttributes[name]; ok && value != nil && value.StringValue != nil {
+			fields[name] = *value.StringValue
+		}
+	}
+
+	if len(fields) > 0 {
+		return fields
+	}
+
+	if header, ok := msg.Attributes[sqs.MessageSystemAttributeNameAwstraceHeader]; ok && header != nil {
+		return tracing.FromAWSTraceHeader(*header)
+	}
+
+	return nil
+}
@@ -0,0 +1,147 @@
+package sqs
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alvinlucillo/sqs-processor/internal/tracing"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/client/metadata"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/sqs"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	traceParent = "00-5759e988bd862e3fe1be46a994272793-53995c3f42cd8ad8-01"
+)
+
+// sendRecorder - mock keeping the last message sent
+type sendRecorder struct {
+	SqsMock
+	input *sqs.SendMessageInput
+}
+
+func (s *sendRecorder) SendMessageWithContext(ctx aws.Context, in *sqs.SendMessageInput, opts ...request.Option) (*sqs.SendMessageOutput, error) {
+	s.input = in
+	return s.SqsMock.SendMessageWithContext(ctx, in, opts...)
+}
+
+func TestTraceCalls(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	defer otel.SetTracerProvider(trace.NewNoopTracerProvider())
+
+	testCases := map[string]struct {
+		err        error
+		wantStatus codes.Code
+	}{
+		"successful call": {
+			wantStatus: codes.Unset,
+		},
+		"failed call": {
+			err:        awserr.New(sqs.ErrCodeReceiptHandleIsInvalid, "invalid", nil),
+			wantStatus: codes.Error,
+		},
+	}
+
+	for name, tc := range testCases {
+		handlers := request.Handlers{}
+		traceCalls(&handlers)
+		handlers.Send.PushBack(func(r *request.Request) {
+			r.Error = tc.err
+		})
+
+		parent := tracing.Extract(context.Background(), map[string]string{"traceparent": traceParent})
+
+		r := request.New(aws.Config{}, metadata.ClientInfo{}, handlers, nil, &request.Operation{Name: "DeleteMessage"}, nil, nil)
+		r.SetContext(parent)
+		require.Equal(t, tc.err, r.Send(), name)
+
+		spans := recorder.Ended()
+		require.NotEmpty(t, spans, name)
+		span := spans[len(spans)-1]
+
+		require.Equal(t, "SQS.DeleteMessage", span.Name(), name)
+		require.Equal(t, trace.SpanKindClient, span.SpanKind(), name)
+		require.Equal(t, trace.SpanContextFromContext(parent).TraceID(), span.SpanContext().TraceID(), name)
+		require.Equal(t, trace.SpanContextFromContext(parent).SpanID(), span.Parent().SpanID(), name)
+		require.Equal(t, tc.wantStatus, span.Status().Code, name)
+	}
+}
+
+func TestTraceContext(t *testing.T) {
+	testCases := map[string]struct {
+		message *sqs.Message
+		want    map[string]string
+	}{
+		"message attributes": {
+			message: &sqs.Message{MessageAttributes: map[string]*sqs.MessageAttributeValue{
+				"traceparent": {DataType: aws.String("String"), StringValue: aws.String(traceParent)},
+				"other":       {DataType: aws.String("String"), StringValue: aws.String("value")},
+			}},
+			want: map[string]string{"traceparent": traceParent},
+		},
+		"aws trace header": {
+			message: &sqs.Message{Attributes: map[string]*string{
+				sqs.MessageSystemAttributeNameAwstraceHeader: aws.String("Root=1-5759e988-bd862e3fe1be46a994272793;Parent=53995c3f42cd8ad8;Sampled=1"),
+			}},
+			want: map[string]string{"traceparent": traceParent},
+		},
+		"message attributes over aws trace header": {
+			message: &sqs.Message{
+				MessageAttributes: map[string]*sqs.MessageAttributeValue{
+					"traceparent": {DataType: aws.String("String"), StringValue: aws.String(traceParent)},
+				},
+				Attributes: map[string]*string{
+					sqs.MessageSystemAttributeNameAwstraceHeader: aws.String("Root=1-00000000-000000000000000000000001;Parent=0000000000000001;Sampled=1"),
+				},
+			},
+			want: map[string]string{"traceparent": traceParent},
+		},
+		"untraced message": {
+			message: &sqs.Message{},
+		},
+	}
+
+	for name, tc := range testCases {
+		require.Equal(t, tc.want, traceContext(tc.message), name)
+	}
+}
+
+func TestSendSQSMessageWithContext(t *testing.T) {
+	mock := &sendRecorder{}
+	service := &SQSService{
+		Session:   &session.Session{},
+		SQSClient: mock,
+		QueueURL:  aws.String(SqsQueueUrlPrefix + SqsQueueName),
+	}
+
+	ctx := tracing.Extract(context.Background(), map[string]string{"traceparent": traceParent})
+
+	_, err := service.SendSQSMessageWithContext(ctx, SqsMessageBody, 0)
+	require.NoError(t, err)
+
+	require.Equal(t, traceParent, aws.StringValue(mock.input.MessageAttributes["traceparent"].StringValue))
+	require.Equal(t, "Root=1-5759e988-bd862e3fe1be46a994272793;Parent=53995c3f42cd8ad8;Sampled=1",
+		aws.StringValue(mock.input.MessageSystemAttributes[sqs.MessageSystemAttributeNameForSendsAwstraceHeader].StringValue))
+
+	// untraced messages are sent without attributes
+	_, err = service.SendSQSMessageWithContext(context.Background(), SqsMessageBody, 0)
+	require.NoError(t, err)
+	require.Nil(t, mock.input.MessageAttributes)
+	require.Nil(t, mock.input.MessageSystemAttributes)
+
+	// the trace context is sent along with the dead-letter reason
+	service.DeadLetterQueueURL = aws.String(SqsQueueUrlPrefix + "dead-letter")
+	require.NoError(t, service.DeadLetterSQSMessageWithContext(ctx, SqsMessageRcptHandle, SqsMessageBody, "malformed"))
+	require.Equal(t, traceParent, aws.StringValue(mock.input.MessageAttributes["traceparent"].StringValue))
+	require.Equal(t, "malformed", aws.StringValue(mock.input.MessageAttributes[DeadLetterReasonAttribute].StringValue))
+}
@@ -2,15 +2,32 @@ package sqs
 
 import "github.com/rs/zerolog"
 
+// sqs limits on a single ReceiveMessage call
+const (
+	MaxBatchSize   = 10
+	MaxWaitingTime = 20
+)
+
 type SQSReceiveMsgConfig struct {
 	VisibilityTimeout int64
 	WaitingTime       int64
 	MaximumMessages   int64
+	// number of seconds to keep aggregating receives when MaximumMessages is over 10
+	// defaults to WaitingTime
+	MaximumWaitTime int64
 }
 
 type SQSResultMessage struct {
-	ID   string
-	Body string
+	// receipt handle used to delete or change the visibility of the message
+	ID string
+	// id assigned by sqs; the same across receives of the message
+	MessageID    string
+	Body         string
+	ReceiveCount int64
+	// trace context the message was sent with, e.g. traceparent; nil if it wasn't traced
+	TraceContext map[string]string
+	// string and number message attributes the message was sent with; binary ones are left out
+	Attributes map[string]string
 }
 
 type SQSResult struct {
@@ -18,10 +35,12 @@ type SQSResult struct {
 }
 
 type SQSConfig struct {
-	QueueName          string
-	Profile            string
-	Logger             zerolog.Logger
-	Region             string
-	AwsAccessKeyId     string
-	AwsSecretAccessKey string
+	QueueName string
+	// queue receiving messages that can't be processed; optional
+	DeadLetterQueueName string
+	Profile             string
+	Logger              zerolog.Logger
+	Region              string
+	AwsAccessKeyId      string
+	AwsSecretAccessKey  string
 }
@@ -0,0 +1,354 @@
+package sqsservice
+
+import (
+	"context"
+	"sync"
+
+	"github.com/alvinlucillo/sqs-processor/internal/logging"
+	"github.com/alvinlucillo/sqs-processor/internal/sqs"
+	"github.com/alvinlucillo/sqs-processor/internal/tracing"
+	pb "github.com/alvinlucillo/sqs-processor/protogen/sqs"
+)
+
+// consumer - state of a single Consume stream
+type consumer struct {
+	mu sync.Mutex
+	// number of messages the server may still push
+	credits int64
+	// receipt handle -> receive count of messages pushed but not yet acked or nacked
+	inflight map[string]int64
+	// signaled whenever the consumer grants credits
+	granted chan struct{}
+	// serializes sends since the stream is written by both the push loop and the request handler
+	sendMu sync.Mutex
+	stream pb.SQSService_ConsumeServer
+	// set once Consume returns since the stream must not be written after that
+	closed bool
+}
+
+// grant - adds credits for the consumer
+func (c *consumer) grant(credits int64) {
+	if credits <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	c.credits += credits
+	c.mu.Unlock()
+
+	select {
+	case c.granted <- struct{}{}:
+	default:
+	}
+}
+
+// take - reserves up to max credits; returns 0 if the consumer has none
+func (c *consumer) take(max int64) int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.credits < max {
+		max = c.credits
+	}
+	c.credits -= max
+
+	return max
+}
+
+// refund - returns reserved credits that weren't used
+func (c *consumer) refund(credits int64) {
+	c.grant(credits)
+}
+
+// waitCredits - blocks until the consumer has credits or ctx is done
+func (c *consumer) waitCredits(ctx context.Context, max int64) (int64, error) {
+	for {
+		if credits := c.take(max); credits > 0 {
+			return credits, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return 0, ctx.Err()
+		case <-c.granted:
+		}
+	}
+}
+
+// track - marks the message as leased to the consumer
+func (c *consumer) track(id string, receiveCount int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.inflight[id] = receiveCount
+}
+
+// receiveCount - returns the receive count of a message leased to the consumer and whether it's leased
+func (c *consumer) receiveCount(id string) (int64, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	receiveCount, ok := c.inflight[id]
+
+	return receiveCount, ok
+}
+
+// untrack - removes the message from the consumer's leases
+// returns the message's receive count and whether it was leased
+func (c *consumer) untrack(id string) (int64, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	receiveCount, ok := c.inflight[id]
+	delete(c.inflight, id)
+
+	return receiveCount, ok
+}
+
+// drain - removes and returns every message still leased to the consumer
+func (c *consumer) drain() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	ids := make([]string, 0, len(c.inflight))
+	for id := range c.inflight {
+		ids = append(ids, id)
+	}
+	c.inflight = make(map[string]int64)
+
+	return ids
+}
+
+// send - writes the response to the stream; no-op once the stream is closed
+func (c *consumer) send(resp *pb.SQSConsumeResponse) error {
+	c.sendMu.Lock()
+	defer c.sendMu.Unlock()
+
+	if c.closed {
+		return nil
+	}
+
+	return c.stream.Send(resp)
+}
+
+// close - stops any further writes to the stream
+func (c *consumer) close() {
+	c.sendMu.Lock()
+	defer c.sendMu.Unlock()
+
+	c.closed = true
+}
+
+// Consume - pushes sqs messages to the consumer and applies its acks, nacks and lease extensions
+// messages are only pushed while the consumer has credits left
+// unacked messages are released back to the queue when the stream breaks
+func (s *SQSServer) Consume(stream pb.SQSService_ConsumeServer) error {
+	l := logging.WithRequestID(stream.Context(), s.Logger).With().Str("function", "Consume").Logger()
+
+	ctx, cancel := context.WithCancel(stream.Context())
+	defer cancel()
+
+	go func() {
+		select {
+		case <-s.shutdown:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	// the first request carries the receive settings
+	settings, err := stream.Recv()
+	if err != nil {
+		l.Err(err).Msg("Failed to receive consumer settings")
+		return err
+	}
+
+	c := &consumer{inflight: make(map[string]int64), granted: make(chan struct{}, 1), stream: stream}
+
+	// the requests still being applied are waited for so their acks and nacks don't race the release of the leases
+	var handler sync.WaitGroup
+
+	defer s.releaseLeases(c)
+	defer c.close()
+	defer handler.Wait()
+	defer cancel()
+
+	requests := make(chan *pb.SQSConsumeRequest)
+
+	// reads the consumer's requests until the stream breaks or the consumer closes its side
+	// can't be waited for since Recv only returns once the stream ends, after Consume returned
+	go func() {
+		defer close(requests)
+
+		for {
+			req, err := stream.Recv()
+			if err != nil {
+				return
+			}
+
+			select {
+			case requests <- req:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	// applies the consumer's requests until the stream ends
+	handler.Add(1)
+	go func() {
+		defer handler.Done()
+		defer cancel()
+
+		s.handleConsumeRequest(stream.Context(), c, settings)
+
+		for {
+			select {
+			case req, ok := <-requests:
+				if !ok {
+					return
+				}
+
+				s.handleConsumeRequest(stream.Context(), c, req)
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	waitingTime, batchSize := receiveLimits(settings.WaitTime, settings.MaximumNumberOfMessages)
+
+	visibilityTimeout := settings.VisibilityTimeout
+	if visibilityTimeout <= 0 {
+		visibilityTimeout = defaultVisibilityTimeout
+	}
+
+	l.Debug().Msg("Consumer connected")
+
+	for {
+		credits, err := c.waitCredits(ctx, batchSize)
+		if err != nil {
+			break
+		}
+
+		sqsConfig := &sqs.SQSReceiveMsgConfig{
+			VisibilityTimeout: visibilityTimeout,
+			WaitingTime:       waitingTime,
+			MaximumMessages:   credits,
+		}
+
+		// the receive isn't cut short by the consumer or a shutdown since the messages it gets would stay invisible
+		messages, err := s.SQSService.GetSQSMessageWithContext(tracing.Detach(ctx), sqsConfig)
+		if err != nil {
+			l.Err(err).Msg("Failed to get SQS message")
+			return err
+		}
+
+		// leased so they're released with the others once the stream ends
+		if ctx.Err() != nil {
+			for _, message := range messages.Messages {
+				c.track(message.ID, message.ReceiveCount)
+			}
+
+			break
+		}
+
+		c.refund(credits - int64(len(messages.Messages)))
+
+		if len(messages.Messages) == 0 {
+			continue
+		}
+
+		resp := &pb.SQSConsumeResponse{}
+
+		for _, message := range messages.Messages {
+			c.track(message.ID, message.ReceiveCount)
+
+			resp.Messages = append(resp.Messages, responseMessage(message))
+		}
+
+		if err := c.send(resp); err != nil {
+			l.Err(err).Msg("Failed to send messages to consumer")
+			return err
+		}
+	}
+
+	l.Debug().Msg("Consumer disconnected")
+
+	return nil
+}
+
+// handleConsumeRequest - applies the credits, acks, nacks and lease extensions of a consumer request
+// failures are reported back to the consumer; sqs calls are cancelled with ctx
+func (s *SQSServer) handleConsumeRequest(ctx context.Context, c *consumer, req *pb.SQSConsumeRequest) {
+	l := logging.WithRequestID(ctx, s.Logger).With().Str("function", "handleConsumeRequest").Logger()
+
+	failures := make([]*pb.SQSConsumeFailure, 0)
+
+	for _, id := range req.AckIDs {
+		if err := s.SQSService.DeleteSQSMessageWithContext(ctx, id); err != nil {
+			l.Err(err).Msgf("Failed to delete message: %v", id)
+			failures = append(failures, &pb.SQSConsumeFailure{MessageID: id, Error: err.Error()})
+			continue
+		}
+
+		c.untrack(id)
+	}
+
+	for _, nack := range req.Nacks {
+		receiveCount, ok := c.receiveCount(nack.MessageID)
+		if !ok || nack.ReceiveCount > 0 {
+			receiveCount = nack.ReceiveCount
+		}
+
+		delay, err := s.nackDelay(nack, receiveCount)
+		if err != nil {
+			l.Err(err).Msgf("Invalid delay to release message: %v", nack.MessageID)
+			failures = append(failures, &pb.SQSConsumeFailure{MessageID: nack.MessageID, Error: err.Error()})
+			continue
+		}
+
+		// still leased if it fails, so it's released once the stream breaks
+		if err := s.SQSService.ChangeSQSMessageVisibilityWithContext(ctx, nack.MessageID, delay); err != nil {
+			l.Err(err).Msgf("Failed to release message: %v", nack.MessageID)
+			failures = append(failures, &pb.SQSConsumeFailure{MessageID: nack.MessageID, Error: err.Error()})
+			continue
+		}
+
+		c.untrack(nack.MessageID)
+	}
+
+	for _, extension := range req.Extensions {
+		if err := checkVisibilityTimeout(extension.VisibilityTimeout); err != nil {
+			l.Err(err).Msgf("Invalid visibility timeout to extend lease of message: %v", extension.MessageID)
+			failures = append(failures, &pb.SQSConsumeFailure{MessageID: extension.MessageID, Error: err.Error()})
+			continue
+		}
+
+		if err := s.SQSService.ChangeSQSMessageVisibilityWithContext(ctx, extension.MessageID, extension.VisibilityTimeout); err != nil {
+			l.Err(err).Msgf("Failed to extend lease of message: %v", extension.MessageID)
+			failures = append(failures, &pb.SQSConsumeFailure{MessageID: extension.MessageID, Error: err.Error()})
+		}
+	}
+
+	// credits are granted last so the push loop sees acks before pushing more
+	c.grant(req.Credits)
+
+	if len(failures) > 0 {
+		if err := c.send(&pb.SQSConsumeResponse{Failures: failures}); err != nil {
+			l.Err(err).Msg("Failed to report failures to consumer")
+		}
+	}
+}
+
+// releaseLeases - makes the consumer's unacked messages visible again so other consumers can receive them
+// not bound to the stream's context since it's usually already cancelled by the consumer going away
+func (s *SQSServer) releaseLeases(c *consumer) {
+	l := s.Logger.With().Str("function", "releaseLeases").Logger()
+
+	for _, id := range c.drain() {
+		if err := s.SQSService.ChangeSQSMessageVisibility(id, 0); err != nil {
+			l.Err(err).Msgf("Failed to release message: %v", id)
+		}
+	}
+}
@@ -0,0 +1,188 @@
+package sqsservice
+
+import (
+	"context"
+	"io"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alvinlucillo/sqs-processor/internal/sqs"
+	pb "github.com/alvinlucillo/sqs-processor/protogen/sqs"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// consumeStreamMock - feeds consumer requests to Consume and collects its responses
+type consumeStreamMock struct {
+	grpc.ServerStream
+	ctx       context.Context
+	requests  chan *pb.SQSConsumeRequest
+	responses chan *pb.SQSConsumeResponse
+}
+
+func (s *consumeStreamMock) Context() context.Context {
+	return s.ctx
+}
+
+func (s *consumeStreamMock) Send(resp *pb.SQSConsumeResponse) error {
+	s.responses <- resp
+	return nil
+}
+
+func (s *consumeStreamMock) Recv() (*pb.SQSConsumeRequest, error) {
+	select {
+	case req, ok := <-s.requests:
+		if !ok {
+			return nil, io.EOF
+		}
+		return req, nil
+	case <-s.ctx.Done():
+		return nil, s.ctx.Err()
+	}
+}
+
+func TestConsume(t *testing.T) {
+	client := &uniqueSqsMock{}
+	svc := &sqs.SQSService{
+		Session:   &session.Session{},
+		SQSClient: client,
+		QueueURL:  aws.String(sqs.SqsQueueUrlPrefix + sqs.SqsQueueName),
+	}
+
+	server := &SQSServer{
+		SQSService: svc,
+		shutdown:   make(chan struct{}),
+	}
+
+	stream := &consumeStreamMock{ctx: context.Background(), requests: make(chan *pb.SQSConsumeRequest, 10),
+		responses: make(chan *pb.SQSConsumeResponse, 10)}
+	done := make(chan error)
+
+	go func() {
+		done <- server.Consume(stream)
+	}()
+
+	// no messages are pushed until the consumer grants credits
+	stream.requests <- &pb.SQSConsumeRequest{}
+
+	select {
+	case <-stream.responses:
+		t.Fatal("received message without credits")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	stream.requests <- &pb.SQSConsumeRequest{Credits: 1}
+
+	var resp *pb.SQSConsumeResponse
+	select {
+	case resp = <-stream.responses:
+		require.Len(t, resp.Messages, 1)
+		require.Equal(t, sqs.SqsMessageBody, resp.Messages[0].MessageBody)
+	case <-time.After(time.Second):
+		t.Fatal("expected message after granting credits")
+	}
+
+	// messages are leased for the default visibility timeout when the consumer didn't set one
+	require.Equal(t, int64(defaultVisibilityTimeout), atomic.LoadInt64(&client.visibilityTimeout))
+
+	// failed acks are reported back to the consumer
+	stream.requests <- &pb.SQSConsumeRequest{AckIDs: []string{resp.Messages[0].MessageID, sqs.ErrMessageId}}
+
+	select {
+	case resp = <-stream.responses:
+		require.Len(t, resp.Failures, 1)
+		require.Equal(t, sqs.ErrMessageId, resp.Failures[0].MessageID)
+		require.Equal(t, sqs.ErrMessageFailedDelete, resp.Failures[0].Error)
+	case <-time.After(time.Second):
+		t.Fatal("expected failure report")
+	}
+
+	// nacks with delays sqs wouldn't accept are reported back too
+	stream.requests <- &pb.SQSConsumeRequest{Nacks: []*pb.SQSNackMessageRequest{{MessageID: sqs.SqsMessageId, DelaySeconds: aws.Int64(maxVisibilityTimeout + 1)}}}
+
+	select {
+	case resp = <-stream.responses:
+		require.Len(t, resp.Failures, 1)
+		require.Equal(t, sqs.SqsMessageId, resp.Failures[0].MessageID)
+		require.Contains(t, resp.Failures[0].Error, "delay must be between 0 and 43200 seconds")
+	case <-time.After(time.Second):
+		t.Fatal("expected failure report")
+	}
+
+	// closing the consumer's side ends the stream cleanly
+	close(stream.requests)
+
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("stream didn't end")
+	}
+}
+
+func TestHandleConsumeRequest(t *testing.T) {
+	server := &SQSServer{
+		SQSService: &sqs.SQSService{
+			Session:   &session.Session{},
+			SQSClient: &sqs.SqsMock{},
+			QueueURL:  aws.String(sqs.SqsQueueUrlPrefix + sqs.SqsQueueName),
+		},
+	}
+
+	stream := &consumeStreamMock{ctx: context.Background(), responses: make(chan *pb.SQSConsumeResponse, 1)}
+	c := &consumer{inflight: map[string]int64{sqs.SqsMessageId: 1, sqs.ErrMessageId: 2}, granted: make(chan struct{}, 1), stream: stream}
+
+	server.handleConsumeRequest(context.Background(), c, &pb.SQSConsumeRequest{
+		Nacks:      []*pb.SQSNackMessageRequest{{MessageID: sqs.SqsMessageId}, {MessageID: sqs.ErrMessageId}},
+		Extensions: []*pb.SQSExtendLeaseRequest{{MessageID: sqs.SqsMessageId, VisibilityTimeout: maxVisibilityTimeout + 1}},
+	})
+
+	resp := <-stream.responses
+	require.Len(t, resp.Failures, 2)
+
+	// a message whose nack failed is still leased, so it's released once the stream breaks
+	require.Equal(t, sqs.ErrMessageId, resp.Failures[0].MessageID)
+	require.Equal(t, []string{sqs.ErrMessageId}, c.drain())
+
+	// extensions sqs wouldn't accept are rejected before calling it
+	require.Equal(t, sqs.SqsMessageId, resp.Failures[1].MessageID)
+	require.Equal(t, status.Error(codes.InvalidArgument, "visibility timeout must be between 0 and 43200 seconds: 43201").Error(), resp.Failures[1].Error)
+}
+
+func TestConsumeReleasesMidReceive(t *testing.T) {
+	client := &uniqueSqsMock{receiving: make(chan struct{}, 1), unblock: make(chan struct{})}
+	server := &SQSServer{
+		SQSService: &sqs.SQSService{
+			Session:   &session.Session{},
+			SQSClient: client,
+			QueueURL:  aws.String(sqs.SqsQueueUrlPrefix + sqs.SqsQueueName),
+		},
+		shutdown: make(chan struct{}),
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	stream := &consumeStreamMock{ctx: ctx, requests: make(chan *pb.SQSConsumeRequest, 1), responses: make(chan *pb.SQSConsumeResponse, 10)}
+	stream.requests <- &pb.SQSConsumeRequest{Credits: 1}
+
+	done := make(chan error)
+	go func() {
+		done <- server.Consume(stream)
+	}()
+
+	// the consumer goes away while sqs is being long polled
+	<-client.receiving
+	cancel()
+	close(client.unblock)
+
+	require.NoError(t, <-done)
+
+	// the message the receive got is visible again right away instead of once its visibility timeout lapses
+	require.Equal(t, int64(1), atomic.LoadInt64(&client.released))
+}
@@ -0,0 +1,235 @@
+package sqsservice
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"io"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/alvinlucillo/sqs-processor/internal/logging"
+	"github.com/alvinlucillo/sqs-processor/internal/ratelimit"
+	"github.com/alvinlucillo/sqs-processor/internal/tracing"
+	pb "github.com/alvinlucillo/sqs-processor/protogen/sqs"
+
+	"github.com/rs/zerolog"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	// upper bound of a request body; sqs messages are at most 256 KiB before json escaping
+	maxGatewayBodySize = 1 << 20
+)
+
+//go:embed openapi.json
+var openAPI []byte
+
+// gatewayRoute - http route mapped onto an rpc
+// the request and response bodies are the rpc's messages in their proto3 json form
+type gatewayRoute struct {
+	// grpc method name the route goes through the interceptors as, e.g. /sqs.SQSService/ReceiveMessage
+	fullMethod string
+	newRequest func() proto.Message
+	handler    grpc.UnaryHandler
+}
+
+// Gateway - http/json api of the SQSService rpcs for business containers that can't use grpc
+// calls go through the same interceptors as grpc calls, e.g. authentication
+type Gateway struct {
+	Server     *SQSServer
+	HTTPServer *http.Server
+	Listener   net.Listener
+	Logger     zerolog.Logger
+}
+
+// NewGateway - creates new Gateway serving on listener
+func NewGateway(logger zerolog.Logger, server *SQSServer, listener net.Listener) *Gateway {
+	g := &Gateway{Server: server, Listener: listener, Logger: logger}
+
+	mux := http.NewServeMux()
+	for path, route := range g.routes() {
+		mux.Handle(path, g.handle(route))
+	}
+	mux.HandleFunc("/openapi.json", serveOpenAPI)
+
+	// no write timeout since receives long poll for as long as the caller asks
+	g.HTTPServer = &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+
+	return g
+}
+
+func (g *Gateway) routes() map[string]gatewayRoute {
+	s := g.Server
+
+	return map[string]gatewayRoute{
+		"/v1/messages/receive": {
+			fullMethod: pb.SQSService_ReceiveMessage_FullMethodName,
+			newRequest: func() proto.Message { return &pb.SQSReceiveMessageRequest{} },
+			handler: func(ctx context.Context, req interface{}) (interface{}, error) {
+				return s.ReceiveMessage(ctx, req.(*pb.SQSReceiveMessageRequest))
+			},
+		},
+		"/v1/messages/delete": {
+			fullMethod: pb.SQSService_DeleteMessage_FullMethodName,
+			newRequest: func() proto.Message { return &pb.SQSDeleteMessageRequest{} },
+			handler: func(ctx context.Context, req interface{}) (interface{}, error) {
+				return s.DeleteMessage(ctx, req.(*pb.SQSDeleteMessageRequest))
+			},
+		},
+		"/v1/messages/send": {
+			fullMethod: pb.SQSService_SendMessage_FullMethodName,
+			newRequest: func() proto.Message { return &pb.SQSSendMessageRequest{} },
+			handler: func(ctx context.Context, req interface{}) (interface{}, error) {
+				return s.SendMessage(ctx, req.(*pb.SQSSendMessageRequest))
+			},
+		},
+		"/v1/messages/visibility": {
+			fullMethod: pb.SQSService_ChangeMessageVisibility_FullMethodName,
+			newRequest: func() proto.Message { return &pb.SQSChangeMessageVisibilityRequest{} },
+			handler: func(ctx context.Context, req interface{}) (interface{}, error) {
+				return s.ChangeMessageVisibility(ctx, req.(*pb.SQSChangeMessageVisibilityRequest))
+			},
+		},
+	}
+}
+
+// handle - decodes the body into the rpc's request, calls the rpc and encodes its response
+func (g *Gateway) handle(route gatewayRoute) http.Handler {
+	l := g.Logger.With().Str("function", "handle").Str("method", route.fullMethod).Logger()
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			writeGatewayError(w, status.Error(codes.Unimplemented, "method not allowed"), http.StatusMethodNotAllowed)
+			return
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxGatewayBodySize))
+		if err != nil {
+			writeGatewayError(w, status.Error(codes.InvalidArgument, err.Error()), 0)
+			return
+		}
+
+		req := route.newRequest()
+		if len(body) > 0 {
+			if err := protojson.Unmarshal(body, req); err != nil {
+				writeGatewayError(w, status.Error(codes.InvalidArgument, err.Error()), 0)
+				return
+			}
+		}
+
+		// headers the interceptors read from the grpc metadata
+		md := metadata.MD{}
+		if authorization := r.Header.Get("Authorization"); authorization != "" {
+			md.Set("authorization", authorization)
+		}
+		for _, field := range tracing.Fields() {
+			if value := r.Header.Get(field); value != "" {
+				md.Set(field, value)
+			}
+		}
+
+		// the interceptor can't return the request id in grpc headers for calls of the gateway
+		requestID := r.Header.Get(logging.RequestIDKey)
+		if requestID == "" {
+			requestID = logging.NewRequestID()
+		}
+		md.Set(logging.RequestIDKey, requestID)
+		w.Header().Set(logging.RequestIDKey, requestID)
+		ctx := metadata.NewIncomingContext(r.Context(), md)
+
+		resp, err := g.Server.invoke(ctx, route.fullMethod, req, route.handler)
+		if err != nil {
+			writeGatewayError(w, err, 0)
+			return
+		}
+
+		out, err := protojson.MarshalOptions{EmitUnpopulated: true}.Marshal(resp.(proto.Message))
+		if err != nil {
+			l.Err(err).Msg("Failed to encode response")
+			writeGatewayError(w, status.Error(codes.Internal, err.Error()), 0)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(out)
+	})
+}
+
+// writeGatewayError - writes the error's grpc status as json
+// httpStatus overrides the http status derived from the grpc code if it's not 0
+// calls rejected over a rate limit get the wait in whole seconds as Retry-After
+func writeGatewayError(w http.ResponseWriter, err error, httpStatus int) {
+	st := status.Convert(err)
+	if httpStatus == 0 {
+		httpStatus = httpStatusFromCode(st.Code())
+	}
+
+	if retryAfter, ok := ratelimit.RetryAfter(err); ok {
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(httpStatus)
+	json.NewEncoder(w).Encode(map[string]interface{}{"code": st.Code(), "message": st.Message()})
+}
+
+// httpStatusFromCode - http status a grpc code maps to, as in google.api.http transcoding
+func httpStatusFromCode(code codes.Code) int {
+	switch code {
+	case codes.OK:
+		return http.StatusOK
+	case codes.Canceled:
+		// client closed request, as used by nginx
+		return 499
+	case codes.InvalidArgument, codes.FailedPrecondition, codes.OutOfRange:
+		return http.StatusBadRequest
+	case codes.DeadlineExceeded:
+		return http.StatusGatewayTimeout
+	case codes.NotFound:
+		return http.StatusNotFound
+	case codes.AlreadyExists, codes.Aborted:
+		return http.StatusConflict
+	case codes.PermissionDenied:
+		return http.StatusForbidden
+	case codes.Unauthenticated:
+		return http.StatusUnauthorized
+	case codes.ResourceExhausted:
+		return http.StatusTooManyRequests
+	case codes.Unimplemented:
+		return http.StatusNotImplemented
+	case codes.Unavailable:
+		return http.StatusServiceUnavailable
+	}
+
+	return http.StatusInternalServerError
+}
+
+func serveOpenAPI(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(openAPI)
+}
+
+// Serve - serves until Shutdown is called
+func (g *Gateway) Serve() error {
+	if err := g.HTTPServer.Serve(g.Listener); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+
+	return nil
+}
+
+// Shutdown - stops accepting requests and waits for the open ones to finish
+func (g *Gateway) Shutdown(ctx context.Context) error {
+	return g.HTTPServer.Shutdown(ctx)
+}
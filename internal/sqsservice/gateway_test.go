@@ -0,0 +1,205 @@
+package sqsservice
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/alvinlucillo/sqs-processor/internal/auth"
+	"github.com/alvinlucillo/sqs-processor/internal/ratelimit"
+	"github.com/alvinlucillo/sqs-processor/internal/sqs"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+)
+
+func TestGateway(t *testing.T) {
+	tokenFile := filepath.Join(t.TempDir(), "tokens")
+	require.NoError(t, os.WriteFile(tokenFile, []byte("consumer-token consumer\n"), 0o600))
+
+	authenticator, err := auth.NewStaticAuthenticator(zerolog.Nop(), tokenFile)
+	require.NoError(t, err)
+
+	server := &SQSServer{
+		SQSService: &sqs.SQSService{
+			Session:   &session.Session{},
+			SQSClient: &sqs.SqsMock{},
+			QueueURL:  aws.String(sqs.SqsQueueUrlPrefix + sqs.SqsQueueName),
+		},
+		Logger:            zerolog.Nop(),
+		tracker:           newStreamTracker(),
+		unaryInterceptors: []grpc.UnaryServerInterceptor{auth.NewInterceptor(zerolog.Nop(), authenticator, auth.DefaultPolicy()).Unary()},
+	}
+
+	gateway := NewGateway(zerolog.Nop(), server, nil)
+
+	testCases := map[string]struct {
+		method        string
+		path          string
+		body          string
+		authorization string
+		wantStatus    int
+		wantBody      string
+	}{
+		"receive": {
+			method:        http.MethodPost,
+			path:          "/v1/messages/receive",
+			body:          `{"visibility_timeout": "30", "waitTime": 1, "maximumNumberOfMessages": "1"}`,
+			authorization: "Bearer consumer-token",
+			wantStatus:    http.StatusOK,
+			wantBody:      `{"messages":[{"messageID":"message-1","messageBody":"message-body","receiveCount":"2","traceContext":{},"sqsMessageID":"message-id-1","attributes":{}}]}`,
+		},
+		"delete": {
+			method:        http.MethodPost,
+			path:          "/v1/messages/delete",
+			body:          `{"messageID": "message-1"}`,
+			authorization: "Bearer consumer-token",
+			wantStatus:    http.StatusOK,
+			wantBody:      `{}`,
+		},
+		"failed delete": {
+			method:        http.MethodPost,
+			path:          "/v1/messages/delete",
+			body:          `{"messageID": "error-id"}`,
+			authorization: "Bearer consumer-token",
+			wantStatus:    http.StatusInternalServerError,
+			wantBody:      `{"code":2,"message":"failed deleting message"}`,
+		},
+		"visibility": {
+			method:        http.MethodPost,
+			path:          "/v1/messages/visibility",
+			body:          `{"messageID": "message-1", "visibilityTimeout": "60"}`,
+			authorization: "Bearer consumer-token",
+			wantStatus:    http.StatusOK,
+			wantBody:      `{}`,
+		},
+		"send without the producer role": {
+			method:        http.MethodPost,
+			path:          "/v1/messages/send",
+			body:          `{"messageBody": "hello"}`,
+			authorization: "Bearer consumer-token",
+			wantStatus:    http.StatusForbidden,
+		},
+		"missing token": {
+			method:     http.MethodPost,
+			path:       "/v1/messages/receive",
+			wantStatus: http.StatusUnauthorized,
+		},
+		"unknown field": {
+			method:        http.MethodPost,
+			path:          "/v1/messages/delete",
+			body:          `{"receiptHandle": "message-1"}`,
+			authorization: "Bearer consumer-token",
+			wantStatus:    http.StatusBadRequest,
+		},
+		"wrong http method": {
+			method:     http.MethodGet,
+			path:       "/v1/messages/receive",
+			wantStatus: http.StatusMethodNotAllowed,
+		},
+		"unknown path": {
+			method:     http.MethodPost,
+			path:       "/v1/messages/purge",
+			wantStatus: http.StatusNotFound,
+		},
+	}
+
+	for name, tc := range testCases {
+		req := httptest.NewRequest(tc.method, tc.path, strings.NewReader(tc.body))
+		if tc.authorization != "" {
+			req.Header.Set("Authorization", tc.authorization)
+		}
+
+		rec := httptest.NewRecorder()
+		gateway.HTTPServer.Handler.ServeHTTP(rec, req)
+
+		require.Equal(t, tc.wantStatus, rec.Code, name)
+		if tc.wantBody != "" {
+			require.JSONEq(t, tc.wantBody, rec.Body.String(), name)
+		}
+	}
+}
+
+func TestGatewaySend(t *testing.T) {
+	server := &SQSServer{
+		SQSService: &sqs.SQSService{
+			Session:   &session.Session{},
+			SQSClient: &sqs.SqsMock{},
+			QueueURL:  aws.String(sqs.SqsQueueUrlPrefix + sqs.SqsQueueName),
+		},
+		Logger: zerolog.Nop(),
+	}
+
+	gateway := NewGateway(zerolog.Nop(), server, nil)
+
+	rec := httptest.NewRecorder()
+	gateway.HTTPServer.Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/v1/messages/send", strings.NewReader(`{"messageBody": "hello", "delaySeconds": "5"}`)))
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.JSONEq(t, `{"messageID":"message-id-1"}`, rec.Body.String())
+	require.NotEmpty(t, rec.Header().Get("X-Request-Id"))
+
+	// the caller's request id is propagated
+	req := httptest.NewRequest(http.MethodPost, "/v1/messages/send", strings.NewReader(`{"messageBody": "hello"}`))
+	req.Header.Set("X-Request-Id", "request-1")
+
+	rec = httptest.NewRecorder()
+	gateway.HTTPServer.Handler.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, "request-1", rec.Header().Get("X-Request-Id"))
+}
+
+func TestGatewayRateLimit(t *testing.T) {
+	limiter := ratelimit.NewLimiter(zerolog.Nop(), map[string]ratelimit.Limit{"SendMessage": {Rate: 0.5, Burst: 1}}, nil)
+
+	server := &SQSServer{
+		SQSService: &sqs.SQSService{
+			Session:   &session.Session{},
+			SQSClient: &sqs.SqsMock{},
+			QueueURL:  aws.String(sqs.SqsQueueUrlPrefix + sqs.SqsQueueName),
+		},
+		Logger:            zerolog.Nop(),
+		unaryInterceptors: []grpc.UnaryServerInterceptor{limiter.Unary()},
+	}
+
+	gateway := NewGateway(zerolog.Nop(), server, nil)
+
+	send := func() *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		gateway.HTTPServer.Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/v1/messages/send", strings.NewReader(`{"messageBody": "hello"}`)))
+		return rec
+	}
+
+	require.Equal(t, http.StatusOK, send().Code)
+
+	rec := send()
+	require.Equal(t, http.StatusTooManyRequests, rec.Code)
+	require.Equal(t, "2", rec.Header().Get("Retry-After"))
+}
+
+func TestGatewayOpenAPI(t *testing.T) {
+	gateway := NewGateway(zerolog.Nop(), &SQSServer{}, nil)
+
+	rec := httptest.NewRecorder()
+	gateway.HTTPServer.Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var doc struct {
+		Paths map[string]interface{} `json:"paths"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &doc))
+
+	// every route is described
+	for path := range gateway.routes() {
+		require.Contains(t, doc.Paths, path)
+	}
+	require.Len(t, doc.Paths, len(gateway.routes()))
+}
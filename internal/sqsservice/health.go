@@ -0,0 +1,119 @@
+package sqsservice
+
+import (
+	"context"
+	"time"
+
+	pb "github.com/alvinlucillo/sqs-processor/protogen/sqs"
+
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// time each queue check waits for sqs when HealthCheck.Timeout isn't set
+const defaultHealthCheckTimeout = 5 * time.Second
+
+// HealthCheck - how often and for how long the queues are checked for the health service
+type HealthCheck struct {
+	// 0 disables the checks so every service is reported serving until shutdown
+	Interval time.Duration
+	// 0 waits defaultHealthCheckTimeout, since checks that can't wait at all always fail
+	Timeout time.Duration
+}
+
+// healthServices - names of the services checks report the status of
+// the overall ("") and sqs.SQSService statuses are serving only if every queue is reachable
+func (s *SQSServer) healthServices() []string {
+	return []string{"", pb.SQSService_ServiceDesc.ServiceName}
+}
+
+// healthQueues - name -> url of the queues checked; each queue's status is reported under its name, e.g. sqs-sample-1
+func (s *SQSServer) healthQueues() map[string]*string {
+	queues := map[string]*string{s.SQSService.QueueName: s.SQSService.QueueURL}
+	if s.SQSService.DeadLetterQueueURL != nil {
+		queues[s.SQSService.DeadLetterQueueName] = s.SQSService.DeadLetterQueueURL
+	}
+
+	return queues
+}
+
+// checkHealth - checks every queue once and updates the statuses of their services
+func (s *SQSServer) checkHealth(ctx context.Context) {
+	l := s.Logger.With().Str("function", "checkHealth").Logger()
+
+	queues := s.healthQueues()
+	overall := healthpb.HealthCheckResponse_SERVING
+
+	timeout := s.HealthCheck.Timeout
+	if timeout <= 0 {
+		timeout = defaultHealthCheckTimeout
+	}
+
+	for name, url := range queues {
+		status := healthpb.HealthCheckResponse_SERVING
+
+		checkCtx, cancel := context.WithTimeout(ctx, timeout)
+		err := s.SQSService.CheckSQSQueue(checkCtx, url)
+		cancel()
+
+		if err != nil {
+			l.Err(err).Str("queue", name).Msg("Queue is unreachable")
+			status = healthpb.HealthCheckResponse_NOT_SERVING
+			overall = healthpb.HealthCheckResponse_NOT_SERVING
+		}
+
+		s.HealthServer.SetServingStatus(name, status)
+	}
+
+	for _, service := range s.healthServices() {
+		s.HealthServer.SetServingStatus(service, overall)
+	}
+}
+
+// watchHealth - checks the queues every interval until the server shuts down
+func (s *SQSServer) watchHealth() {
+	if s.HealthCheck.Interval <= 0 {
+		for _, service := range s.healthServices() {
+			s.HealthServer.SetServingStatus(service, healthpb.HealthCheckResponse_SERVING)
+		}
+		for name := range s.healthQueues() {
+			s.HealthServer.SetServingStatus(name, healthpb.HealthCheckResponse_SERVING)
+		}
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go func() {
+		<-s.shutdown
+		cancel()
+	}()
+
+	ticker := time.NewTicker(s.HealthCheck.Interval)
+	defer ticker.Stop()
+
+	for {
+		s.checkHealth(ctx)
+
+		select {
+		case <-ticker.C:
+		case <-s.shutdown:
+			return
+		}
+	}
+}
+
+// newHealthServer - creates the health service reporting every service as not serving until it's checked
+func (s *SQSServer) newHealthServer() *health.Server {
+	server := health.NewServer()
+
+	for _, service := range s.healthServices() {
+		server.SetServingStatus(service, healthpb.HealthCheckResponse_NOT_SERVING)
+	}
+	for name := range s.healthQueues() {
+		server.SetServingStatus(name, healthpb.HealthCheckResponse_NOT_SERVING)
+	}
+
+	return server
+}
@@ -0,0 +1,110 @@
+package sqsservice
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alvinlucillo/sqs-processor/internal/sqs"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func healthStatus(t *testing.T, server *SQSServer, service string) healthpb.HealthCheckResponse_ServingStatus {
+	resp, err := server.HealthServer.Check(context.Background(), &healthpb.HealthCheckRequest{Service: service})
+	require.NoError(t, err, service)
+
+	return resp.Status
+}
+
+func TestCheckHealth(t *testing.T) {
+	testCases := map[string]struct {
+		deadLetterQueueName string
+		want                map[string]healthpb.HealthCheckResponse_ServingStatus
+	}{
+		"reachable queues": {
+			deadLetterQueueName: sqs.SqsQueueName + "-dlq",
+			want: map[string]healthpb.HealthCheckResponse_ServingStatus{
+				"":                        healthpb.HealthCheckResponse_SERVING,
+				"sqs.SQSService":          healthpb.HealthCheckResponse_SERVING,
+				sqs.SqsQueueName:          healthpb.HealthCheckResponse_SERVING,
+				sqs.SqsQueueName + "-dlq": healthpb.HealthCheckResponse_SERVING,
+			},
+		},
+		"unreachable dead-letter queue": {
+			deadLetterQueueName: sqs.SqsErrQueueName,
+			want: map[string]healthpb.HealthCheckResponse_ServingStatus{
+				"":                  healthpb.HealthCheckResponse_NOT_SERVING,
+				"sqs.SQSService":    healthpb.HealthCheckResponse_NOT_SERVING,
+				sqs.SqsQueueName:    healthpb.HealthCheckResponse_SERVING,
+				sqs.SqsErrQueueName: healthpb.HealthCheckResponse_NOT_SERVING,
+			},
+		},
+	}
+
+	for name, tc := range testCases {
+		server := &SQSServer{
+			SQSService: &sqs.SQSService{
+				SQSClient:           &sqs.SqsMock{},
+				QueueName:           sqs.SqsQueueName,
+				QueueURL:            aws.String(sqs.SqsQueueUrlPrefix + sqs.SqsQueueName),
+				DeadLetterQueueName: tc.deadLetterQueueName,
+				DeadLetterQueueURL:  aws.String(sqs.SqsQueueUrlPrefix + tc.deadLetterQueueName),
+			},
+			Logger:      zerolog.Nop(),
+			HealthCheck: HealthCheck{Interval: time.Minute, Timeout: time.Second},
+		}
+		server.HealthServer = server.newHealthServer()
+
+		for service := range tc.want {
+			require.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, healthStatus(t, server, service), name)
+		}
+
+		server.checkHealth(context.Background())
+
+		for service, want := range tc.want {
+			require.Equal(t, want, healthStatus(t, server, service), name+": "+service)
+		}
+	}
+}
+
+func TestHealthOnShutdown(t *testing.T) {
+	server := &SQSServer{
+		SQSService: &sqs.SQSService{
+			SQSClient: &sqs.SqsMock{},
+			QueueName: sqs.SqsQueueName,
+			QueueURL:  aws.String(sqs.SqsQueueUrlPrefix + sqs.SqsQueueName),
+		},
+		Logger:      zerolog.Nop(),
+		HealthCheck: HealthCheck{Interval: 10 * time.Millisecond, Timeout: time.Second},
+		GrpcServer:  grpc.NewServer(),
+		shutdown:    make(chan struct{}),
+	}
+	server.HealthServer = server.newHealthServer()
+
+	done := make(chan struct{})
+	go func() {
+		server.watchHealth()
+		close(done)
+	}()
+
+	require.Eventually(t, func() bool {
+		return healthStatus(t, server, "") == healthpb.HealthCheckResponse_SERVING
+	}, time.Second, 10*time.Millisecond)
+
+	// stopping again, e.g. on a second signal, is a no-op
+	server.GracefulStop()
+	server.GracefulStop()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("health checks didn't stop on shutdown")
+	}
+
+	require.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, healthStatus(t, server, ""))
+	require.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, healthStatus(t, server, sqs.SqsQueueName))
+}
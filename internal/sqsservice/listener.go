@@ -0,0 +1,49 @@
+package sqsservice
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"os"
+	"strings"
+)
+
+// listen - creates the listener for the address
+// unix:///path or unix:path listens on a unix domain socket, anything else is a tcp host:port
+// an empty address listens on port on every interface
+func listen(address string, port int) (net.Listener, error) {
+	if address == "" {
+		return net.Listen("tcp", fmt.Sprintf(":%d", port))
+	}
+
+	path, ok := unixSocketPath(address)
+	if !ok {
+		return net.Listen("tcp", address)
+	}
+
+	// a socket left behind by a previous run that didn't shut down cleanly blocks the listener
+	if info, err := os.Stat(path); err == nil && info.Mode()&fs.ModeSocket != 0 {
+		if err := os.Remove(path); err != nil {
+			return nil, err
+		}
+	} else if err == nil {
+		return nil, fmt.Errorf("%v exists and isn't a socket", path)
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+
+	return net.Listen("unix", path)
+}
+
+// unixSocketPath - returns the socket path of a unix:///path or unix:path address
+func unixSocketPath(address string) (string, bool) {
+	switch {
+	case strings.HasPrefix(address, "unix://"):
+		return strings.TrimPrefix(address, "unix://"), true
+	case strings.HasPrefix(address, "unix:"):
+		return strings.TrimPrefix(address, "unix:"), true
+	default:
+		return "", false
+	}
+}
@@ -0,0 +1,63 @@
+package sqsservice
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestListen(t *testing.T) {
+	dir := t.TempDir()
+
+	testCases := map[string]struct {
+		address string
+		network string
+	}{
+		"default port": {
+			address: "",
+			network: "tcp",
+		},
+		"tcp address": {
+			address: "127.0.0.1:0",
+			network: "tcp",
+		},
+		"unix socket": {
+			address: "unix://" + filepath.Join(dir, "sqsservice.sock"),
+			network: "unix",
+		},
+		"unix socket without slashes": {
+			address: "unix:" + filepath.Join(dir, "sqsservice-2.sock"),
+			network: "unix",
+		},
+	}
+
+	for name, tc := range testCases {
+		listener, err := listen(tc.address, 0)
+		require.NoError(t, err, name)
+		require.Equal(t, tc.network, listener.Addr().Network(), name)
+		require.NoError(t, listener.Close())
+	}
+}
+
+func TestListenStaleSocket(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sqsservice.sock")
+
+	// a socket file left behind doesn't block the next listener
+	stale, err := listen("unix://"+path, 0)
+	require.NoError(t, err)
+	stale.(interface{ SetUnlinkOnClose(bool) }).SetUnlinkOnClose(false)
+	require.NoError(t, stale.Close())
+
+	listener, err := listen("unix://"+path, 0)
+	require.NoError(t, err)
+	require.NoError(t, listener.Close())
+
+	// anything else at the path is left alone
+	file := filepath.Join(t.TempDir(), "file")
+	require.NoError(t, os.WriteFile(file, nil, 0o600))
+
+	_, err = listen("unix://"+file, 0)
+	require.Error(t, err)
+}
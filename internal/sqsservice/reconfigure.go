@@ -0,0 +1,147 @@
+package sqsservice
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+
+	"github.com/alvinlucillo/sqs-processor/internal/config"
+	"github.com/alvinlucillo/sqs-processor/internal/logging"
+	"github.com/alvinlucillo/sqs-processor/internal/tracing"
+
+	"github.com/rs/zerolog"
+)
+
+// longest wait time accepted by sqs
+const maxWaitTime = 20
+
+// liveSettings - settings of the environment Reconfigure applies while serving
+// the others, e.g. the queues or the listeners, only apply on restart
+var liveSettings = map[string]bool{
+	"LogLevel":         true,
+	"RetryBaseDelay":   true,
+	"RetryMaxDelay":    true,
+	"RateLimitMethods": true,
+	"RateLimitQueues":  true,
+}
+
+// Validate - checks the settings are known, consistent and within the limits of sqs
+// returns every invalid setting at once
+func (env Environment) Validate() error {
+	errs := make([]error, 0)
+
+	if _, err := zerolog.ParseLevel(env.LogLevel); err != nil {
+		errs = append(errs, err)
+	}
+
+	switch env.LogFormat {
+	case logging.FormatJSON, logging.FormatConsole, "":
+	default:
+		errs = append(errs, fmt.Errorf("unknown log format: %v", env.LogFormat))
+	}
+
+	if env.LogRedactPattern != "" {
+		if _, err := regexp.Compile(env.LogRedactPattern); err != nil {
+			errs = append(errs, fmt.Errorf("invalid log redaction pattern: %w", err))
+		}
+	}
+
+	switch env.TracingExporter {
+	case tracing.ExporterNone, tracing.ExporterOTLP, tracing.ExporterFile, "":
+	default:
+		errs = append(errs, fmt.Errorf("unknown tracing exporter: %v", env.TracingExporter))
+	}
+
+	if env.TracingSampleRatio < 0 || env.TracingSampleRatio > 1 {
+		errs = append(errs, fmt.Errorf("tracing sample ratio must be between 0 and 1: %v", env.TracingSampleRatio))
+	}
+
+	if env.TLSClientCAFile != "" && env.TLSCertFile == "" {
+		errs = append(errs, errors.New("client certificate verification requires the server's TLS certificate"))
+	}
+
+	if env.RetryBaseDelay < 0 || env.RetryMaxDelay < 0 || env.RetryMaxDelay > maxVisibilityTimeout {
+		errs = append(errs, fmt.Errorf("retry delays must be between 0 and %v: %v, %v", maxVisibilityTimeout, env.RetryBaseDelay, env.RetryMaxDelay))
+	}
+
+	if env.PrefetchWorkers < 0 || env.PrefetchBufferSize < 0 || env.PrefetchVisibilityTimeout < 0 || env.PrefetchVisibilityTimeout > maxVisibilityTimeout {
+		errs = append(errs, fmt.Errorf("prefetch workers, buffer size and visibility timeout must be between 0 and %v", maxVisibilityTimeout))
+	}
+
+	if env.PrefetchWaitTime < 0 || env.PrefetchWaitTime > maxWaitTime {
+		errs = append(errs, fmt.Errorf("prefetch wait time must be between 0 and %v: %v", maxWaitTime, env.PrefetchWaitTime))
+	}
+
+	if _, _, err := rateLimits(env); err != nil {
+		errs = append(errs, err)
+	}
+
+	if env.HealthCheckInterval < 0 || env.ConfigReloadInterval < 0 {
+		errs = append(errs, errors.New("health check interval and config reload interval can't be negative"))
+	}
+
+	// a check that can't wait for sqs always fails, reporting the server as not serving
+	if env.HealthCheckInterval > 0 && env.HealthCheckTimeout < 1 {
+		errs = append(errs, fmt.Errorf("health check timeout must be at least 1: %v", env.HealthCheckTimeout))
+	}
+
+	return errors.Join(errs...)
+}
+
+// retryPolicy - returns the current retry policy, which Reconfigure can change
+func (s *SQSServer) retryPolicy() RetryPolicy {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.RetryPolicy
+}
+
+// Reconfigure - applies the log level, retry policy and rate limits of env while serving
+// the buckets of changed rate limits start full; the other settings that changed are logged as needing a restart
+func (s *SQSServer) Reconfigure(env Environment) error {
+	l := s.Logger.With().Str("function", "Reconfigure").Logger()
+
+	if err := env.Validate(); err != nil {
+		l.Err(err).Msg("Invalid environment")
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	changed := config.Changed(s.env, env)
+	if len(changed) == 0 {
+		return nil
+	}
+
+	applied := make([]string, 0, len(changed))
+	for _, name := range changed {
+		if !liveSettings[name] {
+			l.Warn().Str("setting", name).Msg("Setting changed, restart to apply it")
+			continue
+		}
+		applied = append(applied, name)
+	}
+
+	// only changed levels are applied so a reload doesn't undo SetLogLevel or the debug toggle
+	if env.LogLevel != s.env.LogLevel {
+		if err := logging.SetConfiguredLevel(env.LogLevel); err != nil {
+			return err
+		}
+	}
+
+	s.RetryPolicy = RetryPolicy{BaseDelay: int64(env.RetryBaseDelay), MaxDelay: int64(env.RetryMaxDelay)}
+
+	if s.RateLimiter != nil && (env.RateLimitMethods != s.env.RateLimitMethods || env.RateLimitQueues != s.env.RateLimitQueues) {
+		methodLimits, queueLimits, _ := rateLimits(env)
+		s.RateLimiter.SetLimits(methodLimits, queueLimits)
+	}
+
+	s.env = env
+
+	if len(applied) > 0 {
+		l.Info().Strs("settings", applied).Msg("Applied changed settings")
+	}
+
+	return nil
+}
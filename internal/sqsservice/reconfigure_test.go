@@ -0,0 +1,125 @@
+package sqsservice
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alvinlucillo/sqs-processor/internal/ratelimit"
+	pb "github.com/alvinlucillo/sqs-processor/protogen/sqs"
+
+	"github.com/kelseyhightower/envconfig"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// defaultEnvironment - returns the environment with every default and the required credentials
+func defaultEnvironment(t *testing.T) Environment {
+	t.Setenv("SQSSERVICETEST_AWS_ACCESS_KEY_ID", "key")
+	t.Setenv("SQSSERVICETEST_AWS_SECRET_ACCESS_KEY", "secret")
+
+	var env Environment
+	require.NoError(t, envconfig.Process("sqsservicetest", &env))
+
+	return env
+}
+
+func TestValidate(t *testing.T) {
+	testCases := map[string]struct {
+		change  func(env *Environment)
+		wantErr bool
+	}{
+		"defaults": {
+			change: func(env *Environment) {},
+		},
+		"unknown log format": {
+			change:  func(env *Environment) { env.LogFormat = "xml" },
+			wantErr: true,
+		},
+		"invalid redaction pattern": {
+			change:  func(env *Environment) { env.LogRedactPattern = "[" },
+			wantErr: true,
+		},
+		"client certificates without TLS": {
+			change:  func(env *Environment) { env.TLSClientCAFile = "ca.pem" },
+			wantErr: true,
+		},
+		"retry delay over sqs limit": {
+			change:  func(env *Environment) { env.RetryMaxDelay = 50000 },
+			wantErr: true,
+		},
+		"invalid rate limit": {
+			change:  func(env *Environment) { env.RateLimitQueues = "sqs-sample-1" },
+			wantErr: true,
+		},
+		"prefetch wait time over sqs limit": {
+			change:  func(env *Environment) { env.PrefetchWaitTime = 30 },
+			wantErr: true,
+		},
+		"health checks without timeout": {
+			change:  func(env *Environment) { env.HealthCheckTimeout = 0 },
+			wantErr: true,
+		},
+		"no health checks": {
+			change: func(env *Environment) {
+				env.HealthCheckInterval = 0
+				env.HealthCheckTimeout = 0
+			},
+		},
+	}
+
+	for name, tc := range testCases {
+		env := defaultEnvironment(t)
+		tc.change(&env)
+
+		err := env.Validate()
+		if tc.wantErr {
+			require.Error(t, err, name)
+		} else {
+			require.NoError(t, err, name)
+		}
+	}
+}
+
+func TestReconfigure(t *testing.T) {
+	defer zerolog.SetGlobalLevel(zerolog.GlobalLevel())
+	zerolog.SetGlobalLevel(zerolog.InfoLevel)
+
+	env := defaultEnvironment(t)
+	server := &SQSServer{Logger: zerolog.Nop(), RateLimiter: ratelimit.NewLimiter(zerolog.Nop(), nil, nil), env: env}
+
+	reloaded := env
+	reloaded.RetryBaseDelay = 10
+	reloaded.RetryMaxDelay = 60
+	reloaded.LogLevel = "warn"
+	reloaded.RateLimitMethods = "SendMessage=1"
+	reloaded.QueueName = "other-queue"
+
+	require.NoError(t, server.Reconfigure(reloaded))
+	require.Equal(t, RetryPolicy{BaseDelay: 10, MaxDelay: 60}, server.retryPolicy())
+	require.Equal(t, zerolog.WarnLevel, zerolog.GlobalLevel())
+
+	// the new rate limit applies right away
+	_, err := server.RateLimiter.Unary()(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: pb.SQSService_SendMessage_FullMethodName},
+		func(ctx context.Context, req interface{}) (interface{}, error) { return nil, nil })
+	require.NoError(t, err)
+	_, err = server.RateLimiter.Unary()(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: pb.SQSService_SendMessage_FullMethodName},
+		func(ctx context.Context, req interface{}) (interface{}, error) { return nil, nil })
+	require.Equal(t, codes.ResourceExhausted, status.Code(err))
+
+	invalid := reloaded
+	invalid.RetryBaseDelay = -1
+	require.Error(t, server.Reconfigure(invalid))
+	require.Equal(t, RetryPolicy{BaseDelay: 10, MaxDelay: 60}, server.retryPolicy())
+}
+
+func TestMethodQueues(t *testing.T) {
+	env := Environment{QueueName: "queue", DeadLetterQueueName: "dead-letter"}
+
+	require.Equal(t, []string{"queue"}, methodQueues(env, pb.SQSService_ReceiveMessage_FullMethodName))
+	require.Equal(t, []string{"queue", "dead-letter"}, methodQueues(env, pb.SQSService_DeadLetterMessage_FullMethodName))
+	require.Empty(t, methodQueues(env, pb.SQSService_SetLogLevel_FullMethodName))
+	require.Empty(t, methodQueues(env, "/grpc.health.v1.Health/Check"))
+}
@@ -0,0 +1,49 @@
+package sqsservice
+
+import (
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// maximum visibility timeout allowed by sqs (12 hours)
+const maxVisibilityTimeout = 43200
+
+// checkVisibilityTimeout - rejects visibility timeouts sqs wouldn't accept as invalid arguments
+func checkVisibilityTimeout(timeout int64) error {
+	if timeout < 0 || timeout > maxVisibilityTimeout {
+		return status.Errorf(codes.InvalidArgument, "visibility timeout must be between 0 and %v seconds: %v", maxVisibilityTimeout, timeout)
+	}
+
+	return nil
+}
+
+// RetryPolicy - derives the redelivery delay of a nacked message from its receive count
+// delay doubles with every receive starting from BaseDelay and is capped at MaxDelay
+type RetryPolicy struct {
+	BaseDelay int64
+	MaxDelay  int64
+}
+
+// Delay - returns the number of seconds the message stays invisible before redelivery
+// returns 0 if the policy is disabled (BaseDelay is 0)
+func (r RetryPolicy) Delay(receiveCount int64) int64 {
+	if r.BaseDelay <= 0 {
+		return 0
+	}
+
+	maxDelay := r.MaxDelay
+	if maxDelay <= 0 || maxDelay > maxVisibilityTimeout {
+		maxDelay = maxVisibilityTimeout
+	}
+
+	delay := r.BaseDelay
+	for i := int64(1); i < receiveCount && delay < maxDelay; i++ {
+		delay *= 2
+	}
+
+	if delay > maxDelay {
+		delay = maxDelay
+	}
+
+	return delay
+}
@@ -0,0 +1,45 @@
+package sqsservice
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetryPolicyDelay(t *testing.T) {
+	testCases := map[string]struct {
+		policy       RetryPolicy
+		receiveCount int64
+		delay        int64
+	}{
+		"disabled policy": {
+			policy:       RetryPolicy{},
+			receiveCount: 3,
+			delay:        0,
+		},
+		"first receive": {
+			policy:       RetryPolicy{BaseDelay: 10, MaxDelay: 300},
+			receiveCount: 1,
+			delay:        10,
+		},
+		"third receive": {
+			policy:       RetryPolicy{BaseDelay: 10, MaxDelay: 300},
+			receiveCount: 3,
+			delay:        40,
+		},
+		"capped by max delay": {
+			policy:       RetryPolicy{BaseDelay: 10, MaxDelay: 300},
+			receiveCount: 20,
+			delay:        300,
+		},
+		"capped by sqs limit": {
+			policy:       RetryPolicy{BaseDelay: 10},
+			receiveCount: 100,
+			delay:        maxVisibilityTimeout,
+		},
+	}
+
+	for _, tc := range testCases {
+		require.Equal(t, tc.delay, tc.policy.Delay(tc.receiveCount))
+	}
+}
@@ -2,114 +2,681 @@ package sqsservice
 
 import (
 	"context"
-	"fmt"
+	"crypto/tls"
+	"errors"
 	"net"
+	"strings"
+	"sync"
+	"time"
 
+	"github.com/alvinlucillo/sqs-processor/internal/auth"
+	"github.com/alvinlucillo/sqs-processor/internal/logging"
+	"github.com/alvinlucillo/sqs-processor/internal/metrics"
+	"github.com/alvinlucillo/sqs-processor/internal/ratelimit"
 	"github.com/alvinlucillo/sqs-processor/internal/sqs"
+	"github.com/alvinlucillo/sqs-processor/internal/tlsconfig"
+	"github.com/alvinlucillo/sqs-processor/internal/tracing"
 	pb "github.com/alvinlucillo/sqs-processor/protogen/sqs"
 
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/rs/zerolog"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/emptypb"
 )
 
 const (
 	packageName = "server"
+
+	// time given to export the buffered spans on shutdown
+	tracingShutdownTimeout = 5 * time.Second
 )
 
+var rpcMetrics = metrics.NewRPC(prometheus.DefaultRegisterer, "sqsservice")
+
 type Server interface {
 	ReceiveMessage(ctx context.Context, in *pb.SQSReceiveMessageRequest) (*pb.SQSReceiveMessageResponse, error)
 	GracefulStop()
 	Serve() error
+	Reconfigure(env Environment) error
 }
 
 type SQSServer struct {
 	pb.SQSServiceServer
-	SQSService *sqs.SQSService
-	Logger     zerolog.Logger
-	GrpcServer *grpc.Server
-	Listener   net.Listener
+	SQSService  *sqs.SQSService
+	Logger      zerolog.Logger
+	GrpcServer  *grpc.Server
+	Listener    net.Listener
+	RetryPolicy RetryPolicy
+	// serves ReceiveMessage from memory when set
+	Prefetcher *sqs.Prefetcher
+	// grpc.health.v1 service reporting whether the queues are reachable
+	HealthServer *health.Server
+	HealthCheck  HealthCheck
+	// serves the http/json api when set
+	Gateway *Gateway
+	// serves /metrics when set
+	Metrics *metrics.Server
+	// exports spans when set
+	Tracing *tracing.Provider
+	// rejects calls over the rate limits of their method or queues
+	RateLimiter *ratelimit.Limiter
+
+	// interceptors of unary calls, also run by the gateway
+	unaryInterceptors []grpc.UnaryServerInterceptor
+
+	// guards the settings Reconfigure changes while serving
+	mu sync.RWMutex
+	// environment the settings were last applied from
+	env Environment
+
+	// unacked messages of open streams
+	tracker *streamTracker
+	// closed when the server starts shutting down so open streams can end
+	shutdown chan struct{}
+	// GracefulStop only shuts down once, however many times it's called
+	stopOnce sync.Once
 }
 
 type Environment struct {
-	Region             string `required:"true" default:"us-east-1"`
-	QueueName          string `required:"true" default:"sqs-sample-1"`
-	Profile            string `required:"true" default:"default"`
-	Port               int    `required:"true" default:"50051"`
+	Region    string `required:"true" default:"us-east-1"`
+	QueueName string `required:"true" default:"sqs-sample-1"`
+	// queue receiving messages consumers dead-letter; dead-lettering fails if unset
+	DeadLetterQueueName string `split_words:"true"`
+	Profile             string `required:"true" default:"default"`
+	Port                int    `required:"true" default:"50051"`
+	// address the server listens on: host:port or unix:///path/to/socket
+	// a unix socket on a shared emptyDir volume keeps the sidecar off the pod network
+	// defaults to Port on every interface
+	ListenAddress string `split_words:"true"`
+	// pem files of the server's certificate and key; TLS is disabled if unset
+	// reloaded when they change, e.g. on cert-manager rotations
+	TLSCertFile string `envconfig:"TLS_CERT_FILE"`
+	TLSKeyFile  string `envconfig:"TLS_KEY_FILE"`
+	// pem file of the certificate authorities clients' certificates are verified against
+	// setting it requires every client to present a certificate (mutual TLS)
+	TLSClientCAFile string `envconfig:"TLS_CLIENT_CA_FILE"`
+	// file of the static bearer tokens callers must send, one per line with their roles
+	// authentication is disabled if neither it nor AuthJWKSFile is set
+	AuthTokenFile string `envconfig:"AUTH_TOKEN_FILE"`
+	// JWKS file of the keys JWT bearer tokens are verified with
+	AuthJWKSFile string `envconfig:"AUTH_JWKS_FILE"`
+	// expected iss and aud claims of JWTs; unchecked if unset
+	AuthJWTIssuer   string `envconfig:"AUTH_JWT_ISSUER"`
+	AuthJWTAudience string `envconfig:"AUTH_JWT_AUDIENCE"`
+	// prefix of the oauth2 scopes granting roles, e.g. sqs: makes the sqs:consumer scope grant consumer
+	// scopes are ignored if unset, so only the roles claim grants roles
+	AuthJWTScopePrefix string `envconfig:"AUTH_JWT_SCOPE_PREFIX"`
+	// methods every role can call, e.g. consumer=ReceiveMessage,DeleteMessage;admin=*
+	// defaults to consumers receiving and settling messages and admins calling every method
+	AuthPolicy         string `envconfig:"AUTH_POLICY"`
 	AwsAccessKeyId     string `required:"true" split_words:"true"`
 	AwsSecretAccessKey string `required:"true" split_words:"true"`
+	// number of seconds a nacked message is delayed on its first receive; doubles on every receive after
+	// 0 disables the retry policy so nacked messages without a delay are redelivered immediately
+	RetryBaseDelay int `split_words:"true" default:"0"`
+	// upper bound in seconds of the retry policy's delay
+	RetryMaxDelay int `split_words:"true" default:"900"`
+	// number of background workers prefetching messages for ReceiveMessage
+	// 0 disables prefetching so every ReceiveMessage calls sqs directly
+	PrefetchWorkers int `split_words:"true" default:"0"`
+	// maximum number of prefetched messages held in memory
+	PrefetchBufferSize int `split_words:"true" default:"100"`
+	// number of seconds prefetched messages stay invisible; extended until they're handed out
+	PrefetchVisibilityTimeout int `split_words:"true" default:"30"`
+	// number of seconds each prefetch worker long polls for
+	PrefetchWaitTime int `split_words:"true" default:"20"`
+	// number of seconds between checks of the queues reported by the health service
+	// 0 disables the checks so the health service reports serving until shutdown
+	HealthCheckInterval int `split_words:"true" default:"30"`
+	// number of seconds each queue check waits for sqs
+	HealthCheckTimeout int `split_words:"true" default:"5"`
+	// address the http/json gateway listens on: host:port or unix:///path/to/socket
+	// the gateway is disabled if unset; it's served over TLS when the server is
+	GatewayAddress string `split_words:"true"`
+	// address /metrics is served on for prometheus; disabled if empty
+	// differs from the client's default so both fit in the same pod
+	MetricsAddress string `split_words:"true" default:":9090"`
+	// level of the logs: trace, debug, info, warn, error or disabled
+	// changed at runtime through SetLogLevel, or toggled with debug on SIGUSR1
+	LogLevel string `split_words:"true" default:"info"`
+	// json, or console for humans
+	LogFormat string `split_words:"true" default:"json"`
+	// number of identical debug and info messages logged per LogSamplePeriod; 0 logs every message
+	LogSampleBurst int `split_words:"true" default:"0"`
+	// number of seconds identical messages are counted over
+	LogSamplePeriod int `split_words:"true" default:"1"`
+	// fields of the logged requests and responses whose values are always redacted
+	// messages' bodies and dead-letter reasons can carry any PII so they're redacted by default
+	LogRedactFields []string `split_words:"true" default:"messageBody,reason"`
+	// regular expression whose matches are redacted from every other logged value, e.g. email addresses
+	LogRedactPattern string `split_words:"true"`
+	// where spans are exported: none, otlp or file
+	// otlp sends them to the collector set by the standard OTEL_EXPORTER_OTLP_* variables
+	// the trace context is propagated through calls and messages even if it's none
+	TracingExporter string `split_words:"true" default:"none"`
+	// file spans are appended to as json when TracingExporter is file
+	TracingFile string `split_words:"true"`
+	// ratio of new traces that are sampled; traces started by callers or producers keep their decision
+	TracingSampleRatio float64 `split_words:"true" default:"1"`
+	// calls allowed per second and burst of each method, e.g. ReceiveMessage=10:20,SendMessage=50
+	// * limits every other method, each on its own; unlimited if unset
+	RateLimitMethods string `split_words:"true"`
+	// calls to sqs allowed per second and burst of each queue across every method, e.g. sqs-sample-1=100:200
+	RateLimitQueues string `split_words:"true"`
+	// yaml or json file the settings are read from; the environment variables override it
+	ConfigFile string `split_words:"true"`
+	// number of seconds between checks of ConfigFile for changes, which apply the settings Reconfigure supports
+	// 0 disables reloading
+	ConfigReloadInterval int `split_words:"true" default:"10"`
 }
 
-func NewServer(logger zerolog.Logger, env Environment) (Server, error) {
+// NewServer - initializes a new server
+// on error, closes the listeners and tracing provider it opened
+func NewServer(logger zerolog.Logger, env Environment) (_ Server, err error) {
 	l := logger.With().Str("package", packageName).Logger()
 
-	sqsServer := &SQSServer{}
+	// closed in reverse order if a later step fails
+	var closers []func() error
+	defer func() {
+		if err == nil {
+			return
+		}
+
+		for i := len(closers) - 1; i >= 0; i-- {
+			if closeErr := closers[i](); closeErr != nil {
+				l.Err(closeErr).Msg("Unable to clean up")
+			}
+		}
+	}()
+
+	if err := env.Validate(); err != nil {
+		l.Err(err).Msg("Invalid environment")
+		return nil, err
+	}
+
+	sqsServer := &SQSServer{env: env}
 
 	sqsConfig := &sqs.SQSConfig{
-		QueueName:          env.QueueName,
-		Profile:            env.Profile,
-		Region:             env.Region,
-		Logger:             logger,
-		AwsAccessKeyId:     env.AwsAccessKeyId,
-		AwsSecretAccessKey: env.AwsSecretAccessKey,
+		QueueName:           env.QueueName,
+		DeadLetterQueueName: env.DeadLetterQueueName,
+		Profile:             env.Profile,
+		Region:              env.Region,
+		Logger:              logger,
+		AwsAccessKeyId:      env.AwsAccessKeyId,
+		AwsSecretAccessKey:  env.AwsSecretAccessKey,
 	}
 
+	// created first so the spans of the calls made while starting are exported
+	provider, err := tracing.NewProvider(context.Background(), tracing.Config{
+		Exporter:    env.TracingExporter,
+		File:        env.TracingFile,
+		SampleRatio: env.TracingSampleRatio,
+		ServiceName: "sqsservice",
+	})
+	if err != nil {
+		l.Err(err).Msg("Failed to set up tracing")
+		return nil, err
+	}
+	if provider != nil {
+		closers = append(closers, func() error {
+			ctx, cancel := context.WithTimeout(context.Background(), tracingShutdownTimeout)
+			defer cancel()
+
+			return provider.Shutdown(ctx)
+		})
+	}
+	sqsServer.Tracing = provider
+
 	sqsService, err := sqs.NewSQSService(sqsConfig)
 	if err != nil {
 		l.Err(err).Msg("Failed to initialize new SQS service")
 		return nil, err
 	}
 
-	listener, err := net.Listen("tcp", fmt.Sprintf(":%d", env.Port))
+	listener, err := listen(env.ListenAddress, env.Port)
 	if err != nil {
 		l.Err(err).Msg("Failed to create listener")
 		return nil, err
 	}
+	// also removes the socket file of unix sockets
+	closers = append(closers, listener.Close)
 
 	sqsServer.Logger = logger
 	sqsServer.SQSService = sqsService
-	sqsServer.GrpcServer = grpc.NewServer()
+	serverOpts := make([]grpc.ServerOption, 0)
+	redactor, err := logging.NewRedactor(env.LogRedactFields, env.LogRedactPattern)
+	if err != nil {
+		l.Err(err).Msg("Invalid log redaction pattern")
+		return nil, err
+	}
+
+	loggingInterceptor := logging.NewInterceptor(logger, redactor)
+	loggingInterceptor.QuietServices = []string{healthpb.Health_ServiceDesc.ServiceName}
+
+	// metrics, tracing and logging go first so they also cover the calls rejected by the other interceptors
+	unaryInterceptors := []grpc.UnaryServerInterceptor{rpcMetrics.UnaryServerInterceptor(),
+		otelgrpc.UnaryServerInterceptor(otelgrpc.WithPropagators(tracing.Propagator)), loggingInterceptor.Unary()}
+	streamInterceptors := []grpc.StreamServerInterceptor{rpcMetrics.StreamServerInterceptor(),
+		otelgrpc.StreamServerInterceptor(otelgrpc.WithPropagators(tracing.Propagator)), loggingInterceptor.Stream()}
+
+	var reloader *tlsconfig.Reloader
+	if env.TLSCertFile != "" {
+		reloader, err = tlsconfig.NewReloader(logger, tlsconfig.Files{CertFile: env.TLSCertFile, KeyFile: env.TLSKeyFile, CAFile: env.TLSClientCAFile})
+		if err != nil {
+			l.Err(err).Msg("Failed to load TLS certificates")
+			return nil, err
+		}
+
+		serverOpts = append(serverOpts, grpc.Creds(credentials.NewTLS(reloader.ServerConfig())))
+	}
+
+	if env.AuthTokenFile != "" || env.AuthJWKSFile != "" {
+		interceptor, err := newAuthInterceptor(logger, env)
+		if err != nil {
+			l.Err(err).Msg("Failed to set up authentication")
+			return nil, err
+		}
+
+		unaryInterceptors = append(unaryInterceptors, interceptor.Unary())
+		streamInterceptors = append(streamInterceptors, interceptor.Stream())
+	}
+
+	// added even without limits so they can be set by reloading the config file
+	// after authentication so unauthenticated calls don't use up the limits
+	sqsServer.RateLimiter, err = newRateLimiter(logger, env)
+	if err != nil {
+		l.Err(err).Msg("Invalid rate limits")
+		return nil, err
+	}
+	unaryInterceptors = append(unaryInterceptors, sqsServer.RateLimiter.Unary())
+	streamInterceptors = append(streamInterceptors, sqsServer.RateLimiter.Stream())
+
+	serverOpts = append(serverOpts, grpc.ChainUnaryInterceptor(unaryInterceptors...), grpc.ChainStreamInterceptor(streamInterceptors...))
+	sqsServer.unaryInterceptors = unaryInterceptors
+	sqsServer.GrpcServer = grpc.NewServer(serverOpts...)
 	sqsServer.Listener = listener
+	sqsServer.RetryPolicy = RetryPolicy{BaseDelay: int64(env.RetryBaseDelay), MaxDelay: int64(env.RetryMaxDelay)}
+	if env.PrefetchWorkers > 0 {
+		sqsServer.Prefetcher = sqs.NewPrefetcher(sqsService, sqs.PrefetchConfig{
+			Workers:           env.PrefetchWorkers,
+			BufferSize:        env.PrefetchBufferSize,
+			VisibilityTimeout: int64(env.PrefetchVisibilityTimeout),
+			WaitingTime:       int64(env.PrefetchWaitTime),
+		})
+	}
+	sqsServer.tracker = newStreamTracker()
+	sqsServer.shutdown = make(chan struct{})
+	sqsServer.HealthCheck = HealthCheck{
+		Interval: time.Duration(env.HealthCheckInterval) * time.Second,
+		Timeout:  time.Duration(env.HealthCheckTimeout) * time.Second,
+	}
+	sqsServer.HealthServer = sqsServer.newHealthServer()
 
 	pb.RegisterSQSServiceServer(sqsServer.GrpcServer, sqsServer)
+	healthpb.RegisterHealthServer(sqsServer.GrpcServer, sqsServer.HealthServer)
+
+	if env.GatewayAddress != "" {
+		gatewayListener, err := listen(env.GatewayAddress, 0)
+		if err != nil {
+			l.Err(err).Msg("Failed to create gateway listener")
+			return nil, err
+		}
+		closers = append(closers, gatewayListener.Close)
+
+		if reloader != nil {
+			gatewayListener = tls.NewListener(gatewayListener, reloader.ServerConfig())
+		}
+
+		sqsServer.Gateway = NewGateway(logger, sqsServer, gatewayListener)
+	}
+
+	if env.MetricsAddress != "" {
+		metricsListener, err := listen(env.MetricsAddress, 0)
+		if err != nil {
+			l.Err(err).Msg("Failed to create metrics listener")
+			return nil, err
+		}
+
+		sqsServer.Metrics = metrics.NewServer(metricsListener)
+	}
 
 	return sqsServer, nil
 }
 
+// newAuthInterceptor - creates the interceptor authenticating callers with the configured token file and JWKS
+func newAuthInterceptor(logger zerolog.Logger, env Environment) (*auth.Interceptor, error) {
+	authenticators := make(auth.Authenticators, 0)
+
+	if env.AuthTokenFile != "" {
+		authenticator, err := auth.NewStaticAuthenticator(logger, env.AuthTokenFile)
+		if err != nil {
+			return nil, err
+		}
+		authenticators = append(authenticators, authenticator)
+	}
+
+	if env.AuthJWKSFile != "" {
+		authenticator, err := auth.NewJWTAuthenticator(logger, env.AuthJWKSFile, env.AuthJWTIssuer, env.AuthJWTAudience, env.AuthJWTScopePrefix)
+		if err != nil {
+			return nil, err
+		}
+		authenticators = append(authenticators, authenticator)
+	}
+
+	policy := auth.DefaultPolicy()
+	if env.AuthPolicy != "" {
+		var err error
+		if policy, err = auth.ParsePolicy(env.AuthPolicy); err != nil {
+			return nil, err
+		}
+	}
+
+	interceptor := auth.NewInterceptor(logger, authenticators, policy)
+	interceptor.PublicServices = []string{healthpb.Health_ServiceDesc.ServiceName}
+
+	return interceptor, nil
+}
+
+// newRateLimiter - creates the limiter of the environment's method and queue limits
+func newRateLimiter(logger zerolog.Logger, env Environment) (*ratelimit.Limiter, error) {
+	methodLimits, queueLimits, err := rateLimits(env)
+	if err != nil {
+		return nil, err
+	}
+
+	limiter := ratelimit.NewLimiter(logger, methodLimits, queueLimits)
+	limiter.Queues = func(fullMethod string) []string {
+		return methodQueues(env, fullMethod)
+	}
+	// like for authentication, so kubelet probes aren't rejected under a default limit
+	limiter.ExemptServices = []string{healthpb.Health_ServiceDesc.ServiceName}
+
+	return limiter, nil
+}
+
+// rateLimits - parses the environment's method and queue limits
+func rateLimits(env Environment) (map[string]ratelimit.Limit, map[string]ratelimit.Limit, error) {
+	methodLimits, err := ratelimit.ParseLimits(env.RateLimitMethods)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	queueLimits, err := ratelimit.ParseLimits(env.RateLimitQueues)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return methodLimits, queueLimits, nil
+}
+
+// methodQueues - returns the names of the queues a method calls sqs on
+func methodQueues(env Environment, fullMethod string) []string {
+	switch fullMethod {
+	case pb.SQSService_DeadLetterMessage_FullMethodName:
+		return []string{env.QueueName, env.DeadLetterQueueName}
+	case pb.SQSService_SetLogLevel_FullMethodName:
+		return nil
+	}
+
+	if strings.HasPrefix(fullMethod, "/"+pb.SQSService_ServiceDesc.ServiceName+"/") {
+		return []string{env.QueueName}
+	}
+
+	return nil
+}
+
+// invoke - calls the handler through the unary interceptors as if it were called over grpc
+func (s *SQSServer) invoke(ctx context.Context, fullMethod string, req interface{}, handler grpc.UnaryHandler) (interface{}, error) {
+	info := &grpc.UnaryServerInfo{Server: s, FullMethod: fullMethod}
+
+	chained := handler
+	for i := len(s.unaryInterceptors) - 1; i >= 0; i-- {
+		interceptor, next := s.unaryInterceptors[i], chained
+		chained = func(ctx context.Context, req interface{}) (interface{}, error) {
+			return interceptor(ctx, req, info, next)
+		}
+	}
+
+	return chained(ctx, req)
+}
+
 func (s *SQSServer) Serve() error {
+	l := s.Logger.With().Str("function", "Serve").Logger()
+
+	if s.Prefetcher != nil {
+		s.Prefetcher.Start()
+	}
+
+	if s.HealthServer != nil {
+		go s.watchHealth()
+	}
+
+	if s.Gateway != nil {
+		go func() {
+			if err := s.Gateway.Serve(); err != nil {
+				l.Err(err).Msg("Failed to serve the gateway")
+			}
+		}()
+	}
+
+	if s.Metrics != nil {
+		go func() {
+			if err := s.Metrics.Serve(); err != nil {
+				l.Err(err).Msg("Failed to serve metrics")
+			}
+		}()
+	}
 
 	return s.GrpcServer.Serve(s.Listener)
 }
 
 func (s *SQSServer) GracefulStop() {
+	// later calls wait for the first one to finish shutting down
+	s.stopOnce.Do(s.gracefulStop)
+}
+
+func (s *SQSServer) gracefulStop() {
 	l := s.Logger.With().Str("function", "GracefulStop").Logger()
 	l.Info().Msg("Gracefully shutting down")
 
+	// reported first so load balancers and probes stop sending new calls while the open ones finish
+	if s.HealthServer != nil {
+		s.HealthServer.Shutdown()
+	}
+
+	// open streams never end on their own, so they're told to stop before waiting on them
+	close(s.shutdown)
+
 	s.GrpcServer.GracefulStop()
+
+	if s.Gateway != nil {
+		if err := s.Gateway.Shutdown(context.Background()); err != nil {
+			l.Err(err).Msg("Failed to shut down the gateway")
+		}
+	}
+
+	// prefetched messages nobody received are made visible again for other consumers
+	if s.Prefetcher != nil {
+		s.Prefetcher.Stop()
+	}
+
+	// stopped last so the shutdown itself can be scraped
+	if s.Metrics != nil {
+		if err := s.Metrics.Shutdown(context.Background()); err != nil {
+			l.Err(err).Msg("Failed to shut down metrics")
+		}
+	}
+
+	// flushes the spans of the calls finished while shutting down
+	if s.Tracing != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), tracingShutdownTimeout)
+		defer cancel()
+
+		if err := s.Tracing.Shutdown(ctx); err != nil {
+			l.Err(err).Msg("Failed to shut down tracing")
+		}
+	}
 }
 
 // DeleteMessage - deletes an sqs message
 func (s *SQSServer) DeleteMessage(ctx context.Context, in *pb.SQSDeleteMessageRequest) (*emptypb.Empty, error) {
-	l := s.Logger.With().Str("function", "DeleteMessage").Logger()
+	l := logging.WithRequestID(ctx, s.Logger).With().Str("function", "DeleteMessage").Logger()
 
-	l.Debug().Str("input", in.MessageID)
+	if err := s.SQSService.DeleteSQSMessageWithContext(ctx, in.MessageID); err != nil {
+		l.Err(err).Msg("Failed to delete SQS message")
+		return &emptypb.Empty{}, err
+	}
+
+	s.tracker.release(in.MessageID)
 
-	return &emptypb.Empty{}, s.SQSService.DeleteSQSMessage(in.MessageID)
+	return &emptypb.Empty{}, nil
 }
 
-// DeleteMessage - retrieves sqs messages
-func (s *SQSServer) ReceiveMessage(ctx context.Context, in *pb.SQSReceiveMessageRequest) (*pb.SQSReceiveMessageResponse, error) {
-	l := s.Logger.With().Str("function", "ReceiveMessage").Logger()
+// NackMessage - releases an sqs message back to the queue
+// the message becomes visible after the requested delay, or the retry policy's delay if none is given
+func (s *SQSServer) NackMessage(ctx context.Context, in *pb.SQSNackMessageRequest) (*emptypb.Empty, error) {
+	l := logging.WithRequestID(ctx, s.Logger).With().Str("function", "NackMessage").Logger()
+
+	delay, err := s.nackDelay(in, in.ReceiveCount)
+	if err != nil {
+		l.Err(err).Msg("Invalid nack delay")
+		return nil, err
+	}
+
+	l.Debug().Int64("delay", delay).Msg("Releasing message")
+
+	if err := s.SQSService.ChangeSQSMessageVisibilityWithContext(ctx, in.MessageID, delay); err != nil {
+		l.Err(err).Msg("Failed to release SQS message")
+		return nil, err
+	}
+
+	s.tracker.release(in.MessageID)
+
+	return &emptypb.Empty{}, nil
+}
+
+// nackDelay - returns the delay nack sets, or the retry policy's delay for receiveCount if it sets none
+// delays sqs wouldn't accept are invalid arguments
+func (s *SQSServer) nackDelay(nack *pb.SQSNackMessageRequest, receiveCount int64) (int64, error) {
+	if nack.DelaySeconds == nil {
+		return s.retryPolicy().Delay(receiveCount), nil
+	}
+
+	delay := nack.GetDelaySeconds()
+	if delay < 0 || delay > maxVisibilityTimeout {
+		return 0, status.Errorf(codes.InvalidArgument, "delay must be between 0 and %v seconds: %v", maxVisibilityTimeout, delay)
+	}
+
+	return delay, nil
+}
 
-	l.Debug().Msgf("Received input: %v", in)
+// DeadLetterMessage - moves an sqs message to the dead-letter queue
+func (s *SQSServer) DeadLetterMessage(ctx context.Context, in *pb.SQSDeadLetterMessageRequest) (*emptypb.Empty, error) {
+	l := logging.WithRequestID(ctx, s.Logger).With().Str("function", "DeadLetterMessage").Logger()
+
+	if err := s.SQSService.DeadLetterSQSMessageWithContext(ctx, in.MessageID, in.MessageBody, in.Reason); err != nil {
+		l.Err(err).Msg("Failed to dead-letter SQS message")
+
+		// tells clients retrying won't help, e.g. so they stop dead-lettering messages over their maximum attempts
+		if errors.Is(err, sqs.ErrNoDeadLetterQueue) {
+			return nil, status.Error(codes.FailedPrecondition, err.Error())
+		}
+
+		return nil, err
+	}
+
+	s.tracker.release(in.MessageID)
+
+	return &emptypb.Empty{}, nil
+}
+
+// SendMessage - sends a message to the queue
+func (s *SQSServer) SendMessage(ctx context.Context, in *pb.SQSSendMessageRequest) (*pb.SQSSendMessageResponse, error) {
+	l := logging.WithRequestID(ctx, s.Logger).With().Str("function", "SendMessage").Logger()
+
+	messageID, err := s.SQSService.SendSQSMessageWithContext(ctx, in.MessageBody, in.DelaySeconds)
+	if err != nil {
+		l.Err(err).Msg("Failed to send SQS message")
+		return nil, err
+	}
+
+	return &pb.SQSSendMessageResponse{MessageID: messageID}, nil
+}
+
+// ChangeMessageVisibility - sets the number of seconds before an sqs message becomes visible again
+// unlike NackMessage the message stays with its consumer, e.g. to extend the time it has to process it
+func (s *SQSServer) ChangeMessageVisibility(ctx context.Context, in *pb.SQSChangeMessageVisibilityRequest) (*emptypb.Empty, error) {
+	l := logging.WithRequestID(ctx, s.Logger).With().Str("function", "ChangeMessageVisibility").Logger()
+
+	if err := checkVisibilityTimeout(in.VisibilityTimeout); err != nil {
+		l.Err(err).Msg("Invalid visibility timeout")
+		return nil, err
+	}
+
+	if err := s.SQSService.ChangeSQSMessageVisibilityWithContext(ctx, in.MessageID, in.VisibilityTimeout); err != nil {
+		l.Err(err).Msg("Failed to change SQS message visibility")
+		return nil, err
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+// SetLogLevel - changes the level of every logger of the server
+func (s *SQSServer) SetLogLevel(ctx context.Context, in *pb.SQSSetLogLevelRequest) (*pb.SQSSetLogLevelResponse, error) {
+	l := logging.WithRequestID(ctx, s.Logger).With().Str("function", "SetLogLevel").Logger()
+
+	previousLevel := logging.Level()
+
+	if in.Level != "" {
+		if err := logging.SetLevel(in.Level); err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+
+		// logged at warn so it shows whatever the new level is
+		l.Warn().Str("previousLevel", previousLevel).Str("level", logging.Level()).Msg("Changed log level")
+	}
+
+	return &pb.SQSSetLogLevelResponse{PreviousLevel: previousLevel, Level: logging.Level()}, nil
+}
+
+// responseMessage - returns the message as sent to consumers
+func responseMessage(message sqs.SQSResultMessage) *pb.SQSResponseMessage {
+	return &pb.SQSResponseMessage{
+		MessageID:    message.ID,
+		MessageBody:  message.Body,
+		ReceiveCount: message.ReceiveCount,
+		TraceContext: message.TraceContext,
+		SqsMessageID: message.MessageID,
+		Attributes:   message.Attributes,
+	}
+}
+
+// ReceiveMessage - retrieves sqs messages
+func (s *SQSServer) ReceiveMessage(ctx context.Context, in *pb.SQSReceiveMessageRequest) (*pb.SQSReceiveMessageResponse, error) {
+	l := logging.WithRequestID(ctx, s.Logger).With().Str("function", "ReceiveMessage").Logger()
 
 	sqsConfig := &sqs.SQSReceiveMsgConfig{
 		VisibilityTimeout: in.VisibilityTimeout,
 		WaitingTime:       in.WaitTime,
 		MaximumMessages:   in.MaximumNumberOfMessages,
+		MaximumWaitTime:   in.MaximumWaitTime,
 	}
 
-	messages, err := s.SQSService.GetSQSMessage(sqsConfig)
+	var messages *sqs.SQSResult
+	var err error
+
+	if s.Prefetcher != nil {
+		messages, err = s.Prefetcher.Receive(ctx, sqsConfig)
+	} else {
+		// the receive isn't cut short by the caller since the messages it gets would stay invisible
+		messages, err = s.SQSService.GetSQSMessageWithContext(tracing.Detach(ctx), sqsConfig)
+	}
 	if err != nil {
 		l.Err(err).Msg("Failed to get SQS message")
 		return nil, err
@@ -118,14 +685,9 @@ func (s *SQSServer) ReceiveMessage(ctx context.Context, in *pb.SQSReceiveMessage
 	sqsReceiveResponse := make([]*pb.SQSResponseMessage, 0)
 
 	for _, message := range messages.Messages {
-		sqsReceiveResponse = append(sqsReceiveResponse, &pb.SQSResponseMessage{
-			MessageID:   message.ID,
-			MessageBody: message.Body,
-		})
+		sqsReceiveResponse = append(sqsReceiveResponse, responseMessage(message))
 	}
 
-	l.Debug().Msgf("Returned output: %v", sqsReceiveResponse)
-
 	return &pb.SQSReceiveMessageResponse{
 		Messages: sqsReceiveResponse,
 	}, nil
@@ -9,8 +9,13 @@ import (
 
 	"github.com/alvinlucillo/sqs-processor/internal/sqs"
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
 	"github.com/aws/aws-sdk-go/aws/session"
+	awssqs "github.com/aws/aws-sdk-go/service/sqs"
+	"github.com/rs/zerolog"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 func TestDeleteMessage(t *testing.T) {
@@ -49,6 +54,87 @@ func TestDeleteMessage(t *testing.T) {
 	}
 }
 
+// visibilitySqsMock - records the visibility timeout messages are changed to
+type visibilitySqsMock struct {
+	sqs.SqsMock
+	visibilityTimeout *int64
+}
+
+func (s *visibilitySqsMock) ChangeMessageVisibilityWithContext(ctx aws.Context, in *awssqs.ChangeMessageVisibilityInput, opts ...request.Option) (*awssqs.ChangeMessageVisibilityOutput, error) {
+	s.visibilityTimeout = in.VisibilityTimeout
+	return s.SqsMock.ChangeMessageVisibilityWithContext(ctx, in, opts...)
+}
+
+func TestNackMessage(t *testing.T) {
+
+	client := &visibilitySqsMock{}
+	svc := &sqs.SQSService{
+		Session:   &session.Session{},
+		SQSClient: client,
+	}
+
+	server := &SQSServer{
+		SQSService:  svc,
+		RetryPolicy: RetryPolicy{BaseDelay: 10, MaxDelay: 60},
+	}
+
+	testCases := map[string]struct {
+		messageId string
+		delay     *int64
+		wantDelay *int64
+		err       error
+	}{
+		"successful nack": {
+			messageId: sqs.SqsMessageId,
+			wantDelay: aws.Int64(20),
+			err:       nil,
+		},
+		"successful nack with delay": {
+			messageId: sqs.SqsMessageId,
+			delay:     aws.Int64(30),
+			wantDelay: aws.Int64(30),
+			err:       nil,
+		},
+		"successful nack without delay": {
+			messageId: sqs.SqsMessageId,
+			delay:     aws.Int64(0),
+			wantDelay: aws.Int64(0),
+			err:       nil,
+		},
+		"delay too long": {
+			messageId: sqs.SqsMessageId,
+			delay:     aws.Int64(maxVisibilityTimeout + 1),
+			err:       status.Error(codes.InvalidArgument, "delay must be between 0 and 43200 seconds: 43201"),
+		},
+		"negative delay": {
+			messageId: sqs.SqsMessageId,
+			delay:     aws.Int64(-1),
+			err:       status.Error(codes.InvalidArgument, "delay must be between 0 and 43200 seconds: -1"),
+		},
+		"failed nack": {
+			messageId: sqs.ErrMessageId,
+			err:       errors.New(sqs.ErrMessageFailedChange),
+		},
+	}
+
+	for name, tc := range testCases {
+		client.visibilityTimeout = nil
+
+		_, err := server.NackMessage(context.Background(), &pb.SQSNackMessageRequest{MessageID: tc.messageId, DelaySeconds: tc.delay, ReceiveCount: 2})
+
+		if tc.err == nil {
+			require.NoError(t, err, name)
+		} else {
+			require.Equal(t, tc.err, err, name)
+		}
+
+		// without a delay, the retry policy's applies
+		if tc.wantDelay != nil {
+			require.Equal(t, tc.wantDelay, client.visibilityTimeout, name)
+		}
+	}
+}
+
 func TestReceiveMessage(t *testing.T) {
 
 	svc := &sqs.SQSService{
@@ -85,3 +171,175 @@ func TestReceiveMessage(t *testing.T) {
 		}
 	}
 }
+
+func TestDeadLetterMessage(t *testing.T) {
+
+	svc := &sqs.SQSService{
+		Session:            &session.Session{},
+		SQSClient:          &sqs.SqsMock{},
+		DeadLetterQueueURL: aws.String(sqs.SqsQueueUrlPrefix + sqs.SqsQueueName),
+	}
+
+	server := &SQSServer{
+		SQSService: svc,
+	}
+
+	testCases := map[string]struct {
+		messageId string
+		noQueue   bool
+		err       error
+	}{
+		"successful dead-letter": {
+			messageId: sqs.SqsMessageId,
+			err:       nil,
+		},
+		"failed dead-letter": {
+			messageId: sqs.ErrMessageId,
+			err:       errors.New(sqs.ErrMessageFailedDelete),
+		},
+		"no dead-letter queue": {
+			messageId: sqs.SqsMessageId,
+			noQueue:   true,
+			err:       status.Error(codes.FailedPrecondition, sqs.ErrNoDeadLetterQueue.Error()),
+		},
+	}
+
+	for _, tc := range testCases {
+		svc.DeadLetterQueueURL = aws.String(sqs.SqsQueueUrlPrefix + sqs.SqsQueueName)
+		if tc.noQueue {
+			svc.DeadLetterQueueURL = nil
+		}
+
+		_, err := server.DeadLetterMessage(context.Background(), &pb.SQSDeadLetterMessageRequest{MessageID: tc.messageId, Reason: "reason"})
+
+		if tc.err == nil {
+			require.NoError(t, err)
+		} else {
+			require.Equal(t, tc.err, err)
+		}
+	}
+}
+
+func TestSendMessage(t *testing.T) {
+
+	testCases := map[string]struct {
+		queueName string
+		err       error
+	}{
+		"successful send": {
+			queueName: sqs.SqsQueueUrlPrefix + sqs.SqsQueueName,
+			err:       nil,
+		},
+		"failed send": {
+			queueName: sqs.SqsQueueUrlPrefix + sqs.SqsErrQueueName,
+			err:       errors.New(sqs.ErrMessageFailedSend),
+		},
+	}
+
+	for _, tc := range testCases {
+		server := &SQSServer{
+			SQSService: &sqs.SQSService{
+				Session:   &session.Session{},
+				SQSClient: &sqs.SqsMock{},
+				QueueURL:  aws.String(tc.queueName),
+			},
+		}
+
+		resp, err := server.SendMessage(context.Background(), &pb.SQSSendMessageRequest{MessageBody: sqs.SqsMessageBody})
+
+		if tc.err == nil {
+			require.NoError(t, err)
+			require.Equal(t, sqs.SqsMessageId, resp.MessageID)
+		} else {
+			require.Equal(t, tc.err, err)
+		}
+	}
+}
+
+func TestChangeMessageVisibility(t *testing.T) {
+
+	svc := &sqs.SQSService{
+		Session:   &session.Session{},
+		SQSClient: &sqs.SqsMock{},
+	}
+
+	server := &SQSServer{
+		SQSService: svc,
+	}
+
+	testCases := map[string]struct {
+		messageId         string
+		visibilityTimeout int64
+		err               error
+	}{
+		"successful change": {
+			messageId:         sqs.SqsMessageId,
+			visibilityTimeout: 60,
+			err:               nil,
+		},
+		"failed change": {
+			messageId:         sqs.ErrMessageId,
+			visibilityTimeout: 60,
+			err:               errors.New(sqs.ErrMessageFailedChange),
+		},
+		"visibility timeout too long": {
+			messageId:         sqs.SqsMessageId,
+			visibilityTimeout: maxVisibilityTimeout + 1,
+			err:               status.Error(codes.InvalidArgument, "visibility timeout must be between 0 and 43200 seconds: 43201"),
+		},
+	}
+
+	for _, tc := range testCases {
+		_, err := server.ChangeMessageVisibility(context.Background(), &pb.SQSChangeMessageVisibilityRequest{MessageID: tc.messageId, VisibilityTimeout: tc.visibilityTimeout})
+
+		if tc.err == nil {
+			require.NoError(t, err)
+		} else {
+			require.Equal(t, tc.err, err)
+		}
+	}
+}
+
+func TestSetLogLevel(t *testing.T) {
+	defer zerolog.SetGlobalLevel(zerolog.GlobalLevel())
+	zerolog.SetGlobalLevel(zerolog.InfoLevel)
+
+	server := &SQSServer{Logger: zerolog.Nop()}
+
+	testCases := map[string]struct {
+		level             string
+		wantPreviousLevel string
+		wantLevel         string
+		code              codes.Code
+	}{
+		"change level": {
+			level:             "debug",
+			wantPreviousLevel: "info",
+			wantLevel:         "debug",
+		},
+		"current level": {
+			wantPreviousLevel: "debug",
+			wantLevel:         "debug",
+		},
+		"unknown level": {
+			level: "verbose",
+			code:  codes.InvalidArgument,
+		},
+	}
+
+	// ordered since every case changes the level the next one starts from
+	for _, name := range []string{"change level", "current level", "unknown level"} {
+		tc := testCases[name]
+
+		resp, err := server.SetLogLevel(context.Background(), &pb.SQSSetLogLevelRequest{Level: tc.level})
+		require.Equal(t, tc.code, status.Code(err), name)
+		if tc.code != codes.OK {
+			continue
+		}
+
+		require.Equal(t, tc.wantPreviousLevel, resp.PreviousLevel, name)
+		require.Equal(t, tc.wantLevel, resp.Level, name)
+	}
+
+	require.Equal(t, zerolog.DebugLevel, zerolog.GlobalLevel())
+}
@@ -0,0 +1,240 @@
+package sqsservice
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/alvinlucillo/sqs-processor/internal/logging"
+	"github.com/alvinlucillo/sqs-processor/internal/sqs"
+	"github.com/alvinlucillo/sqs-processor/internal/tracing"
+	pb "github.com/alvinlucillo/sqs-processor/protogen/sqs"
+	"github.com/rs/zerolog"
+)
+
+const (
+	// number of unacked messages a subscriber holds if it doesn't set a limit
+	defaultMaxUnacked = 10
+	// visibility timeout sqs applies when the request doesn't set one, unless the queue overrides it
+	defaultVisibilityTimeout = 30
+)
+
+// subscription - messages pushed to a stream that haven't been deleted or nacked yet
+type subscription struct {
+	maxUnacked int
+	// receipt handle -> time the message's visibility timeout lapses
+	unacked map[string]time.Time
+	// signaled whenever an unacked message is released
+	released chan struct{}
+}
+
+// streamTracker - keeps track of the unacked messages of every open stream
+// DeleteMessage and NackMessage release a message so its stream can receive more
+type streamTracker struct {
+	mu            sync.Mutex
+	subscriptions map[string]*subscription
+}
+
+func newStreamTracker() *streamTracker {
+	return &streamTracker{subscriptions: make(map[string]*subscription)}
+}
+
+// subscribe - registers a new stream that holds at most maxUnacked messages
+func (t *streamTracker) subscribe(maxUnacked int) *subscription {
+	if maxUnacked <= 0 {
+		maxUnacked = defaultMaxUnacked
+	}
+
+	return &subscription{maxUnacked: maxUnacked, unacked: make(map[string]time.Time), released: make(chan struct{}, 1)}
+}
+
+// unsubscribe - stops tracking the stream's messages; they can still be deleted or nacked
+func (t *streamTracker) unsubscribe(sub *subscription) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for id := range sub.unacked {
+		delete(t.subscriptions, id)
+	}
+	sub.unacked = map[string]time.Time{}
+}
+
+// add - marks the message as pushed to the stream
+func (t *streamTracker) add(sub *subscription, id string, expiry time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	sub.unacked[id] = expiry
+	t.subscriptions[id] = sub
+}
+
+// release - marks the message as acked by the subscriber
+// no-op if the message doesn't belong to any stream
+func (t *streamTracker) release(id string) {
+	if t == nil {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	sub, ok := t.subscriptions[id]
+	if !ok {
+		return
+	}
+
+	delete(t.subscriptions, id)
+	delete(sub.unacked, id)
+
+	select {
+	case sub.released <- struct{}{}:
+	default:
+	}
+}
+
+// credit - returns how many more messages the stream can take and when the next unacked message expires
+// expired messages are visible in the queue again so they no longer count against the stream
+func (t *streamTracker) credit(sub *subscription) (int, time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := time.Now()
+	var next time.Time
+
+	for id, expiry := range sub.unacked {
+		if !expiry.After(now) {
+			delete(sub.unacked, id)
+			delete(t.subscriptions, id)
+			continue
+		}
+
+		if next.IsZero() || expiry.Before(next) {
+			next = expiry
+		}
+	}
+
+	return sub.maxUnacked - len(sub.unacked), next
+}
+
+// waitCredit - blocks until the stream can take more messages or ctx is done
+func (t *streamTracker) waitCredit(ctx context.Context, sub *subscription) (int, error) {
+	for {
+		credit, next := t.credit(sub)
+		if credit > 0 {
+			return credit, nil
+		}
+
+		timer := time.NewTimer(time.Until(next))
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return 0, ctx.Err()
+		case <-sub.released:
+		case <-timer.C:
+		}
+
+		timer.Stop()
+	}
+}
+
+// receiveLimits - bounds the streams' long poll waiting time and batch size to what sqs allows
+// unset values default to the maximum
+func receiveLimits(waitingTime int64, batchSize int64) (int64, int64) {
+	if waitingTime <= 0 || waitingTime > sqs.MaxWaitingTime {
+		waitingTime = sqs.MaxWaitingTime
+	}
+
+	if batchSize <= 0 || batchSize > sqs.MaxBatchSize {
+		batchSize = sqs.MaxBatchSize
+	}
+
+	return waitingTime, batchSize
+}
+
+// StreamMessages - pushes sqs messages to the subscriber as they arrive
+// long polls sqs continuously while the subscriber has room for more unacked messages
+// ends when the subscriber cancels the stream or the server shuts down, once the running long poll returns
+// received messages the subscriber didn't get are released back to the queue
+func (s *SQSServer) StreamMessages(in *pb.SQSStreamMessagesRequest, stream pb.SQSService_StreamMessagesServer) error {
+	l := logging.WithRequestID(stream.Context(), s.Logger).With().Str("function", "StreamMessages").Logger()
+
+	ctx, cancel := context.WithCancel(stream.Context())
+	defer cancel()
+
+	// cuts long polls short when the server is shutting down
+	go func() {
+		select {
+		case <-s.shutdown:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	sub := s.tracker.subscribe(int(in.MaximumUnackedMessages))
+	defer s.tracker.unsubscribe(sub)
+
+	waitingTime, batchSize := receiveLimits(in.WaitTime, in.MaximumNumberOfMessages)
+
+	visibilityTimeout := in.VisibilityTimeout
+	if visibilityTimeout <= 0 {
+		visibilityTimeout = defaultVisibilityTimeout
+	}
+
+	l.Debug().Int("maxUnacked", sub.maxUnacked).Msg("Subscriber connected")
+
+	for {
+		credit, err := s.tracker.waitCredit(ctx, sub)
+		if err != nil {
+			l.Debug().Msg("Subscriber disconnected")
+			return nil
+		}
+
+		maximumMessages := batchSize
+		if int64(credit) < maximumMessages {
+			maximumMessages = int64(credit)
+		}
+
+		sqsConfig := &sqs.SQSReceiveMsgConfig{
+			VisibilityTimeout: visibilityTimeout,
+			WaitingTime:       waitingTime,
+			MaximumMessages:   maximumMessages,
+		}
+
+		// the receive isn't cut short by the subscriber or a shutdown since the messages it gets would stay invisible
+		messages, err := s.SQSService.GetSQSMessageWithContext(tracing.Detach(ctx), sqsConfig)
+		if err != nil {
+			l.Err(err).Msg("Failed to get SQS message")
+			return err
+		}
+
+		if ctx.Err() != nil {
+			s.releaseUnsent(l, messages.Messages)
+
+			l.Debug().Msg("Subscriber disconnected")
+			return nil
+		}
+
+		for i, message := range messages.Messages {
+			s.tracker.add(sub, message.ID, time.Now().Add(time.Duration(visibilityTimeout)*time.Second))
+
+			err := stream.Send(responseMessage(message))
+			if err != nil {
+				l.Err(err).Msg("Failed to send message to subscriber")
+				s.releaseUnsent(l, messages.Messages[i:])
+				return err
+			}
+		}
+	}
+}
+
+// releaseUnsent - makes received messages the subscriber didn't get visible again right away
+func (s *SQSServer) releaseUnsent(l zerolog.Logger, messages []sqs.SQSResultMessage) {
+	for _, message := range messages {
+		s.tracker.release(message.ID)
+
+		if err := s.SQSService.ChangeSQSMessageVisibility(message.ID, 0); err != nil {
+			l.Err(err).Msgf("Failed to release unsent message: %v", message.ID)
+		}
+	}
+}
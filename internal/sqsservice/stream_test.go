@@ -0,0 +1,222 @@
+package sqsservice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alvinlucillo/sqs-processor/internal/sqs"
+	pb "github.com/alvinlucillo/sqs-processor/protogen/sqs"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/aws/session"
+	awssqs "github.com/aws/aws-sdk-go/service/sqs"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+)
+
+// streamMock - collects the messages pushed by StreamMessages
+type streamMock struct {
+	grpc.ServerStream
+	ctx      context.Context
+	messages chan *pb.SQSResponseMessage
+	// returned by every send instead of pushing the message when set
+	sendErr error
+}
+
+func (s *streamMock) Context() context.Context {
+	return s.ctx
+}
+
+func (s *streamMock) Send(msg *pb.SQSResponseMessage) error {
+	if s.sendErr != nil {
+		return s.sendErr
+	}
+
+	s.messages <- msg
+	return nil
+}
+
+// uniqueSqsMock - returns a new receipt handle on every receive like sqs does
+type uniqueSqsMock struct {
+	sqs.SqsMock
+	counter int64
+	// visibility timeout of the last receive
+	visibilityTimeout int64
+	// number of messages made visible again
+	released int64
+	// when set, receives are signaled on receiving and return once unblock is closed
+	receiving chan struct{}
+	unblock   chan struct{}
+}
+
+func (s *uniqueSqsMock) ReceiveMessageWithContext(ctx aws.Context, in *awssqs.ReceiveMessageInput, opts ...request.Option) (*awssqs.ReceiveMessageOutput, error) {
+	atomic.StoreInt64(&s.visibilityTimeout, aws.Int64Value(in.VisibilityTimeout))
+
+	if s.receiving != nil {
+		s.receiving <- struct{}{}
+
+		select {
+		case <-s.unblock:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	out, err := s.SqsMock.ReceiveMessageWithContext(ctx, in, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, msg := range out.Messages {
+		msg.ReceiptHandle = aws.String(fmt.Sprintf("%v-%v", *msg.ReceiptHandle, atomic.AddInt64(&s.counter, 1)))
+	}
+
+	return out, nil
+}
+
+func (s *uniqueSqsMock) ChangeMessageVisibilityWithContext(ctx aws.Context, in *awssqs.ChangeMessageVisibilityInput, opts ...request.Option) (*awssqs.ChangeMessageVisibilityOutput, error) {
+	if aws.Int64Value(in.VisibilityTimeout) == 0 {
+		atomic.AddInt64(&s.released, 1)
+	}
+
+	return s.SqsMock.ChangeMessageVisibilityWithContext(ctx, in, opts...)
+}
+
+func TestStreamMessages(t *testing.T) {
+	svc := &sqs.SQSService{
+		Session:   &session.Session{},
+		SQSClient: &uniqueSqsMock{},
+		QueueURL:  aws.String(sqs.SqsQueueUrlPrefix + sqs.SqsQueueName),
+	}
+
+	server := &SQSServer{
+		SQSService: svc,
+		tracker:    newStreamTracker(),
+		shutdown:   make(chan struct{}),
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	stream := &streamMock{ctx: ctx, messages: make(chan *pb.SQSResponseMessage, 10)}
+	done := make(chan error)
+
+	go func() {
+		done <- server.StreamMessages(&pb.SQSStreamMessagesRequest{VisibilityTimeout: 60, MaximumUnackedMessages: 2}, stream)
+	}()
+
+	// the subscriber only allows two unacked messages
+	var msg *pb.SQSResponseMessage
+	for i := 0; i < 2; i++ {
+		msg = <-stream.messages
+		require.Equal(t, sqs.SqsMessageBody, msg.MessageBody)
+	}
+
+	select {
+	case <-stream.messages:
+		t.Fatal("received message beyond the unacked limit")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	// acking a message lets the stream push another one
+	_, err := server.DeleteMessage(context.Background(), &pb.SQSDeleteMessageRequest{MessageID: msg.MessageID})
+	require.NoError(t, err)
+
+	select {
+	case <-stream.messages:
+	case <-time.After(time.Second):
+		t.Fatal("expected message after ack")
+	}
+
+	// shutting down the server ends the stream cleanly
+	close(server.shutdown)
+
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("stream didn't end on shutdown")
+	}
+}
+
+func TestStreamMessagesDefaultVisibility(t *testing.T) {
+	client := &uniqueSqsMock{}
+	server := &SQSServer{
+		SQSService: &sqs.SQSService{
+			Session:   &session.Session{},
+			SQSClient: client,
+			QueueURL:  aws.String(sqs.SqsQueueUrlPrefix + sqs.SqsQueueName),
+		},
+		tracker:  newStreamTracker(),
+		shutdown: make(chan struct{}),
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stream := &streamMock{ctx: ctx, messages: make(chan *pb.SQSResponseMessage, 10)}
+	done := make(chan error)
+
+	go func() {
+		done <- server.StreamMessages(&pb.SQSStreamMessagesRequest{MaximumUnackedMessages: 1}, stream)
+	}()
+
+	<-stream.messages
+	cancel()
+	require.NoError(t, <-done)
+
+	// sqs is asked for the same visibility timeout the stream tracks the message's lease with
+	require.Equal(t, int64(defaultVisibilityTimeout), atomic.LoadInt64(&client.visibilityTimeout))
+}
+
+func TestStreamMessagesReleasesUnsent(t *testing.T) {
+	testCases := map[string]struct {
+		// disconnects the subscriber while sqs is being long polled
+		disconnect bool
+		sendErr    error
+		err        error
+	}{
+		"subscriber disconnected mid-receive": {
+			disconnect: true,
+		},
+		"failed send": {
+			sendErr: errors.New("stream broken"),
+			err:     errors.New("stream broken"),
+		},
+	}
+
+	for name, tc := range testCases {
+		client := &uniqueSqsMock{receiving: make(chan struct{}, 1), unblock: make(chan struct{})}
+		server := &SQSServer{
+			SQSService: &sqs.SQSService{
+				Session:   &session.Session{},
+				SQSClient: client,
+				QueueURL:  aws.String(sqs.SqsQueueUrlPrefix + sqs.SqsQueueName),
+			},
+			tracker:  newStreamTracker(),
+			shutdown: make(chan struct{}),
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		stream := &streamMock{ctx: ctx, messages: make(chan *pb.SQSResponseMessage, 10), sendErr: tc.sendErr}
+		done := make(chan error)
+
+		go func() {
+			done <- server.StreamMessages(&pb.SQSStreamMessagesRequest{MaximumUnackedMessages: 1}, stream)
+		}()
+
+		<-client.receiving
+		if tc.disconnect {
+			cancel()
+		}
+		close(client.unblock)
+
+		require.Equal(t, tc.err, <-done, name)
+		cancel()
+
+		// the received message is visible again right away instead of once its visibility timeout lapses
+		require.Equal(t, int64(1), atomic.LoadInt64(&client.released), name)
+	}
+}
@@ -0,0 +1,205 @@
+package tlsconfig
+
+// package used by sqsservice and client to secure the grpc channel
+// certificate files are reloaded when they change so rotations don't need restarts
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+const (
+	packageName = "tlsconfig"
+
+	// minimum time between checks of the files for changes
+	reloadInterval = 5 * time.Second
+)
+
+// Files - paths of the pem encoded certificate files; empty paths are skipped
+type Files struct {
+	CertFile string
+	KeyFile  string
+	// certificate authorities used to verify the peer
+	CAFile string
+}
+
+// Reloader - holds the certificates loaded from Files and reloads them once the files change
+type Reloader struct {
+	Files  Files
+	Logger zerolog.Logger
+
+	mu        sync.Mutex
+	cert      *tls.Certificate
+	pool      *x509.CertPool
+	modTimes  map[string]time.Time
+	lastCheck time.Time
+}
+
+// NewReloader - creates new Reloader; fails if the files can't be loaded
+func NewReloader(logger zerolog.Logger, files Files) (*Reloader, error) {
+	if (files.CertFile == "") != (files.KeyFile == "") {
+		return nil, errors.New("certificate and key files must be set together")
+	}
+
+	r := &Reloader{
+		Files:    files,
+		Logger:   logger.With().Str("package", packageName).Logger(),
+		modTimes: make(map[string]time.Time),
+	}
+
+	if err := r.load(); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// load - reads every file; caller holds the lock or has exclusive access
+func (r *Reloader) load() error {
+	modTimes := make(map[string]time.Time)
+
+	for _, file := range []string{r.Files.CertFile, r.Files.KeyFile, r.Files.CAFile} {
+		if file == "" {
+			continue
+		}
+
+		info, err := os.Stat(file)
+		if err != nil {
+			return err
+		}
+		modTimes[file] = info.ModTime()
+	}
+
+	if r.Files.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(r.Files.CertFile, r.Files.KeyFile)
+		if err != nil {
+			return err
+		}
+		r.cert = &cert
+	}
+
+	if r.Files.CAFile != "" {
+		caPem, err := os.ReadFile(r.Files.CAFile)
+		if err != nil {
+			return err
+		}
+
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caPem) {
+			return fmt.Errorf("no certificates found in %v", r.Files.CAFile)
+		}
+		r.pool = pool
+	}
+
+	r.modTimes = modTimes
+
+	return nil
+}
+
+// current - returns the certificate and ca pool, reloading them first if the files changed
+// keeps serving the previous ones if reloading fails, e.g. while a rotation is half written
+func (r *Reloader) current() (*tls.Certificate, *x509.CertPool) {
+	l := r.Logger.With().Str("function", "current").Logger()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if time.Since(r.lastCheck) < reloadInterval {
+		return r.cert, r.pool
+	}
+	r.lastCheck = time.Now()
+
+	changed := false
+	for file, modTime := range r.modTimes {
+		info, err := os.Stat(file)
+		if err != nil || !info.ModTime().Equal(modTime) {
+			changed = true
+			break
+		}
+	}
+
+	if changed {
+		if err := r.load(); err != nil {
+			l.Err(err).Msg("Failed to reload certificates, keeping the previous ones")
+		} else {
+			l.Info().Msg("Reloaded certificates")
+		}
+	}
+
+	return r.cert, r.pool
+}
+
+// ServerConfig - tls config for the sqsservice
+// clients must present a certificate signed by CAFile if it's set
+func (r *Reloader) ServerConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			cert, pool := r.current()
+			if cert == nil {
+				return nil, errors.New("no server certificate configured")
+			}
+
+			config := &tls.Config{
+				MinVersion:   tls.VersionTLS12,
+				Certificates: []tls.Certificate{*cert},
+			}
+
+			if pool != nil {
+				config.ClientCAs = pool
+				config.ClientAuth = tls.RequireAndVerifyClientCert
+			}
+
+			return config, nil
+		},
+	}
+}
+
+// ClientConfig - tls config for the client
+// the sqsservice's certificate is verified against CAFile, or the system roots if it's not set
+// the client presents CertFile if it's set
+// serverName overrides the name verified in the sqsservice's certificate, which defaults to the target's host
+func (r *Reloader) ClientConfig(serverName string) *tls.Config {
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		ServerName: serverName,
+		// verification is done in VerifyConnection so it always uses the latest ca pool
+		InsecureSkipVerify: true,
+		VerifyConnection: func(state tls.ConnectionState) error {
+			_, pool := r.current()
+
+			if len(state.PeerCertificates) == 0 {
+				return errors.New("sqsservice didn't present a certificate")
+			}
+
+			opts := x509.VerifyOptions{
+				Roots:         pool,
+				DNSName:       state.ServerName,
+				Intermediates: x509.NewCertPool(),
+			}
+
+			for _, cert := range state.PeerCertificates[1:] {
+				opts.Intermediates.AddCert(cert)
+			}
+
+			_, err := state.PeerCertificates[0].Verify(opts)
+
+			return err
+		},
+		GetClientCertificate: func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			cert, _ := r.current()
+			if cert == nil {
+				return &tls.Certificate{}, nil
+			}
+
+			return cert, nil
+		},
+	}
+}
@@ -0,0 +1,265 @@
+package tlsconfig
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+)
+
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  []byte
+}
+
+func newTestCA(t *testing.T, name string) *testCA {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	return &testCA{cert: cert, key: key, pem: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})}
+}
+
+// issue - returns the pem encoded certificate and key of a leaf signed by the ca
+func (ca *testCA) issue(t *testing.T, name string, usage x509.ExtKeyUsage) ([]byte, []byte) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		DNSNames:     []string{name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &key.PublicKey, ca.key)
+	require.NoError(t, err)
+
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer})
+}
+
+func writeFile(t *testing.T, path string, content []byte) string {
+	require.NoError(t, os.WriteFile(path, content, 0o600))
+	return path
+}
+
+// handshake - runs a tls handshake between the configs over a loopback connection
+func handshake(t *testing.T, serverConfig *tls.Config, clientConfig *tls.Config) error {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	serverErr := make(chan error, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			serverErr <- err
+			return
+		}
+		defer conn.Close()
+
+		serverErr <- tls.Server(conn, serverConfig).Handshake()
+	}()
+
+	conn, err := net.Dial("tcp", listener.Addr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	clientErr := tls.Client(conn, clientConfig).Handshake()
+	if clientErr != nil {
+		// unblocks the server waiting on the client's reply
+		conn.Close()
+	}
+
+	if err := <-serverErr; err != nil {
+		return err
+	}
+
+	return clientErr
+}
+
+func TestHandshake(t *testing.T) {
+	ca := newTestCA(t, "test-ca")
+	otherCA := newTestCA(t, "other-ca")
+
+	serverCert, serverKey := ca.issue(t, "sqsservice", x509.ExtKeyUsageServerAuth)
+	clientCert, clientKey := ca.issue(t, "client", x509.ExtKeyUsageClientAuth)
+	untrustedCert, untrustedKey := otherCA.issue(t, "client", x509.ExtKeyUsageClientAuth)
+
+	dir := t.TempDir()
+	serverFiles := Files{
+		CertFile: writeFile(t, filepath.Join(dir, "server.crt"), serverCert),
+		KeyFile:  writeFile(t, filepath.Join(dir, "server.key"), serverKey),
+	}
+	caFile := writeFile(t, filepath.Join(dir, "ca.crt"), ca.pem)
+	otherCAFile := writeFile(t, filepath.Join(dir, "other-ca.crt"), otherCA.pem)
+	clientCertFile := writeFile(t, filepath.Join(dir, "client.crt"), clientCert)
+	clientKeyFile := writeFile(t, filepath.Join(dir, "client.key"), clientKey)
+	untrustedCertFile := writeFile(t, filepath.Join(dir, "untrusted.crt"), untrustedCert)
+	untrustedKeyFile := writeFile(t, filepath.Join(dir, "untrusted.key"), untrustedKey)
+
+	mutualFiles := serverFiles
+	mutualFiles.CAFile = caFile
+
+	testCases := map[string]struct {
+		server     Files
+		client     Files
+		serverName string
+		wantErr    bool
+	}{
+		"tls": {
+			server:     serverFiles,
+			client:     Files{CAFile: caFile},
+			serverName: "sqsservice",
+		},
+		"mutual tls": {
+			server:     mutualFiles,
+			client:     Files{CertFile: clientCertFile, KeyFile: clientKeyFile, CAFile: caFile},
+			serverName: "sqsservice",
+		},
+		"server not trusted": {
+			server:     serverFiles,
+			client:     Files{CAFile: otherCAFile},
+			serverName: "sqsservice",
+			wantErr:    true,
+		},
+		"wrong server name": {
+			server:     serverFiles,
+			client:     Files{CAFile: caFile},
+			serverName: "elsewhere",
+			wantErr:    true,
+		},
+		"missing client certificate": {
+			server:     mutualFiles,
+			client:     Files{CAFile: caFile},
+			serverName: "sqsservice",
+			wantErr:    true,
+		},
+		"client not trusted": {
+			server:     mutualFiles,
+			client:     Files{CertFile: untrustedCertFile, KeyFile: untrustedKeyFile, CAFile: caFile},
+			serverName: "sqsservice",
+			wantErr:    true,
+		},
+	}
+
+	for name, tc := range testCases {
+		server, err := NewReloader(zerolog.Nop(), tc.server)
+		require.NoError(t, err, name)
+
+		client, err := NewReloader(zerolog.Nop(), tc.client)
+		require.NoError(t, err, name)
+
+		err = handshake(t, server.ServerConfig(), client.ClientConfig(tc.serverName))
+		if tc.wantErr {
+			require.Error(t, err, name)
+		} else {
+			require.NoError(t, err, name)
+		}
+	}
+}
+
+func TestNewReloader(t *testing.T) {
+	dir := t.TempDir()
+
+	testCases := map[string]struct {
+		files Files
+	}{
+		"key without certificate": {
+			files: Files{KeyFile: filepath.Join(dir, "server.key")},
+		},
+		"missing files": {
+			files: Files{CertFile: filepath.Join(dir, "server.crt"), KeyFile: filepath.Join(dir, "server.key")},
+		},
+		"ca without certificates": {
+			files: Files{CAFile: writeFile(t, filepath.Join(dir, "empty.crt"), []byte("not a certificate"))},
+		},
+	}
+
+	for name, tc := range testCases {
+		_, err := NewReloader(zerolog.Nop(), tc.files)
+		require.Error(t, err, name)
+	}
+}
+
+func TestReload(t *testing.T) {
+	oldCA := newTestCA(t, "old-ca")
+	newCA := newTestCA(t, "new-ca")
+
+	oldCert, oldKey := oldCA.issue(t, "sqsservice", x509.ExtKeyUsageServerAuth)
+	newCert, newKey := newCA.issue(t, "sqsservice", x509.ExtKeyUsageServerAuth)
+
+	dir := t.TempDir()
+	serverFiles := Files{
+		CertFile: writeFile(t, filepath.Join(dir, "server.crt"), oldCert),
+		KeyFile:  writeFile(t, filepath.Join(dir, "server.key"), oldKey),
+	}
+	clientFiles := Files{CAFile: writeFile(t, filepath.Join(dir, "ca.crt"), oldCA.pem)}
+
+	server, err := NewReloader(zerolog.Nop(), serverFiles)
+	require.NoError(t, err)
+
+	client, err := NewReloader(zerolog.Nop(), clientFiles)
+	require.NoError(t, err)
+
+	require.NoError(t, handshake(t, server.ServerConfig(), client.ClientConfig("sqsservice")))
+
+	// a half written rotation keeps the previous certificate
+	writeFile(t, serverFiles.KeyFile, []byte("partial"))
+	future := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(serverFiles.KeyFile, future, future))
+	server.lastCheck = time.Time{}
+
+	require.NoError(t, handshake(t, server.ServerConfig(), client.ClientConfig("sqsservice")))
+
+	// the server's rotation isn't picked up by the client until its ca is rotated too
+	writeFile(t, serverFiles.CertFile, newCert)
+	writeFile(t, serverFiles.KeyFile, newKey)
+	future = future.Add(time.Minute)
+	require.NoError(t, os.Chtimes(serverFiles.CertFile, future, future))
+	require.NoError(t, os.Chtimes(serverFiles.KeyFile, future, future))
+	server.lastCheck = time.Time{}
+
+	require.Error(t, handshake(t, server.ServerConfig(), client.ClientConfig("sqsservice")))
+
+	writeFile(t, clientFiles.CAFile, newCA.pem)
+	require.NoError(t, os.Chtimes(clientFiles.CAFile, future, future))
+	client.lastCheck = time.Time{}
+
+	require.NoError(t, handshake(t, server.ServerConfig(), client.ClientConfig("sqsservice")))
+}
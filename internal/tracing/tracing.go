@@ -0,0 +1,155 @@
+package tracing
+
+// package used by sqsservice and client to trace calls and messages with opentelemetry
+// the trace context travels with the grpc calls and with the messages' attributes,
+// so the trace of a message spans its producer, the sqsservice and the handler processing it
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.20.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// exporters spans can be sent to
+const (
+	// spans are still propagated, just not exported
+	ExporterNone = "none"
+	// spans are sent over grpc to the collector set by the standard OTEL_EXPORTER_OTLP_* variables
+	ExporterOTLP = "otlp"
+	// spans are appended to a file as json, one span per object
+	ExporterFile = "file"
+)
+
+// Propagator - propagates the w3c trace context and baggage of calls and messages
+var Propagator propagation.TextMapPropagator = propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{})
+
+type Config struct {
+	// none, otlp or file
+	Exporter string
+	// file spans are appended to when Exporter is file
+	File string
+	// ratio of new traces that are sampled; traces started by the caller keep the caller's decision
+	SampleRatio float64
+	// reported service.name unless OTEL_SERVICE_NAME is set
+	ServiceName string
+}
+
+// Provider - tracer provider exporting the spans of the process
+type Provider struct {
+	*sdktrace.TracerProvider
+	file *os.File
+}
+
+// NewProvider - creates new Provider and makes it the global tracer provider
+// returns nil if the exporter is none
+func NewProvider(ctx context.Context, config Config) (*Provider, error) {
+	otel.SetTextMapPropagator(Propagator)
+
+	provider := &Provider{}
+
+	var exporter sdktrace.SpanExporter
+	var err error
+
+	switch config.Exporter {
+	case ExporterNone, "":
+		return nil, nil
+	case ExporterOTLP:
+		exporter, err = otlptracegrpc.New(ctx)
+	case ExporterFile:
+		if config.File == "" {
+			return nil, fmt.Errorf("the file exporter needs a file")
+		}
+
+		provider.file, err = os.OpenFile(config.File, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return nil, err
+		}
+
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(provider.file))
+	default:
+		return nil, fmt.Errorf("unknown tracing exporter: %v", config.Exporter)
+	}
+	if err != nil {
+		provider.closeFile()
+		return nil, err
+	}
+
+	// the environment goes last so OTEL_SERVICE_NAME and OTEL_RESOURCE_ATTRIBUTES override the defaults
+	res, err := resource.New(ctx,
+		resource.WithAttributes(semconv.ServiceName(config.ServiceName)),
+		resource.WithTelemetrySDK(),
+		resource.WithFromEnv(),
+	)
+	if err != nil {
+		provider.closeFile()
+		return nil, err
+	}
+
+	provider.TracerProvider = sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(config.SampleRatio))),
+	)
+
+	otel.SetTracerProvider(provider.TracerProvider)
+
+	return provider, nil
+}
+
+// Shutdown - exports the buffered spans and stops the provider
+func (p *Provider) Shutdown(ctx context.Context) error {
+	err := p.TracerProvider.Shutdown(ctx)
+
+	if closeErr := p.closeFile(); err == nil {
+		err = closeErr
+	}
+
+	return err
+}
+
+func (p *Provider) closeFile() error {
+	if p.file == nil {
+		return nil
+	}
+
+	return p.file.Close()
+}
+
+// Tracer - returns the named tracer of the global tracer provider
+// spans are dropped until a Provider is created
+func Tracer(name string) trace.Tracer {
+	return otel.Tracer(name)
+}
+
+// Detach - returns a context carrying the trace of ctx but never done
+// used for calls that must finish even if ctx is cancelled
+func Detach(ctx context.Context) context.Context {
+	return trace.ContextWithSpan(context.Background(), trace.SpanFromContext(ctx))
+}
+
+// Inject - returns the trace context of ctx as the fields a message carries, e.g. traceparent
+func Inject(ctx context.Context) map[string]string {
+	carrier := propagation.MapCarrier{}
+	Propagator.Inject(ctx, carrier)
+
+	return carrier
+}
+
+// Extract - returns ctx as a child of the trace context of the message's fields
+func Extract(ctx context.Context, fields map[string]string) context.Context {
+	return Propagator.Extract(ctx, propagation.MapCarrier(fields))
+}
+
+// Fields - names of the fields Inject returns
+func Fields() []string {
+	return Propagator.Fields()
+}
@@ -0,0 +1,120 @@
+package tracing
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func remoteContext(t *testing.T, sampled bool) context.Context {
+	traceID, err := trace.TraceIDFromHex("5759e988bd862e3fe1be46a994272793")
+	require.NoError(t, err)
+	spanID, err := trace.SpanIDFromHex("53995c3f42cd8ad8")
+	require.NoError(t, err)
+
+	var flags trace.TraceFlags
+	if sampled {
+		flags = trace.FlagsSampled
+	}
+
+	return trace.ContextWithRemoteSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    traceID,
+		SpanID:     spanID,
+		TraceFlags: flags,
+	}))
+}
+
+func TestInjectExtract(t *testing.T) {
+	fields := Inject(remoteContext(t, true))
+	require.Equal(t, map[string]string{"traceparent": "00-5759e988bd862e3fe1be46a994272793-53995c3f42cd8ad8-01"}, fields)
+
+	spanContext := trace.SpanContextFromContext(Extract(context.Background(), fields))
+	require.True(t, spanContext.IsRemote())
+	require.Equal(t, trace.SpanContextFromContext(remoteContext(t, true)).WithRemote(true), spanContext)
+
+	require.Empty(t, Inject(context.Background()))
+	require.False(t, trace.SpanContextFromContext(Extract(context.Background(), nil)).IsValid())
+	require.Contains(t, Fields(), "traceparent")
+}
+
+func TestDetach(t *testing.T) {
+	ctx, cancel := context.WithCancel(remoteContext(t, true))
+	cancel()
+
+	detached := Detach(ctx)
+	require.NoError(t, detached.Err())
+	require.Equal(t, trace.SpanContextFromContext(ctx), trace.SpanContextFromContext(detached))
+}
+
+func TestAWSTraceHeader(t *testing.T) {
+	require.Equal(t, "Root=1-5759e988-bd862e3fe1be46a994272793;Parent=53995c3f42cd8ad8;Sampled=1", AWSTraceHeader(remoteContext(t, true)))
+	require.Equal(t, "Root=1-5759e988-bd862e3fe1be46a994272793;Parent=53995c3f42cd8ad8;Sampled=0", AWSTraceHeader(remoteContext(t, false)))
+	require.Equal(t, "", AWSTraceHeader(context.Background()))
+}
+
+func TestFromAWSTraceHeader(t *testing.T) {
+	testCases := map[string]struct {
+		header string
+		want   map[string]string
+	}{
+		"sampled": {
+			header: "Root=1-5759e988-bd862e3fe1be46a994272793;Parent=53995c3f42cd8ad8;Sampled=1",
+			want:   map[string]string{"traceparent": "00-5759e988bd862e3fe1be46a994272793-53995c3f42cd8ad8-01"},
+		},
+		"not sampled with lineage": {
+			header: "Root=1-5759e988-bd862e3fe1be46a994272793;Parent=53995c3f42cd8ad8;Sampled=0;Lineage=a87bd80c:0",
+			want:   map[string]string{"traceparent": "00-5759e988bd862e3fe1be46a994272793-53995c3f42cd8ad8-00"},
+		},
+		"missing parent": {
+			header: "Root=1-5759e988-bd862e3fe1be46a994272793;Sampled=1",
+		},
+		"unknown version": {
+			header: "Root=2-5759e988-bd862e3fe1be46a994272793;Parent=53995c3f42cd8ad8;Sampled=1",
+		},
+		"malformed root": {
+			header: "Root=1-5759e988bd862e3fe1be46a994272793;Parent=53995c3f42cd8ad8",
+		},
+		"empty": {
+			header: "",
+		},
+	}
+
+	for name, tc := range testCases {
+		require.Equal(t, tc.want, FromAWSTraceHeader(tc.header), name)
+	}
+
+	// round trip
+	fields := FromAWSTraceHeader(AWSTraceHeader(remoteContext(t, true)))
+	require.Equal(t, Inject(remoteContext(t, true)), fields)
+}
+
+func TestNewProvider(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "traces.json")
+
+	provider, err := NewProvider(context.Background(), Config{Exporter: ExporterFile, File: file, SampleRatio: 1, ServiceName: "test"})
+	require.NoError(t, err)
+
+	_, span := Tracer("test").Start(context.Background(), "operation")
+	span.End()
+
+	require.NoError(t, provider.Shutdown(context.Background()))
+
+	content, err := os.ReadFile(file)
+	require.NoError(t, err)
+	require.Contains(t, string(content), `"Name":"operation"`)
+	require.Contains(t, string(content), `"Value":"test"`)
+
+	provider, err = NewProvider(context.Background(), Config{Exporter: ExporterNone})
+	require.NoError(t, err)
+	require.Nil(t, provider)
+
+	_, err = NewProvider(context.Background(), Config{Exporter: ExporterFile})
+	require.Error(t, err)
+
+	_, err = NewProvider(context.Background(), Config{Exporter: "zipkin"})
+	require.Error(t, err)
+}
@@ -0,0 +1,78 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// AWSTraceHeader - returns the trace context of ctx in the x-ray format of the AWSTraceHeader system attribute
+// e.g. Root=1-5759e988-bd862e3fe1be46a994272793;Parent=53995c3f42cd8ad8;Sampled=1
+// returns "" if ctx isn't traced
+func AWSTraceHeader(ctx context.Context) string {
+	spanContext := trace.SpanContextFromContext(ctx)
+	if !spanContext.IsValid() {
+		return ""
+	}
+
+	traceID := spanContext.TraceID().String()
+
+	sampled := "0"
+	if spanContext.IsSampled() {
+		sampled = "1"
+	}
+
+	return fmt.Sprintf("Root=1-%v-%v;Parent=%v;Sampled=%v", traceID[:8], traceID[8:], spanContext.SpanID(), sampled)
+}
+
+// FromAWSTraceHeader - converts the x-ray AWSTraceHeader system attribute to the fields Extract takes
+// returns nil if the header has no valid root and parent, e.g. when x-ray hasn't sampled the message yet
+func FromAWSTraceHeader(header string) map[string]string {
+	var root, parent string
+	sampled := false
+
+	for _, part := range strings.Split(header, ";") {
+		key, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+
+		switch key {
+		case "Root":
+			root = value
+		case "Parent":
+			parent = value
+		case "Sampled":
+			sampled = value == "1"
+		}
+	}
+
+	// 1-<8 hex digits of the epoch>-<24 random hex digits>
+	version, rest, _ := strings.Cut(root, "-")
+	epoch, random, _ := strings.Cut(rest, "-")
+	if version != "1" || len(epoch) != 8 || len(random) != 24 {
+		return nil
+	}
+
+	traceID, err := trace.TraceIDFromHex(epoch + random)
+	if err != nil {
+		return nil
+	}
+
+	spanID, err := trace.SpanIDFromHex(parent)
+	if err != nil {
+		return nil
+	}
+
+	var flags trace.TraceFlags
+	if sampled {
+		flags = trace.FlagsSampled
+	}
+
+	ctx := trace.ContextWithRemoteSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    traceID,
+		SpanID:     spanID,
+		TraceFlags: flags,
+	}))
+
+	return Inject(ctx)
+}
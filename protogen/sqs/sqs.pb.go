@@ -26,28 +26,760 @@ type SQSReceiveMessageRequest struct {
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
+	VisibilityTimeout int64 `protobuf:"varint,1,opt,name=visibility_timeout,json=visibilityTimeout,proto3" json:"visibility_timeout,omitempty"`
+	WaitTime          int64 `protobuf:"varint,2,opt,name=wait_time,json=waitTime,proto3" json:"wait_time,omitempty"`
+	// over 10 makes the server aggregate parallel receives
+	MaximumNumberOfMessages int64 `protobuf:"varint,3,opt,name=maximum_number_of_messages,json=maximumNumberOfMessages,proto3" json:"maximum_number_of_messages,omitempty"`
+	// seconds to keep aggregating receives for batches over 10; defaults to wait_time
+	MaximumWaitTime int64 `protobuf:"varint,4,opt,name=maximum_wait_time,json=maximumWaitTime,proto3" json:"maximum_wait_time,omitempty"`
+}
+
+func (x *SQSReceiveMessageRequest) Reset() {
+	*x = SQSReceiveMessageRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_sqs_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SQSReceiveMessageRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SQSReceiveMessageRequest) ProtoMessage() {}
+
+func (x *SQSReceiveMessageRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_sqs_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SQSReceiveMessageRequest.ProtoReflect.Descriptor instead.
+func (*SQSReceiveMessageRequest) Descriptor() ([]byte, []int) {
+	return file_sqs_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *SQSReceiveMessageRequest) GetVisibilityTimeout() int64 {
+	if x != nil {
+		return x.VisibilityTimeout
+	}
+	return 0
+}
+
+func (x *SQSReceiveMessageRequest) GetWaitTime() int64 {
+	if x != nil {
+		return x.WaitTime
+	}
+	return 0
+}
+
+func (x *SQSReceiveMessageRequest) GetMaximumNumberOfMessages() int64 {
+	if x != nil {
+		return x.MaximumNumberOfMessages
+	}
+	return 0
+}
+
+func (x *SQSReceiveMessageRequest) GetMaximumWaitTime() int64 {
+	if x != nil {
+		return x.MaximumWaitTime
+	}
+	return 0
+}
+
+type SQSResponseMessage struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	MessageID    string `protobuf:"bytes,1,opt,name=messageID,proto3" json:"messageID,omitempty"`
+	MessageBody  string `protobuf:"bytes,2,opt,name=messageBody,proto3" json:"messageBody,omitempty"`
+	ReceiveCount int64  `protobuf:"varint,3,opt,name=receiveCount,proto3" json:"receiveCount,omitempty"`
+	// trace context the message was sent with, e.g. traceparent; empty if it wasn't traced
+	TraceContext map[string]string `protobuf:"bytes,4,rep,name=traceContext,proto3" json:"traceContext,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	// id assigned by sqs; unlike messageID, the receipt handle, it's the same across receives of the message
+	SqsMessageID string `protobuf:"bytes,5,opt,name=sqsMessageID,proto3" json:"sqsMessageID,omitempty"`
+	// string and number message attributes the message was sent with, e.g. to route it by type
+	Attributes map[string]string `protobuf:"bytes,6,rep,name=attributes,proto3" json:"attributes,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+}
+
+func (x *SQSResponseMessage) Reset() {
+	*x = SQSResponseMessage{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_sqs_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SQSResponseMessage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SQSResponseMessage) ProtoMessage() {}
+
+func (x *SQSResponseMessage) ProtoReflect() protoreflect.Message {
+	mi := &file_sqs_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SQSResponseMessage.ProtoReflect.Descriptor instead.
+func (*SQSResponseMessage) Descriptor() ([]byte, []int) {
+	return file_sqs_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *SQSResponseMessage) GetMessageID() string {
+	if x != nil {
+		return x.MessageID
+	}
+	return ""
+}
+
+func (x *SQSResponseMessage) GetMessageBody() string {
+	if x != nil {
+		return x.MessageBody
+	}
+	return ""
+}
+
+func (x *SQSResponseMessage) GetReceiveCount() int64 {
+	if x != nil {
+		return x.ReceiveCount
+	}
+	return 0
+}
+
+func (x *SQSResponseMessage) GetTraceContext() map[string]string {
+	if x != nil {
+		return x.TraceContext
+	}
+	return nil
+}
+
+func (x *SQSResponseMessage) GetSqsMessageID() string {
+	if x != nil {
+		return x.SqsMessageID
+	}
+	return ""
+}
+
+func (x *SQSResponseMessage) GetAttributes() map[string]string {
+	if x != nil {
+		return x.Attributes
+	}
+	return nil
+}
+
+type SQSReceiveMessageResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Messages []*SQSResponseMessage `protobuf:"bytes,1,rep,name=messages,proto3" json:"messages,omitempty"`
+}
+
+func (x *SQSReceiveMessageResponse) Reset() {
+	*x = SQSReceiveMessageResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_sqs_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SQSReceiveMessageResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SQSReceiveMessageResponse) ProtoMessage() {}
+
+func (x *SQSReceiveMessageResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_sqs_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SQSReceiveMessageResponse.ProtoReflect.Descriptor instead.
+func (*SQSReceiveMessageResponse) Descriptor() ([]byte, []int) {
+	return file_sqs_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *SQSReceiveMessageResponse) GetMessages() []*SQSResponseMessage {
+	if x != nil {
+		return x.Messages
+	}
+	return nil
+}
+
+type SQSDeleteMessageRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	MessageID string `protobuf:"bytes,1,opt,name=messageID,proto3" json:"messageID,omitempty"`
+}
+
+func (x *SQSDeleteMessageRequest) Reset() {
+	*x = SQSDeleteMessageRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_sqs_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SQSDeleteMessageRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SQSDeleteMessageRequest) ProtoMessage() {}
+
+func (x *SQSDeleteMessageRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_sqs_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SQSDeleteMessageRequest.ProtoReflect.Descriptor instead.
+func (*SQSDeleteMessageRequest) Descriptor() ([]byte, []int) {
+	return file_sqs_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *SQSDeleteMessageRequest) GetMessageID() string {
+	if x != nil {
+		return x.MessageID
+	}
+	return ""
+}
+
+type SQSDeleteMessageResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	IsDeleted bool `protobuf:"varint,1,opt,name=isDeleted,proto3" json:"isDeleted,omitempty"`
+}
+
+func (x *SQSDeleteMessageResponse) Reset() {
+	*x = SQSDeleteMessageResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_sqs_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SQSDeleteMessageResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SQSDeleteMessageResponse) ProtoMessage() {}
+
+func (x *SQSDeleteMessageResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_sqs_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SQSDeleteMessageResponse.ProtoReflect.Descriptor instead.
+func (*SQSDeleteMessageResponse) Descriptor() ([]byte, []int) {
+	return file_sqs_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *SQSDeleteMessageResponse) GetIsDeleted() bool {
+	if x != nil {
+		return x.IsDeleted
+	}
+	return false
+}
+
+type SQSStreamMessagesRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	VisibilityTimeout       int64 `protobuf:"varint,1,opt,name=visibility_timeout,json=visibilityTimeout,proto3" json:"visibility_timeout,omitempty"`
+	WaitTime                int64 `protobuf:"varint,2,opt,name=wait_time,json=waitTime,proto3" json:"wait_time,omitempty"`
+	MaximumNumberOfMessages int64 `protobuf:"varint,3,opt,name=maximum_number_of_messages,json=maximumNumberOfMessages,proto3" json:"maximum_number_of_messages,omitempty"`
+	// number of messages the subscriber can hold without deleting or nacking them
+	// the server stops pushing once reached; 0 defaults to 10
+	MaximumUnackedMessages int64 `protobuf:"varint,4,opt,name=maximum_unacked_messages,json=maximumUnackedMessages,proto3" json:"maximum_unacked_messages,omitempty"`
+}
+
+func (x *SQSStreamMessagesRequest) Reset() {
+	*x = SQSStreamMessagesRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_sqs_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SQSStreamMessagesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SQSStreamMessagesRequest) ProtoMessage() {}
+
+func (x *SQSStreamMessagesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_sqs_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SQSStreamMessagesRequest.ProtoReflect.Descriptor instead.
+func (*SQSStreamMessagesRequest) Descriptor() ([]byte, []int) {
+	return file_sqs_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *SQSStreamMessagesRequest) GetVisibilityTimeout() int64 {
+	if x != nil {
+		return x.VisibilityTimeout
+	}
+	return 0
+}
+
+func (x *SQSStreamMessagesRequest) GetWaitTime() int64 {
+	if x != nil {
+		return x.WaitTime
+	}
+	return 0
+}
+
+func (x *SQSStreamMessagesRequest) GetMaximumNumberOfMessages() int64 {
+	if x != nil {
+		return x.MaximumNumberOfMessages
+	}
+	return 0
+}
+
+func (x *SQSStreamMessagesRequest) GetMaximumUnackedMessages() int64 {
+	if x != nil {
+		return x.MaximumUnackedMessages
+	}
+	return 0
+}
+
+type SQSNackMessageRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	MessageID string `protobuf:"bytes,1,opt,name=messageID,proto3" json:"messageID,omitempty"`
+	// seconds before the message becomes visible again, up to 43200; 0 means immediate redelivery
+	// when unset, the server's retry policy derives the delay from receiveCount
+	DelaySeconds *int64 `protobuf:"varint,2,opt,name=delaySeconds,proto3,oneof" json:"delaySeconds,omitempty"`
+	ReceiveCount int64  `protobuf:"varint,3,opt,name=receiveCount,proto3" json:"receiveCount,omitempty"`
+}
+
+func (x *SQSNackMessageRequest) Reset() {
+	*x = SQSNackMessageRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_sqs_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SQSNackMessageRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SQSNackMessageRequest) ProtoMessage() {}
+
+func (x *SQSNackMessageRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_sqs_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SQSNackMessageRequest.ProtoReflect.Descriptor instead.
+func (*SQSNackMessageRequest) Descriptor() ([]byte, []int) {
+	return file_sqs_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *SQSNackMessageRequest) GetMessageID() string {
+	if x != nil {
+		return x.MessageID
+	}
+	return ""
+}
+
+func (x *SQSNackMessageRequest) GetDelaySeconds() int64 {
+	if x != nil && x.DelaySeconds != nil {
+		return *x.DelaySeconds
+	}
+	return 0
+}
+
+func (x *SQSNackMessageRequest) GetReceiveCount() int64 {
+	if x != nil {
+		return x.ReceiveCount
+	}
+	return 0
+}
+
+type SQSDeadLetterMessageRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	MessageID   string `protobuf:"bytes,1,opt,name=messageID,proto3" json:"messageID,omitempty"`
+	MessageBody string `protobuf:"bytes,2,opt,name=messageBody,proto3" json:"messageBody,omitempty"`
+	// why the message can't be processed; kept as a message attribute in the dead-letter queue
+	Reason string `protobuf:"bytes,3,opt,name=reason,proto3" json:"reason,omitempty"`
+}
+
+func (x *SQSDeadLetterMessageRequest) Reset() {
+	*x = SQSDeadLetterMessageRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_sqs_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SQSDeadLetterMessageRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SQSDeadLetterMessageRequest) ProtoMessage() {}
+
+func (x *SQSDeadLetterMessageRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_sqs_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SQSDeadLetterMessageRequest.ProtoReflect.Descriptor instead.
+func (*SQSDeadLetterMessageRequest) Descriptor() ([]byte, []int) {
+	return file_sqs_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *SQSDeadLetterMessageRequest) GetMessageID() string {
+	if x != nil {
+		return x.MessageID
+	}
+	return ""
+}
+
+func (x *SQSDeadLetterMessageRequest) GetMessageBody() string {
+	if x != nil {
+		return x.MessageBody
+	}
+	return ""
+}
+
+func (x *SQSDeadLetterMessageRequest) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+type SQSExtendLeaseRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	MessageID string `protobuf:"bytes,1,opt,name=messageID,proto3" json:"messageID,omitempty"`
+	// seconds from now before the message becomes visible again
+	VisibilityTimeout int64 `protobuf:"varint,2,opt,name=visibilityTimeout,proto3" json:"visibilityTimeout,omitempty"`
+}
+
+func (x *SQSExtendLeaseRequest) Reset() {
+	*x = SQSExtendLeaseRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_sqs_proto_msgTypes[8]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SQSExtendLeaseRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SQSExtendLeaseRequest) ProtoMessage() {}
+
+func (x *SQSExtendLeaseRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_sqs_proto_msgTypes[8]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SQSExtendLeaseRequest.ProtoReflect.Descriptor instead.
+func (*SQSExtendLeaseRequest) Descriptor() ([]byte, []int) {
+	return file_sqs_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *SQSExtendLeaseRequest) GetMessageID() string {
+	if x != nil {
+		return x.MessageID
+	}
+	return ""
+}
+
+func (x *SQSExtendLeaseRequest) GetVisibilityTimeout() int64 {
+	if x != nil {
+		return x.VisibilityTimeout
+	}
+	return 0
+}
+
+type SQSSendMessageRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	MessageBody string `protobuf:"bytes,1,opt,name=messageBody,proto3" json:"messageBody,omitempty"`
+	// seconds before the message becomes visible to consumers
+	DelaySeconds int64 `protobuf:"varint,2,opt,name=delaySeconds,proto3" json:"delaySeconds,omitempty"`
+}
+
+func (x *SQSSendMessageRequest) Reset() {
+	*x = SQSSendMessageRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_sqs_proto_msgTypes[9]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SQSSendMessageRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SQSSendMessageRequest) ProtoMessage() {}
+
+func (x *SQSSendMessageRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_sqs_proto_msgTypes[9]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SQSSendMessageRequest.ProtoReflect.Descriptor instead.
+func (*SQSSendMessageRequest) Descriptor() ([]byte, []int) {
+	return file_sqs_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *SQSSendMessageRequest) GetMessageBody() string {
+	if x != nil {
+		return x.MessageBody
+	}
+	return ""
+}
+
+func (x *SQSSendMessageRequest) GetDelaySeconds() int64 {
+	if x != nil {
+		return x.DelaySeconds
+	}
+	return 0
+}
+
+type SQSSendMessageResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// id sqs assigned to the message; unlike the messageID of received messages it can't be used to delete it
+	MessageID string `protobuf:"bytes,1,opt,name=messageID,proto3" json:"messageID,omitempty"`
+}
+
+func (x *SQSSendMessageResponse) Reset() {
+	*x = SQSSendMessageResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_sqs_proto_msgTypes[10]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SQSSendMessageResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SQSSendMessageResponse) ProtoMessage() {}
+
+func (x *SQSSendMessageResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_sqs_proto_msgTypes[10]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SQSSendMessageResponse.ProtoReflect.Descriptor instead.
+func (*SQSSendMessageResponse) Descriptor() ([]byte, []int) {
+	return file_sqs_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *SQSSendMessageResponse) GetMessageID() string {
+	if x != nil {
+		return x.MessageID
+	}
+	return ""
+}
+
+type SQSChangeMessageVisibilityRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	MessageID string `protobuf:"bytes,1,opt,name=messageID,proto3" json:"messageID,omitempty"`
+	// seconds from now before the message becomes visible again; 0 makes it immediately visible
+	VisibilityTimeout int64 `protobuf:"varint,2,opt,name=visibilityTimeout,proto3" json:"visibilityTimeout,omitempty"`
+}
+
+func (x *SQSChangeMessageVisibilityRequest) Reset() {
+	*x = SQSChangeMessageVisibilityRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_sqs_proto_msgTypes[11]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SQSChangeMessageVisibilityRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SQSChangeMessageVisibilityRequest) ProtoMessage() {}
+
+func (x *SQSChangeMessageVisibilityRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_sqs_proto_msgTypes[11]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SQSChangeMessageVisibilityRequest.ProtoReflect.Descriptor instead.
+func (*SQSChangeMessageVisibilityRequest) Descriptor() ([]byte, []int) {
+	return file_sqs_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *SQSChangeMessageVisibilityRequest) GetMessageID() string {
+	if x != nil {
+		return x.MessageID
+	}
+	return ""
+}
+
+func (x *SQSChangeMessageVisibilityRequest) GetVisibilityTimeout() int64 {
+	if x != nil {
+		return x.VisibilityTimeout
+	}
+	return 0
+}
+
+// sent by the consumer on the Consume stream
+// receive settings are only read from the first request of the stream
+type SQSConsumeRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
 	VisibilityTimeout       int64 `protobuf:"varint,1,opt,name=visibility_timeout,json=visibilityTimeout,proto3" json:"visibility_timeout,omitempty"`
 	WaitTime                int64 `protobuf:"varint,2,opt,name=wait_time,json=waitTime,proto3" json:"wait_time,omitempty"`
 	MaximumNumberOfMessages int64 `protobuf:"varint,3,opt,name=maximum_number_of_messages,json=maximumNumberOfMessages,proto3" json:"maximum_number_of_messages,omitempty"`
+	// number of additional messages the server may push
+	Credits int64 `protobuf:"varint,4,opt,name=credits,proto3" json:"credits,omitempty"`
+	// messages to delete
+	AckIDs []string `protobuf:"bytes,5,rep,name=ackIDs,proto3" json:"ackIDs,omitempty"`
+	// messages to release back to the queue
+	Nacks []*SQSNackMessageRequest `protobuf:"bytes,6,rep,name=nacks,proto3" json:"nacks,omitempty"`
+	// messages whose visibility timeout is extended
+	Extensions []*SQSExtendLeaseRequest `protobuf:"bytes,7,rep,name=extensions,proto3" json:"extensions,omitempty"`
 }
 
-func (x *SQSReceiveMessageRequest) Reset() {
-	*x = SQSReceiveMessageRequest{}
+func (x *SQSConsumeRequest) Reset() {
+	*x = SQSConsumeRequest{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_sqs_proto_msgTypes[0]
+		mi := &file_sqs_proto_msgTypes[12]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *SQSReceiveMessageRequest) String() string {
+func (x *SQSConsumeRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*SQSReceiveMessageRequest) ProtoMessage() {}
+func (*SQSConsumeRequest) ProtoMessage() {}
 
-func (x *SQSReceiveMessageRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_sqs_proto_msgTypes[0]
+func (x *SQSConsumeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_sqs_proto_msgTypes[12]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -58,58 +790,86 @@ func (x *SQSReceiveMessageRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use SQSReceiveMessageRequest.ProtoReflect.Descriptor instead.
-func (*SQSReceiveMessageRequest) Descriptor() ([]byte, []int) {
-	return file_sqs_proto_rawDescGZIP(), []int{0}
+// Deprecated: Use SQSConsumeRequest.ProtoReflect.Descriptor instead.
+func (*SQSConsumeRequest) Descriptor() ([]byte, []int) {
+	return file_sqs_proto_rawDescGZIP(), []int{12}
 }
 
-func (x *SQSReceiveMessageRequest) GetVisibilityTimeout() int64 {
+func (x *SQSConsumeRequest) GetVisibilityTimeout() int64 {
 	if x != nil {
 		return x.VisibilityTimeout
 	}
 	return 0
 }
 
-func (x *SQSReceiveMessageRequest) GetWaitTime() int64 {
+func (x *SQSConsumeRequest) GetWaitTime() int64 {
 	if x != nil {
 		return x.WaitTime
 	}
 	return 0
 }
 
-func (x *SQSReceiveMessageRequest) GetMaximumNumberOfMessages() int64 {
+func (x *SQSConsumeRequest) GetMaximumNumberOfMessages() int64 {
 	if x != nil {
 		return x.MaximumNumberOfMessages
 	}
 	return 0
 }
 
-type SQSResponseMessage struct {
+func (x *SQSConsumeRequest) GetCredits() int64 {
+	if x != nil {
+		return x.Credits
+	}
+	return 0
+}
+
+func (x *SQSConsumeRequest) GetAckIDs() []string {
+	if x != nil {
+		return x.AckIDs
+	}
+	return nil
+}
+
+func (x *SQSConsumeRequest) GetNacks() []*SQSNackMessageRequest {
+	if x != nil {
+		return x.Nacks
+	}
+	return nil
+}
+
+func (x *SQSConsumeRequest) GetExtensions() []*SQSExtendLeaseRequest {
+	if x != nil {
+		return x.Extensions
+	}
+	return nil
+}
+
+type SQSConsumeFailure struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	MessageID   string `protobuf:"bytes,1,opt,name=messageID,proto3" json:"messageID,omitempty"`
-	MessageBody string `protobuf:"bytes,2,opt,name=messageBody,proto3" json:"messageBody,omitempty"`
+	MessageID string `protobuf:"bytes,1,opt,name=messageID,proto3" json:"messageID,omitempty"`
+	Error     string `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
 }
 
-func (x *SQSResponseMessage) Reset() {
-	*x = SQSResponseMessage{}
+func (x *SQSConsumeFailure) Reset() {
+	*x = SQSConsumeFailure{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_sqs_proto_msgTypes[1]
+		mi := &file_sqs_proto_msgTypes[13]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *SQSResponseMessage) String() string {
+func (x *SQSConsumeFailure) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*SQSResponseMessage) ProtoMessage() {}
+func (*SQSConsumeFailure) ProtoMessage() {}
 
-func (x *SQSResponseMessage) ProtoReflect() protoreflect.Message {
-	mi := &file_sqs_proto_msgTypes[1]
+func (x *SQSConsumeFailure) ProtoReflect() protoreflect.Message {
+	mi := &file_sqs_proto_msgTypes[13]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -120,50 +880,53 @@ func (x *SQSResponseMessage) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use SQSResponseMessage.ProtoReflect.Descriptor instead.
-func (*SQSResponseMessage) Descriptor() ([]byte, []int) {
-	return file_sqs_proto_rawDescGZIP(), []int{1}
+// Deprecated: Use SQSConsumeFailure.ProtoReflect.Descriptor instead.
+func (*SQSConsumeFailure) Descriptor() ([]byte, []int) {
+	return file_sqs_proto_rawDescGZIP(), []int{13}
 }
 
-func (x *SQSResponseMessage) GetMessageID() string {
+func (x *SQSConsumeFailure) GetMessageID() string {
 	if x != nil {
 		return x.MessageID
 	}
 	return ""
 }
 
-func (x *SQSResponseMessage) GetMessageBody() string {
+func (x *SQSConsumeFailure) GetError() string {
 	if x != nil {
-		return x.MessageBody
+		return x.Error
 	}
 	return ""
 }
 
-type SQSReceiveMessageResponse struct {
+// sent by the server on the Consume stream
+type SQSConsumeResponse struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
 	Messages []*SQSResponseMessage `protobuf:"bytes,1,rep,name=messages,proto3" json:"messages,omitempty"`
+	// acks, nacks or lease extensions that failed
+	Failures []*SQSConsumeFailure `protobuf:"bytes,2,rep,name=failures,proto3" json:"failures,omitempty"`
 }
 
-func (x *SQSReceiveMessageResponse) Reset() {
-	*x = SQSReceiveMessageResponse{}
+func (x *SQSConsumeResponse) Reset() {
+	*x = SQSConsumeResponse{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_sqs_proto_msgTypes[2]
+		mi := &file_sqs_proto_msgTypes[14]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *SQSReceiveMessageResponse) String() string {
+func (x *SQSConsumeResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*SQSReceiveMessageResponse) ProtoMessage() {}
+func (*SQSConsumeResponse) ProtoMessage() {}
 
-func (x *SQSReceiveMessageResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_sqs_proto_msgTypes[2]
+func (x *SQSConsumeResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_sqs_proto_msgTypes[14]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -174,43 +937,51 @@ func (x *SQSReceiveMessageResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use SQSReceiveMessageResponse.ProtoReflect.Descriptor instead.
-func (*SQSReceiveMessageResponse) Descriptor() ([]byte, []int) {
-	return file_sqs_proto_rawDescGZIP(), []int{2}
+// Deprecated: Use SQSConsumeResponse.ProtoReflect.Descriptor instead.
+func (*SQSConsumeResponse) Descriptor() ([]byte, []int) {
+	return file_sqs_proto_rawDescGZIP(), []int{14}
 }
 
-func (x *SQSReceiveMessageResponse) GetMessages() []*SQSResponseMessage {
+func (x *SQSConsumeResponse) GetMessages() []*SQSResponseMessage {
 	if x != nil {
 		return x.Messages
 	}
 	return nil
 }
 
-type SQSDeleteMessageRequest struct {
+func (x *SQSConsumeResponse) GetFailures() []*SQSConsumeFailure {
+	if x != nil {
+		return x.Failures
+	}
+	return nil
+}
+
+type SQSSetLogLevelRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	MessageID string `protobuf:"bytes,1,opt,name=messageID,proto3" json:"messageID,omitempty"`
+	// trace, debug, info, warn, error or disabled; empty keeps the current level
+	Level string `protobuf:"bytes,1,opt,name=level,proto3" json:"level,omitempty"`
 }
 
-func (x *SQSDeleteMessageRequest) Reset() {
-	*x = SQSDeleteMessageRequest{}
+func (x *SQSSetLogLevelRequest) Reset() {
+	*x = SQSSetLogLevelRequest{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_sqs_proto_msgTypes[3]
+		mi := &file_sqs_proto_msgTypes[15]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *SQSDeleteMessageRequest) String() string {
+func (x *SQSSetLogLevelRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*SQSDeleteMessageRequest) ProtoMessage() {}
+func (*SQSSetLogLevelRequest) ProtoMessage() {}
 
-func (x *SQSDeleteMessageRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_sqs_proto_msgTypes[3]
+func (x *SQSSetLogLevelRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_sqs_proto_msgTypes[15]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -221,43 +992,44 @@ func (x *SQSDeleteMessageRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use SQSDeleteMessageRequest.ProtoReflect.Descriptor instead.
-func (*SQSDeleteMessageRequest) Descriptor() ([]byte, []int) {
-	return file_sqs_proto_rawDescGZIP(), []int{3}
+// Deprecated: Use SQSSetLogLevelRequest.ProtoReflect.Descriptor instead.
+func (*SQSSetLogLevelRequest) Descriptor() ([]byte, []int) {
+	return file_sqs_proto_rawDescGZIP(), []int{15}
 }
 
-func (x *SQSDeleteMessageRequest) GetMessageID() string {
+func (x *SQSSetLogLevelRequest) GetLevel() string {
 	if x != nil {
-		return x.MessageID
+		return x.Level
 	}
 	return ""
 }
 
-type SQSDeleteMessageResponse struct {
+type SQSSetLogLevelResponse struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	IsDeleted bool `protobuf:"varint,1,opt,name=isDeleted,proto3" json:"isDeleted,omitempty"`
+	PreviousLevel string `protobuf:"bytes,1,opt,name=previousLevel,proto3" json:"previousLevel,omitempty"`
+	Level         string `protobuf:"bytes,2,opt,name=level,proto3" json:"level,omitempty"`
 }
 
-func (x *SQSDeleteMessageResponse) Reset() {
-	*x = SQSDeleteMessageResponse{}
+func (x *SQSSetLogLevelResponse) Reset() {
+	*x = SQSSetLogLevelResponse{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_sqs_proto_msgTypes[4]
+		mi := &file_sqs_proto_msgTypes[16]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *SQSDeleteMessageResponse) String() string {
+func (x *SQSSetLogLevelResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*SQSDeleteMessageResponse) ProtoMessage() {}
+func (*SQSSetLogLevelResponse) ProtoMessage() {}
 
-func (x *SQSDeleteMessageResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_sqs_proto_msgTypes[4]
+func (x *SQSSetLogLevelResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_sqs_proto_msgTypes[16]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -268,23 +1040,30 @@ func (x *SQSDeleteMessageResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use SQSDeleteMessageResponse.ProtoReflect.Descriptor instead.
-func (*SQSDeleteMessageResponse) Descriptor() ([]byte, []int) {
-	return file_sqs_proto_rawDescGZIP(), []int{4}
+// Deprecated: Use SQSSetLogLevelResponse.ProtoReflect.Descriptor instead.
+func (*SQSSetLogLevelResponse) Descriptor() ([]byte, []int) {
+	return file_sqs_proto_rawDescGZIP(), []int{16}
 }
 
-func (x *SQSDeleteMessageResponse) GetIsDeleted() bool {
+func (x *SQSSetLogLevelResponse) GetPreviousLevel() string {
 	if x != nil {
-		return x.IsDeleted
+		return x.PreviousLevel
 	}
-	return false
+	return ""
+}
+
+func (x *SQSSetLogLevelResponse) GetLevel() string {
+	if x != nil {
+		return x.Level
+	}
+	return ""
 }
 
 var File_sqs_proto protoreflect.FileDescriptor
 
 var file_sqs_proto_rawDesc = []byte{
 	0x0a, 0x09, 0x73, 0x71, 0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x03, 0x73, 0x71, 0x73,
-	0x1a, 0x0b, 0x65, 0x6d, 0x70, 0x74, 0x79, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0xa3, 0x01,
+	0x1a, 0x0b, 0x65, 0x6d, 0x70, 0x74, 0x79, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0xcf, 0x01,
 	0x0a, 0x18, 0x53, 0x51, 0x53, 0x52, 0x65, 0x63, 0x65, 0x69, 0x76, 0x65, 0x4d, 0x65, 0x73, 0x73,
 	0x61, 0x67, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x2d, 0x0a, 0x12, 0x76, 0x69,
 	0x73, 0x69, 0x62, 0x69, 0x6c, 0x69, 0x74, 0x79, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74,
@@ -295,36 +1074,188 @@ var file_sqs_proto_rawDesc = []byte{
 	0x6d, 0x5f, 0x6e, 0x75, 0x6d, 0x62, 0x65, 0x72, 0x5f, 0x6f, 0x66, 0x5f, 0x6d, 0x65, 0x73, 0x73,
 	0x61, 0x67, 0x65, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52, 0x17, 0x6d, 0x61, 0x78, 0x69,
 	0x6d, 0x75, 0x6d, 0x4e, 0x75, 0x6d, 0x62, 0x65, 0x72, 0x4f, 0x66, 0x4d, 0x65, 0x73, 0x73, 0x61,
-	0x67, 0x65, 0x73, 0x22, 0x54, 0x0a, 0x12, 0x53, 0x51, 0x53, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
-	0x73, 0x65, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x12, 0x1c, 0x0a, 0x09, 0x6d, 0x65, 0x73,
-	0x73, 0x61, 0x67, 0x65, 0x49, 0x44, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x6d, 0x65,
-	0x73, 0x73, 0x61, 0x67, 0x65, 0x49, 0x44, 0x12, 0x20, 0x0a, 0x0b, 0x6d, 0x65, 0x73, 0x73, 0x61,
-	0x67, 0x65, 0x42, 0x6f, 0x64, 0x79, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x6d, 0x65,
-	0x73, 0x73, 0x61, 0x67, 0x65, 0x42, 0x6f, 0x64, 0x79, 0x22, 0x50, 0x0a, 0x19, 0x53, 0x51, 0x53,
-	0x52, 0x65, 0x63, 0x65, 0x69, 0x76, 0x65, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x52, 0x65,
-	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x33, 0x0a, 0x08, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67,
-	0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x17, 0x2e, 0x73, 0x71, 0x73, 0x2e, 0x53,
-	0x51, 0x53, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67,
-	0x65, 0x52, 0x08, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x73, 0x22, 0x37, 0x0a, 0x17, 0x53,
-	0x51, 0x53, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x52,
-	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1c, 0x0a, 0x09, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67,
+	0x67, 0x65, 0x73, 0x12, 0x2a, 0x0a, 0x11, 0x6d, 0x61, 0x78, 0x69, 0x6d, 0x75, 0x6d, 0x5f, 0x77,
+	0x61, 0x69, 0x74, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0f,
+	0x6d, 0x61, 0x78, 0x69, 0x6d, 0x75, 0x6d, 0x57, 0x61, 0x69, 0x74, 0x54, 0x69, 0x6d, 0x65, 0x22,
+	0xb4, 0x03, 0x0a, 0x12, 0x53, 0x51, 0x53, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x4d,
+	0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x12, 0x1c, 0x0a, 0x09, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67,
+	0x65, 0x49, 0x44, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x6d, 0x65, 0x73, 0x73, 0x61,
+	0x67, 0x65, 0x49, 0x44, 0x12, 0x20, 0x0a, 0x0b, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x42,
+	0x6f, 0x64, 0x79, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x6d, 0x65, 0x73, 0x73, 0x61,
+	0x67, 0x65, 0x42, 0x6f, 0x64, 0x79, 0x12, 0x22, 0x0a, 0x0c, 0x72, 0x65, 0x63, 0x65, 0x69, 0x76,
+	0x65, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0c, 0x72, 0x65,
+	0x63, 0x65, 0x69, 0x76, 0x65, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x12, 0x4d, 0x0a, 0x0c, 0x74, 0x72,
+	0x61, 0x63, 0x65, 0x43, 0x6f, 0x6e, 0x74, 0x65, 0x78, 0x74, 0x18, 0x04, 0x20, 0x03, 0x28, 0x0b,
+	0x32, 0x29, 0x2e, 0x73, 0x71, 0x73, 0x2e, 0x53, 0x51, 0x53, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
+	0x73, 0x65, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x2e, 0x54, 0x72, 0x61, 0x63, 0x65, 0x43,
+	0x6f, 0x6e, 0x74, 0x65, 0x78, 0x74, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x0c, 0x74, 0x72, 0x61,
+	0x63, 0x65, 0x43, 0x6f, 0x6e, 0x74, 0x65, 0x78, 0x74, 0x12, 0x22, 0x0a, 0x0c, 0x73, 0x71, 0x73,
+	0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x49, 0x44, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x0c, 0x73, 0x71, 0x73, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x49, 0x44, 0x12, 0x47, 0x0a,
+	0x0a, 0x61, 0x74, 0x74, 0x72, 0x69, 0x62, 0x75, 0x74, 0x65, 0x73, 0x18, 0x06, 0x20, 0x03, 0x28,
+	0x0b, 0x32, 0x27, 0x2e, 0x73, 0x71, 0x73, 0x2e, 0x53, 0x51, 0x53, 0x52, 0x65, 0x73, 0x70, 0x6f,
+	0x6e, 0x73, 0x65, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x2e, 0x41, 0x74, 0x74, 0x72, 0x69,
+	0x62, 0x75, 0x74, 0x65, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x0a, 0x61, 0x74, 0x74, 0x72,
+	0x69, 0x62, 0x75, 0x74, 0x65, 0x73, 0x1a, 0x3f, 0x0a, 0x11, 0x54, 0x72, 0x61, 0x63, 0x65, 0x43,
+	0x6f, 0x6e, 0x74, 0x65, 0x78, 0x74, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b,
+	0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a,
+	0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x76, 0x61,
+	0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x1a, 0x3d, 0x0a, 0x0f, 0x41, 0x74, 0x74, 0x72, 0x69,
+	0x62, 0x75, 0x74, 0x65, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65,
+	0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05,
+	0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c,
+	0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x22, 0x50, 0x0a, 0x19, 0x53, 0x51, 0x53, 0x52, 0x65, 0x63,
+	0x65, 0x69, 0x76, 0x65, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f,
+	0x6e, 0x73, 0x65, 0x12, 0x33, 0x0a, 0x08, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x73, 0x18,
+	0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x17, 0x2e, 0x73, 0x71, 0x73, 0x2e, 0x53, 0x51, 0x53, 0x52,
+	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x52, 0x08,
+	0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x73, 0x22, 0x37, 0x0a, 0x17, 0x53, 0x51, 0x53, 0x44,
+	0x65, 0x6c, 0x65, 0x74, 0x65, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x12, 0x1c, 0x0a, 0x09, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x49, 0x44,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x49,
+	0x44, 0x22, 0x38, 0x0a, 0x18, 0x53, 0x51, 0x53, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x4d, 0x65,
+	0x73, 0x73, 0x61, 0x67, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x1c, 0x0a,
+	0x09, 0x69, 0x73, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08,
+	0x52, 0x09, 0x69, 0x73, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x64, 0x22, 0xdd, 0x01, 0x0a, 0x18,
+	0x53, 0x51, 0x53, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65,
+	0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x2d, 0x0a, 0x12, 0x76, 0x69, 0x73, 0x69,
+	0x62, 0x69, 0x6c, 0x69, 0x74, 0x79, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x03, 0x52, 0x11, 0x76, 0x69, 0x73, 0x69, 0x62, 0x69, 0x6c, 0x69, 0x74, 0x79,
+	0x54, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x12, 0x1b, 0x0a, 0x09, 0x77, 0x61, 0x69, 0x74, 0x5f,
+	0x74, 0x69, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x08, 0x77, 0x61, 0x69, 0x74,
+	0x54, 0x69, 0x6d, 0x65, 0x12, 0x3b, 0x0a, 0x1a, 0x6d, 0x61, 0x78, 0x69, 0x6d, 0x75, 0x6d, 0x5f,
+	0x6e, 0x75, 0x6d, 0x62, 0x65, 0x72, 0x5f, 0x6f, 0x66, 0x5f, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67,
+	0x65, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52, 0x17, 0x6d, 0x61, 0x78, 0x69, 0x6d, 0x75,
+	0x6d, 0x4e, 0x75, 0x6d, 0x62, 0x65, 0x72, 0x4f, 0x66, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65,
+	0x73, 0x12, 0x38, 0x0a, 0x18, 0x6d, 0x61, 0x78, 0x69, 0x6d, 0x75, 0x6d, 0x5f, 0x75, 0x6e, 0x61,
+	0x63, 0x6b, 0x65, 0x64, 0x5f, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x73, 0x18, 0x04, 0x20,
+	0x01, 0x28, 0x03, 0x52, 0x16, 0x6d, 0x61, 0x78, 0x69, 0x6d, 0x75, 0x6d, 0x55, 0x6e, 0x61, 0x63,
+	0x6b, 0x65, 0x64, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x73, 0x22, 0x93, 0x01, 0x0a, 0x15,
+	0x53, 0x51, 0x53, 0x4e, 0x61, 0x63, 0x6b, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1c, 0x0a, 0x09, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65,
+	0x49, 0x44, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67,
+	0x65, 0x49, 0x44, 0x12, 0x27, 0x0a, 0x0c, 0x64, 0x65, 0x6c, 0x61, 0x79, 0x53, 0x65, 0x63, 0x6f,
+	0x6e, 0x64, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x48, 0x00, 0x52, 0x0c, 0x64, 0x65, 0x6c,
+	0x61, 0x79, 0x53, 0x65, 0x63, 0x6f, 0x6e, 0x64, 0x73, 0x88, 0x01, 0x01, 0x12, 0x22, 0x0a, 0x0c,
+	0x72, 0x65, 0x63, 0x65, 0x69, 0x76, 0x65, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x03, 0x52, 0x0c, 0x72, 0x65, 0x63, 0x65, 0x69, 0x76, 0x65, 0x43, 0x6f, 0x75, 0x6e, 0x74,
+	0x42, 0x0f, 0x0a, 0x0d, 0x5f, 0x64, 0x65, 0x6c, 0x61, 0x79, 0x53, 0x65, 0x63, 0x6f, 0x6e, 0x64,
+	0x73, 0x22, 0x75, 0x0a, 0x1b, 0x53, 0x51, 0x53, 0x44, 0x65, 0x61, 0x64, 0x4c, 0x65, 0x74, 0x74,
+	0x65, 0x72, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x12, 0x1c, 0x0a, 0x09, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x49, 0x44, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x09, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x49, 0x44, 0x12, 0x20,
+	0x0a, 0x0b, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x42, 0x6f, 0x64, 0x79, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x0b, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x42, 0x6f, 0x64, 0x79,
+	0x12, 0x16, 0x0a, 0x06, 0x72, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x06, 0x72, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x22, 0x63, 0x0a, 0x15, 0x53, 0x51, 0x53, 0x45,
+	0x78, 0x74, 0x65, 0x6e, 0x64, 0x4c, 0x65, 0x61, 0x73, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x12, 0x1c, 0x0a, 0x09, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x49, 0x44, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x49, 0x44, 0x12,
+	0x2c, 0x0a, 0x11, 0x76, 0x69, 0x73, 0x69, 0x62, 0x69, 0x6c, 0x69, 0x74, 0x79, 0x54, 0x69, 0x6d,
+	0x65, 0x6f, 0x75, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x11, 0x76, 0x69, 0x73, 0x69,
+	0x62, 0x69, 0x6c, 0x69, 0x74, 0x79, 0x54, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x22, 0x5d, 0x0a,
+	0x15, 0x53, 0x51, 0x53, 0x53, 0x65, 0x6e, 0x64, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x20, 0x0a, 0x0b, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67,
+	0x65, 0x42, 0x6f, 0x64, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x6d, 0x65, 0x73,
+	0x73, 0x61, 0x67, 0x65, 0x42, 0x6f, 0x64, 0x79, 0x12, 0x22, 0x0a, 0x0c, 0x64, 0x65, 0x6c, 0x61,
+	0x79, 0x53, 0x65, 0x63, 0x6f, 0x6e, 0x64, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0c,
+	0x64, 0x65, 0x6c, 0x61, 0x79, 0x53, 0x65, 0x63, 0x6f, 0x6e, 0x64, 0x73, 0x22, 0x36, 0x0a, 0x16,
+	0x53, 0x51, 0x53, 0x53, 0x65, 0x6e, 0x64, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x52, 0x65,
+	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x1c, 0x0a, 0x09, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67,
 	0x65, 0x49, 0x44, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x6d, 0x65, 0x73, 0x73, 0x61,
-	0x67, 0x65, 0x49, 0x44, 0x22, 0x38, 0x0a, 0x18, 0x53, 0x51, 0x53, 0x44, 0x65, 0x6c, 0x65, 0x74,
-	0x65, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
-	0x12, 0x1c, 0x0a, 0x09, 0x69, 0x73, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x64, 0x18, 0x01, 0x20,
-	0x01, 0x28, 0x08, 0x52, 0x09, 0x69, 0x73, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x64, 0x32, 0xa4,
-	0x01, 0x0a, 0x0a, 0x53, 0x51, 0x53, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x4f, 0x0a,
-	0x0e, 0x52, 0x65, 0x63, 0x65, 0x69, 0x76, 0x65, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x12,
-	0x1d, 0x2e, 0x73, 0x71, 0x73, 0x2e, 0x53, 0x51, 0x53, 0x52, 0x65, 0x63, 0x65, 0x69, 0x76, 0x65,
-	0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1e,
-	0x2e, 0x73, 0x71, 0x73, 0x2e, 0x53, 0x51, 0x53, 0x52, 0x65, 0x63, 0x65, 0x69, 0x76, 0x65, 0x4d,
-	0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x45,
-	0x0a, 0x0d, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x12,
-	0x1c, 0x2e, 0x73, 0x71, 0x73, 0x2e, 0x53, 0x51, 0x53, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x4d,
-	0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x16, 0x2e,
-	0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e,
-	0x45, 0x6d, 0x70, 0x74, 0x79, 0x42, 0x07, 0x5a, 0x05, 0x2e, 0x2f, 0x73, 0x71, 0x73, 0x62, 0x06,
-	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x67, 0x65, 0x49, 0x44, 0x22, 0x6f, 0x0a, 0x21, 0x53, 0x51, 0x53, 0x43, 0x68, 0x61, 0x6e, 0x67,
+	0x65, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x56, 0x69, 0x73, 0x69, 0x62, 0x69, 0x6c, 0x69,
+	0x74, 0x79, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1c, 0x0a, 0x09, 0x6d, 0x65, 0x73,
+	0x73, 0x61, 0x67, 0x65, 0x49, 0x44, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x6d, 0x65,
+	0x73, 0x73, 0x61, 0x67, 0x65, 0x49, 0x44, 0x12, 0x2c, 0x0a, 0x11, 0x76, 0x69, 0x73, 0x69, 0x62,
+	0x69, 0x6c, 0x69, 0x74, 0x79, 0x54, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x03, 0x52, 0x11, 0x76, 0x69, 0x73, 0x69, 0x62, 0x69, 0x6c, 0x69, 0x74, 0x79, 0x54, 0x69,
+	0x6d, 0x65, 0x6f, 0x75, 0x74, 0x22, 0xbc, 0x02, 0x0a, 0x11, 0x53, 0x51, 0x53, 0x43, 0x6f, 0x6e,
+	0x73, 0x75, 0x6d, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x2d, 0x0a, 0x12, 0x76,
+	0x69, 0x73, 0x69, 0x62, 0x69, 0x6c, 0x69, 0x74, 0x79, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x6f, 0x75,
+	0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x11, 0x76, 0x69, 0x73, 0x69, 0x62, 0x69, 0x6c,
+	0x69, 0x74, 0x79, 0x54, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x12, 0x1b, 0x0a, 0x09, 0x77, 0x61,
+	0x69, 0x74, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x08, 0x77,
+	0x61, 0x69, 0x74, 0x54, 0x69, 0x6d, 0x65, 0x12, 0x3b, 0x0a, 0x1a, 0x6d, 0x61, 0x78, 0x69, 0x6d,
+	0x75, 0x6d, 0x5f, 0x6e, 0x75, 0x6d, 0x62, 0x65, 0x72, 0x5f, 0x6f, 0x66, 0x5f, 0x6d, 0x65, 0x73,
+	0x73, 0x61, 0x67, 0x65, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52, 0x17, 0x6d, 0x61, 0x78,
+	0x69, 0x6d, 0x75, 0x6d, 0x4e, 0x75, 0x6d, 0x62, 0x65, 0x72, 0x4f, 0x66, 0x4d, 0x65, 0x73, 0x73,
+	0x61, 0x67, 0x65, 0x73, 0x12, 0x18, 0x0a, 0x07, 0x63, 0x72, 0x65, 0x64, 0x69, 0x74, 0x73, 0x18,
+	0x04, 0x20, 0x01, 0x28, 0x03, 0x52, 0x07, 0x63, 0x72, 0x65, 0x64, 0x69, 0x74, 0x73, 0x12, 0x16,
+	0x0a, 0x06, 0x61, 0x63, 0x6b, 0x49, 0x44, 0x73, 0x18, 0x05, 0x20, 0x03, 0x28, 0x09, 0x52, 0x06,
+	0x61, 0x63, 0x6b, 0x49, 0x44, 0x73, 0x12, 0x30, 0x0a, 0x05, 0x6e, 0x61, 0x63, 0x6b, 0x73, 0x18,
+	0x06, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x73, 0x71, 0x73, 0x2e, 0x53, 0x51, 0x53, 0x4e,
+	0x61, 0x63, 0x6b, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x52, 0x05, 0x6e, 0x61, 0x63, 0x6b, 0x73, 0x12, 0x3a, 0x0a, 0x0a, 0x65, 0x78, 0x74, 0x65,
+	0x6e, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x07, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x73,
+	0x71, 0x73, 0x2e, 0x53, 0x51, 0x53, 0x45, 0x78, 0x74, 0x65, 0x6e, 0x64, 0x4c, 0x65, 0x61, 0x73,
+	0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x52, 0x0a, 0x65, 0x78, 0x74, 0x65, 0x6e, 0x73,
+	0x69, 0x6f, 0x6e, 0x73, 0x22, 0x47, 0x0a, 0x11, 0x53, 0x51, 0x53, 0x43, 0x6f, 0x6e, 0x73, 0x75,
+	0x6d, 0x65, 0x46, 0x61, 0x69, 0x6c, 0x75, 0x72, 0x65, 0x12, 0x1c, 0x0a, 0x09, 0x6d, 0x65, 0x73,
+	0x73, 0x61, 0x67, 0x65, 0x49, 0x44, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x6d, 0x65,
+	0x73, 0x73, 0x61, 0x67, 0x65, 0x49, 0x44, 0x12, 0x14, 0x0a, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x22, 0x7d, 0x0a,
+	0x12, 0x53, 0x51, 0x53, 0x43, 0x6f, 0x6e, 0x73, 0x75, 0x6d, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f,
+	0x6e, 0x73, 0x65, 0x12, 0x33, 0x0a, 0x08, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x73, 0x18,
+	0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x17, 0x2e, 0x73, 0x71, 0x73, 0x2e, 0x53, 0x51, 0x53, 0x52,
+	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x52, 0x08,
+	0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x73, 0x12, 0x32, 0x0a, 0x08, 0x66, 0x61, 0x69, 0x6c,
+	0x75, 0x72, 0x65, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x16, 0x2e, 0x73, 0x71, 0x73,
+	0x2e, 0x53, 0x51, 0x53, 0x43, 0x6f, 0x6e, 0x73, 0x75, 0x6d, 0x65, 0x46, 0x61, 0x69, 0x6c, 0x75,
+	0x72, 0x65, 0x52, 0x08, 0x66, 0x61, 0x69, 0x6c, 0x75, 0x72, 0x65, 0x73, 0x22, 0x2d, 0x0a, 0x15,
+	0x53, 0x51, 0x53, 0x53, 0x65, 0x74, 0x4c, 0x6f, 0x67, 0x4c, 0x65, 0x76, 0x65, 0x6c, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x6c, 0x65, 0x76, 0x65, 0x6c, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x6c, 0x65, 0x76, 0x65, 0x6c, 0x22, 0x54, 0x0a, 0x16, 0x53,
+	0x51, 0x53, 0x53, 0x65, 0x74, 0x4c, 0x6f, 0x67, 0x4c, 0x65, 0x76, 0x65, 0x6c, 0x52, 0x65, 0x73,
+	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x24, 0x0a, 0x0d, 0x70, 0x72, 0x65, 0x76, 0x69, 0x6f, 0x75,
+	0x73, 0x4c, 0x65, 0x76, 0x65, 0x6c, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x70, 0x72,
+	0x65, 0x76, 0x69, 0x6f, 0x75, 0x73, 0x4c, 0x65, 0x76, 0x65, 0x6c, 0x12, 0x14, 0x0a, 0x05, 0x6c,
+	0x65, 0x76, 0x65, 0x6c, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x6c, 0x65, 0x76, 0x65,
+	0x6c, 0x32, 0xad, 0x05, 0x0a, 0x0a, 0x53, 0x51, 0x53, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65,
+	0x12, 0x4f, 0x0a, 0x0e, 0x52, 0x65, 0x63, 0x65, 0x69, 0x76, 0x65, 0x4d, 0x65, 0x73, 0x73, 0x61,
+	0x67, 0x65, 0x12, 0x1d, 0x2e, 0x73, 0x71, 0x73, 0x2e, 0x53, 0x51, 0x53, 0x52, 0x65, 0x63, 0x65,
+	0x69, 0x76, 0x65, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x1a, 0x1e, 0x2e, 0x73, 0x71, 0x73, 0x2e, 0x53, 0x51, 0x53, 0x52, 0x65, 0x63, 0x65, 0x69,
+	0x76, 0x65, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
+	0x65, 0x12, 0x45, 0x0a, 0x0d, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x4d, 0x65, 0x73, 0x73, 0x61,
+	0x67, 0x65, 0x12, 0x1c, 0x2e, 0x73, 0x71, 0x73, 0x2e, 0x53, 0x51, 0x53, 0x44, 0x65, 0x6c, 0x65,
+	0x74, 0x65, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x1a, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62,
+	0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x12, 0x41, 0x0a, 0x0b, 0x4e, 0x61, 0x63, 0x6b,
+	0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x12, 0x1a, 0x2e, 0x73, 0x71, 0x73, 0x2e, 0x53, 0x51,
+	0x53, 0x4e, 0x61, 0x63, 0x6b, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x1a, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x12, 0x4d, 0x0a, 0x11, 0x44,
+	0x65, 0x61, 0x64, 0x4c, 0x65, 0x74, 0x74, 0x65, 0x72, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65,
+	0x12, 0x20, 0x2e, 0x73, 0x71, 0x73, 0x2e, 0x53, 0x51, 0x53, 0x44, 0x65, 0x61, 0x64, 0x4c, 0x65,
+	0x74, 0x74, 0x65, 0x72, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x1a, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x12, 0x46, 0x0a, 0x0b, 0x53, 0x65,
+	0x6e, 0x64, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x12, 0x1a, 0x2e, 0x73, 0x71, 0x73, 0x2e,
+	0x53, 0x51, 0x53, 0x53, 0x65, 0x6e, 0x64, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1b, 0x2e, 0x73, 0x71, 0x73, 0x2e, 0x53, 0x51, 0x53, 0x53,
+	0x65, 0x6e, 0x64, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
+	0x73, 0x65, 0x12, 0x59, 0x0a, 0x17, 0x43, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x4d, 0x65, 0x73, 0x73,
+	0x61, 0x67, 0x65, 0x56, 0x69, 0x73, 0x69, 0x62, 0x69, 0x6c, 0x69, 0x74, 0x79, 0x12, 0x26, 0x2e,
+	0x73, 0x71, 0x73, 0x2e, 0x53, 0x51, 0x53, 0x43, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x4d, 0x65, 0x73,
+	0x73, 0x61, 0x67, 0x65, 0x56, 0x69, 0x73, 0x69, 0x62, 0x69, 0x6c, 0x69, 0x74, 0x79, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x12, 0x4a, 0x0a,
+	0x0e, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x73, 0x12,
+	0x1d, 0x2e, 0x73, 0x71, 0x73, 0x2e, 0x53, 0x51, 0x53, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x4d,
+	0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x17,
+	0x2e, 0x73, 0x71, 0x73, 0x2e, 0x53, 0x51, 0x53, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
+	0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x30, 0x01, 0x12, 0x3e, 0x0a, 0x07, 0x43, 0x6f, 0x6e,
+	0x73, 0x75, 0x6d, 0x65, 0x12, 0x16, 0x2e, 0x73, 0x71, 0x73, 0x2e, 0x53, 0x51, 0x53, 0x43, 0x6f,
+	0x6e, 0x73, 0x75, 0x6d, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x17, 0x2e, 0x73,
+	0x71, 0x73, 0x2e, 0x53, 0x51, 0x53, 0x43, 0x6f, 0x6e, 0x73, 0x75, 0x6d, 0x65, 0x52, 0x65, 0x73,
+	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x28, 0x01, 0x30, 0x01, 0x12, 0x46, 0x0a, 0x0b, 0x53, 0x65, 0x74,
+	0x4c, 0x6f, 0x67, 0x4c, 0x65, 0x76, 0x65, 0x6c, 0x12, 0x1a, 0x2e, 0x73, 0x71, 0x73, 0x2e, 0x53,
+	0x51, 0x53, 0x53, 0x65, 0x74, 0x4c, 0x6f, 0x67, 0x4c, 0x65, 0x76, 0x65, 0x6c, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x1a, 0x1b, 0x2e, 0x73, 0x71, 0x73, 0x2e, 0x53, 0x51, 0x53, 0x53, 0x65,
+	0x74, 0x4c, 0x6f, 0x67, 0x4c, 0x65, 0x76, 0x65, 0x6c, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
+	0x65, 0x42, 0x07, 0x5a, 0x05, 0x2e, 0x2f, 0x73, 0x71, 0x73, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x33,
 }
 
 var (
@@ -339,26 +1270,60 @@ func file_sqs_proto_rawDescGZIP() []byte {
 	return file_sqs_proto_rawDescData
 }
 
-var file_sqs_proto_msgTypes = make([]protoimpl.MessageInfo, 5)
+var file_sqs_proto_msgTypes = make([]protoimpl.MessageInfo, 19)
 var file_sqs_proto_goTypes = []interface{}{
-	(*SQSReceiveMessageRequest)(nil),  // 0: sqs.SQSReceiveMessageRequest
-	(*SQSResponseMessage)(nil),        // 1: sqs.SQSResponseMessage
-	(*SQSReceiveMessageResponse)(nil), // 2: sqs.SQSReceiveMessageResponse
-	(*SQSDeleteMessageRequest)(nil),   // 3: sqs.SQSDeleteMessageRequest
-	(*SQSDeleteMessageResponse)(nil),  // 4: sqs.SQSDeleteMessageResponse
-	(*emptypb.Empty)(nil),             // 5: google.protobuf.Empty
+	(*SQSReceiveMessageRequest)(nil),          // 0: sqs.SQSReceiveMessageRequest
+	(*SQSResponseMessage)(nil),                // 1: sqs.SQSResponseMessage
+	(*SQSReceiveMessageResponse)(nil),         // 2: sqs.SQSReceiveMessageResponse
+	(*SQSDeleteMessageRequest)(nil),           // 3: sqs.SQSDeleteMessageRequest
+	(*SQSDeleteMessageResponse)(nil),          // 4: sqs.SQSDeleteMessageResponse
+	(*SQSStreamMessagesRequest)(nil),          // 5: sqs.SQSStreamMessagesRequest
+	(*SQSNackMessageRequest)(nil),             // 6: sqs.SQSNackMessageRequest
+	(*SQSDeadLetterMessageRequest)(nil),       // 7: sqs.SQSDeadLetterMessageRequest
+	(*SQSExtendLeaseRequest)(nil),             // 8: sqs.SQSExtendLeaseRequest
+	(*SQSSendMessageRequest)(nil),             // 9: sqs.SQSSendMessageRequest
+	(*SQSSendMessageResponse)(nil),            // 10: sqs.SQSSendMessageResponse
+	(*SQSChangeMessageVisibilityRequest)(nil), // 11: sqs.SQSChangeMessageVisibilityRequest
+	(*SQSConsumeRequest)(nil),                 // 12: sqs.SQSConsumeRequest
+	(*SQSConsumeFailure)(nil),                 // 13: sqs.SQSConsumeFailure
+	(*SQSConsumeResponse)(nil),                // 14: sqs.SQSConsumeResponse
+	(*SQSSetLogLevelRequest)(nil),             // 15: sqs.SQSSetLogLevelRequest
+	(*SQSSetLogLevelResponse)(nil),            // 16: sqs.SQSSetLogLevelResponse
+	nil,                                       // 17: sqs.SQSResponseMessage.TraceContextEntry
+	nil,                                       // 18: sqs.SQSResponseMessage.AttributesEntry
+	(*emptypb.Empty)(nil),                     // 19: google.protobuf.Empty
 }
 var file_sqs_proto_depIdxs = []int32{
-	1, // 0: sqs.SQSReceiveMessageResponse.messages:type_name -> sqs.SQSResponseMessage
-	0, // 1: sqs.SQSService.ReceiveMessage:input_type -> sqs.SQSReceiveMessageRequest
-	3, // 2: sqs.SQSService.DeleteMessage:input_type -> sqs.SQSDeleteMessageRequest
-	2, // 3: sqs.SQSService.ReceiveMessage:output_type -> sqs.SQSReceiveMessageResponse
-	5, // 4: sqs.SQSService.DeleteMessage:output_type -> google.protobuf.Empty
-	3, // [3:5] is the sub-list for method output_type
-	1, // [1:3] is the sub-list for method input_type
-	1, // [1:1] is the sub-list for extension type_name
-	1, // [1:1] is the sub-list for extension extendee
-	0, // [0:1] is the sub-list for field type_name
+	17, // 0: sqs.SQSResponseMessage.traceContext:type_name -> sqs.SQSResponseMessage.TraceContextEntry
+	18, // 1: sqs.SQSResponseMessage.attributes:type_name -> sqs.SQSResponseMessage.AttributesEntry
+	1,  // 2: sqs.SQSReceiveMessageResponse.messages:type_name -> sqs.SQSResponseMessage
+	6,  // 3: sqs.SQSConsumeRequest.nacks:type_name -> sqs.SQSNackMessageRequest
+	8,  // 4: sqs.SQSConsumeRequest.extensions:type_name -> sqs.SQSExtendLeaseRequest
+	1,  // 5: sqs.SQSConsumeResponse.messages:type_name -> sqs.SQSResponseMessage
+	13, // 6: sqs.SQSConsumeResponse.failures:type_name -> sqs.SQSConsumeFailure
+	0,  // 7: sqs.SQSService.ReceiveMessage:input_type -> sqs.SQSReceiveMessageRequest
+	3,  // 8: sqs.SQSService.DeleteMessage:input_type -> sqs.SQSDeleteMessageRequest
+	6,  // 9: sqs.SQSService.NackMessage:input_type -> sqs.SQSNackMessageRequest
+	7,  // 10: sqs.SQSService.DeadLetterMessage:input_type -> sqs.SQSDeadLetterMessageRequest
+	9,  // 11: sqs.SQSService.SendMessage:input_type -> sqs.SQSSendMessageRequest
+	11, // 12: sqs.SQSService.ChangeMessageVisibility:input_type -> sqs.SQSChangeMessageVisibilityRequest
+	5,  // 13: sqs.SQSService.StreamMessages:input_type -> sqs.SQSStreamMessagesRequest
+	12, // 14: sqs.SQSService.Consume:input_type -> sqs.SQSConsumeRequest
+	15, // 15: sqs.SQSService.SetLogLevel:input_type -> sqs.SQSSetLogLevelRequest
+	2,  // 16: sqs.SQSService.ReceiveMessage:output_type -> sqs.SQSReceiveMessageResponse
+	19, // 17: sqs.SQSService.DeleteMessage:output_type -> google.protobuf.Empty
+	19, // 18: sqs.SQSService.NackMessage:output_type -> google.protobuf.Empty
+	19, // 19: sqs.SQSService.DeadLetterMessage:output_type -> google.protobuf.Empty
+	10, // 20: sqs.SQSService.SendMessage:output_type -> sqs.SQSSendMessageResponse
+	19, // 21: sqs.SQSService.ChangeMessageVisibility:output_type -> google.protobuf.Empty
+	1,  // 22: sqs.SQSService.StreamMessages:output_type -> sqs.SQSResponseMessage
+	14, // 23: sqs.SQSService.Consume:output_type -> sqs.SQSConsumeResponse
+	16, // 24: sqs.SQSService.SetLogLevel:output_type -> sqs.SQSSetLogLevelResponse
+	16, // [16:25] is the sub-list for method output_type
+	7,  // [7:16] is the sub-list for method input_type
+	7,  // [7:7] is the sub-list for extension type_name
+	7,  // [7:7] is the sub-list for extension extendee
+	0,  // [0:7] is the sub-list for field type_name
 }
 
 func init() { file_sqs_proto_init() }
@@ -427,14 +1392,159 @@ func file_sqs_proto_init() {
 				return nil
 			}
 		}
+		file_sqs_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SQSStreamMessagesRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_sqs_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SQSNackMessageRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_sqs_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SQSDeadLetterMessageRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_sqs_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SQSExtendLeaseRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_sqs_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SQSSendMessageRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_sqs_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SQSSendMessageResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_sqs_proto_msgTypes[11].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SQSChangeMessageVisibilityRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_sqs_proto_msgTypes[12].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SQSConsumeRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_sqs_proto_msgTypes[13].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SQSConsumeFailure); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_sqs_proto_msgTypes[14].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SQSConsumeResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_sqs_proto_msgTypes[15].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SQSSetLogLevelRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_sqs_proto_msgTypes[16].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SQSSetLogLevelResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
 	}
+	file_sqs_proto_msgTypes[6].OneofWrappers = []interface{}{}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_sqs_proto_rawDesc,
 			NumEnums:      0,
-			NumMessages:   5,
+			NumMessages:   19,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
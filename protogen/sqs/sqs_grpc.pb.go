@@ -20,8 +20,15 @@ import (
 const _ = grpc.SupportPackageIsVersion7
 
 const (
-	SQSService_ReceiveMessage_FullMethodName = "/sqs.SQSService/ReceiveMessage"
-	SQSService_DeleteMessage_FullMethodName  = "/sqs.SQSService/DeleteMessage"
+	SQSService_ReceiveMessage_FullMethodName          = "/sqs.SQSService/ReceiveMessage"
+	SQSService_DeleteMessage_FullMethodName           = "/sqs.SQSService/DeleteMessage"
+	SQSService_NackMessage_FullMethodName             = "/sqs.SQSService/NackMessage"
+	SQSService_DeadLetterMessage_FullMethodName       = "/sqs.SQSService/DeadLetterMessage"
+	SQSService_SendMessage_FullMethodName             = "/sqs.SQSService/SendMessage"
+	SQSService_ChangeMessageVisibility_FullMethodName = "/sqs.SQSService/ChangeMessageVisibility"
+	SQSService_StreamMessages_FullMethodName          = "/sqs.SQSService/StreamMessages"
+	SQSService_Consume_FullMethodName                 = "/sqs.SQSService/Consume"
+	SQSService_SetLogLevel_FullMethodName             = "/sqs.SQSService/SetLogLevel"
 )
 
 // SQSServiceClient is the client API for SQSService service.
@@ -30,6 +37,14 @@ const (
 type SQSServiceClient interface {
 	ReceiveMessage(ctx context.Context, in *SQSReceiveMessageRequest, opts ...grpc.CallOption) (*SQSReceiveMessageResponse, error)
 	DeleteMessage(ctx context.Context, in *SQSDeleteMessageRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	NackMessage(ctx context.Context, in *SQSNackMessageRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	DeadLetterMessage(ctx context.Context, in *SQSDeadLetterMessageRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	SendMessage(ctx context.Context, in *SQSSendMessageRequest, opts ...grpc.CallOption) (*SQSSendMessageResponse, error)
+	ChangeMessageVisibility(ctx context.Context, in *SQSChangeMessageVisibilityRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	StreamMessages(ctx context.Context, in *SQSStreamMessagesRequest, opts ...grpc.CallOption) (SQSService_StreamMessagesClient, error)
+	Consume(ctx context.Context, opts ...grpc.CallOption) (SQSService_ConsumeClient, error)
+	// changes the level of the server's logs without restarting it; only admins can call it by default
+	SetLogLevel(ctx context.Context, in *SQSSetLogLevelRequest, opts ...grpc.CallOption) (*SQSSetLogLevelResponse, error)
 }
 
 type sQSServiceClient struct {
@@ -58,12 +73,128 @@ func (c *sQSServiceClient) DeleteMessage(ctx context.Context, in *SQSDeleteMessa
 	return out, nil
 }
 
+func (c *sQSServiceClient) NackMessage(ctx context.Context, in *SQSNackMessageRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, SQSService_NackMessage_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sQSServiceClient) DeadLetterMessage(ctx context.Context, in *SQSDeadLetterMessageRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, SQSService_DeadLetterMessage_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sQSServiceClient) SendMessage(ctx context.Context, in *SQSSendMessageRequest, opts ...grpc.CallOption) (*SQSSendMessageResponse, error) {
+	out := new(SQSSendMessageResponse)
+	err := c.cc.Invoke(ctx, SQSService_SendMessage_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sQSServiceClient) ChangeMessageVisibility(ctx context.Context, in *SQSChangeMessageVisibilityRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, SQSService_ChangeMessageVisibility_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sQSServiceClient) StreamMessages(ctx context.Context, in *SQSStreamMessagesRequest, opts ...grpc.CallOption) (SQSService_StreamMessagesClient, error) {
+	stream, err := c.cc.NewStream(ctx, &SQSService_ServiceDesc.Streams[0], SQSService_StreamMessages_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &sQSServiceStreamMessagesClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type SQSService_StreamMessagesClient interface {
+	Recv() (*SQSResponseMessage, error)
+	grpc.ClientStream
+}
+
+type sQSServiceStreamMessagesClient struct {
+	grpc.ClientStream
+}
+
+func (x *sQSServiceStreamMessagesClient) Recv() (*SQSResponseMessage, error) {
+	m := new(SQSResponseMessage)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *sQSServiceClient) Consume(ctx context.Context, opts ...grpc.CallOption) (SQSService_ConsumeClient, error) {
+	stream, err := c.cc.NewStream(ctx, &SQSService_ServiceDesc.Streams[1], SQSService_Consume_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &sQSServiceConsumeClient{stream}
+	return x, nil
+}
+
+type SQSService_ConsumeClient interface {
+	Send(*SQSConsumeRequest) error
+	Recv() (*SQSConsumeResponse, error)
+	grpc.ClientStream
+}
+
+type sQSServiceConsumeClient struct {
+	grpc.ClientStream
+}
+
+func (x *sQSServiceConsumeClient) Send(m *SQSConsumeRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *sQSServiceConsumeClient) Recv() (*SQSConsumeResponse, error) {
+	m := new(SQSConsumeResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *sQSServiceClient) SetLogLevel(ctx context.Context, in *SQSSetLogLevelRequest, opts ...grpc.CallOption) (*SQSSetLogLevelResponse, error) {
+	out := new(SQSSetLogLevelResponse)
+	err := c.cc.Invoke(ctx, SQSService_SetLogLevel_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // SQSServiceServer is the server API for SQSService service.
 // All implementations must embed UnimplementedSQSServiceServer
 // for forward compatibility
 type SQSServiceServer interface {
 	ReceiveMessage(context.Context, *SQSReceiveMessageRequest) (*SQSReceiveMessageResponse, error)
 	DeleteMessage(context.Context, *SQSDeleteMessageRequest) (*emptypb.Empty, error)
+	NackMessage(context.Context, *SQSNackMessageRequest) (*emptypb.Empty, error)
+	DeadLetterMessage(context.Context, *SQSDeadLetterMessageRequest) (*emptypb.Empty, error)
+	SendMessage(context.Context, *SQSSendMessageRequest) (*SQSSendMessageResponse, error)
+	ChangeMessageVisibility(context.Context, *SQSChangeMessageVisibilityRequest) (*emptypb.Empty, error)
+	StreamMessages(*SQSStreamMessagesRequest, SQSService_StreamMessagesServer) error
+	Consume(SQSService_ConsumeServer) error
+	// changes the level of the server's logs without restarting it; only admins can call it by default
+	SetLogLevel(context.Context, *SQSSetLogLevelRequest) (*SQSSetLogLevelResponse, error)
 	mustEmbedUnimplementedSQSServiceServer()
 }
 
@@ -77,6 +208,27 @@ func (UnimplementedSQSServiceServer) ReceiveMessage(context.Context, *SQSReceive
 func (UnimplementedSQSServiceServer) DeleteMessage(context.Context, *SQSDeleteMessageRequest) (*emptypb.Empty, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method DeleteMessage not implemented")
 }
+func (UnimplementedSQSServiceServer) NackMessage(context.Context, *SQSNackMessageRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method NackMessage not implemented")
+}
+func (UnimplementedSQSServiceServer) DeadLetterMessage(context.Context, *SQSDeadLetterMessageRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeadLetterMessage not implemented")
+}
+func (UnimplementedSQSServiceServer) SendMessage(context.Context, *SQSSendMessageRequest) (*SQSSendMessageResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SendMessage not implemented")
+}
+func (UnimplementedSQSServiceServer) ChangeMessageVisibility(context.Context, *SQSChangeMessageVisibilityRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ChangeMessageVisibility not implemented")
+}
+func (UnimplementedSQSServiceServer) StreamMessages(*SQSStreamMessagesRequest, SQSService_StreamMessagesServer) error {
+	return status.Errorf(codes.Unimplemented, "method StreamMessages not implemented")
+}
+func (UnimplementedSQSServiceServer) Consume(SQSService_ConsumeServer) error {
+	return status.Errorf(codes.Unimplemented, "method Consume not implemented")
+}
+func (UnimplementedSQSServiceServer) SetLogLevel(context.Context, *SQSSetLogLevelRequest) (*SQSSetLogLevelResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetLogLevel not implemented")
+}
 func (UnimplementedSQSServiceServer) mustEmbedUnimplementedSQSServiceServer() {}
 
 // UnsafeSQSServiceServer may be embedded to opt out of forward compatibility for this service.
@@ -126,6 +278,143 @@ func _SQSService_DeleteMessage_Handler(srv interface{}, ctx context.Context, dec
 	return interceptor(ctx, in, info, handler)
 }
 
+func _SQSService_NackMessage_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SQSNackMessageRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SQSServiceServer).NackMessage(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SQSService_NackMessage_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SQSServiceServer).NackMessage(ctx, req.(*SQSNackMessageRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SQSService_DeadLetterMessage_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SQSDeadLetterMessageRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SQSServiceServer).DeadLetterMessage(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SQSService_DeadLetterMessage_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SQSServiceServer).DeadLetterMessage(ctx, req.(*SQSDeadLetterMessageRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SQSService_SendMessage_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SQSSendMessageRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SQSServiceServer).SendMessage(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SQSService_SendMessage_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SQSServiceServer).SendMessage(ctx, req.(*SQSSendMessageRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SQSService_ChangeMessageVisibility_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SQSChangeMessageVisibilityRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SQSServiceServer).ChangeMessageVisibility(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SQSService_ChangeMessageVisibility_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SQSServiceServer).ChangeMessageVisibility(ctx, req.(*SQSChangeMessageVisibilityRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SQSService_StreamMessages_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SQSStreamMessagesRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(SQSServiceServer).StreamMessages(m, &sQSServiceStreamMessagesServer{stream})
+}
+
+type SQSService_StreamMessagesServer interface {
+	Send(*SQSResponseMessage) error
+	grpc.ServerStream
+}
+
+type sQSServiceStreamMessagesServer struct {
+	grpc.ServerStream
+}
+
+func (x *sQSServiceStreamMessagesServer) Send(m *SQSResponseMessage) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _SQSService_Consume_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(SQSServiceServer).Consume(&sQSServiceConsumeServer{stream})
+}
+
+type SQSService_ConsumeServer interface {
+	Send(*SQSConsumeResponse) error
+	Recv() (*SQSConsumeRequest, error)
+	grpc.ServerStream
+}
+
+type sQSServiceConsumeServer struct {
+	grpc.ServerStream
+}
+
+func (x *sQSServiceConsumeServer) Send(m *SQSConsumeResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *sQSServiceConsumeServer) Recv() (*SQSConsumeRequest, error) {
+	m := new(SQSConsumeRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _SQSService_SetLogLevel_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SQSSetLogLevelRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SQSServiceServer).SetLogLevel(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SQSService_SetLogLevel_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SQSServiceServer).SetLogLevel(ctx, req.(*SQSSetLogLevelRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // SQSService_ServiceDesc is the grpc.ServiceDesc for SQSService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -141,7 +430,39 @@ var SQSService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "DeleteMessage",
 			Handler:    _SQSService_DeleteMessage_Handler,
 		},
+		{
+			MethodName: "NackMessage",
+			Handler:    _SQSService_NackMessage_Handler,
+		},
+		{
+			MethodName: "DeadLetterMessage",
+			Handler:    _SQSService_DeadLetterMessage_Handler,
+		},
+		{
+			MethodName: "SendMessage",
+			Handler:    _SQSService_SendMessage_Handler,
+		},
+		{
+			MethodName: "ChangeMessageVisibility",
+			Handler:    _SQSService_ChangeMessageVisibility_Handler,
+		},
+		{
+			MethodName: "SetLogLevel",
+			Handler:    _SQSService_SetLogLevel_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamMessages",
+			Handler:       _SQSService_StreamMessages_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "Consume",
+			Handler:       _SQSService_Consume_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "sqs.proto",
 }